@@ -1718,7 +1718,8 @@ func findModifiedParagraphs(paras1, paras2 []string, map1, map2 map[string]bool)
 	return modified
 }
 
-// similarity calculates a simple similarity score between two strings
+// similarity calculates a simple similarity score between two strings: the
+// word overlap of texts, or the edit distance of single words
 func similarity(s1, s2 string) float64 {
 	if s1 == s2 {
 		return 1.0
@@ -1734,6 +1735,9 @@ func similarity(s1, s2 string) float64 {
 	if len(words1) == 0 || len(words2) == 0 {
 		return 0.0
 	}
+	if len(words1) == 1 && len(words2) == 1 {
+		return editSimilarity(words1[0], words2[0])
+	}
 
 	// Count common words
 	wordSet := make(map[string]bool)
@@ -1757,6 +1761,34 @@ func similarity(s1, s2 string) float64 {
 	return float64(common) / float64(total)
 }
 
+// editSimilarity scores two words by their Levenshtein distance relative to the longer word
+func editSimilarity(s1, s2 string) float64 {
+	r1, r2 := []rune(s1), []rune(s2)
+	longest := max(len(r1), len(r2))
+	if longest == 0 {
+		return 1.0
+	}
+
+	previous := make([]int, len(r2)+1)
+	current := make([]int, len(r2)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		current[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1.0 - float64(previous[len(r2)])/float64(longest)
+}
+
 func extractLinkText(content string) string {
 	var text strings.Builder
 	textPattern := regexp.MustCompile(`<w:t[^>]*>([^<]*)</w:t>`)
@@ -60,8 +60,7 @@ func NewWithOptions(pageSize ...PageSize) *DocxTmpl {
 		hyperlinkReg:     hyperlinkReg,
 	}
 
-	// Override the link function to use our hyperlink registry
-	docTmpl.funcMap["link"] = docTmpl.createLink
+	docTmpl.registerBuiltins()
 
 	return docTmpl
 }
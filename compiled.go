@@ -0,0 +1,230 @@
+package docxtpl
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/abdokhaire/go-docxgen/internal/docx"
+	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
+	"github.com/abdokhaire/go-docxgen/internal/hyperlinks"
+	"github.com/abdokhaire/go-docxgen/internal/tags"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+	"golang.org/x/text/language"
+)
+
+// =============================================================================
+// Compiled Templates
+// =============================================================================
+
+// CompiledTemplate is a document whose templates have been parsed once, to be
+// rendered many times with different data. It doesn't change once compiled
+// and is safe for concurrent use: each render works on its own copy of the
+// document, sharing the parts and media of the template.
+type CompiledTemplate struct {
+	doc   *DocxTmpl
+	parts *compiledParts
+}
+
+// compiledParts are the parsed parts of a document, ready to render
+type compiledParts struct {
+	locale  language.Tag
+	tagOpts tags.Options
+	body    *compiledPart
+	files   []*compiledPart // in the order of the processable files
+}
+
+// compiledPart is a part of the document with its tags ready to replace
+type compiledPart struct {
+	name    string
+	content string         // with the standard delimiters
+	tmpl    *tags.Template // nil when the part is parsed on each render
+}
+
+// Compile parses the templates of the document once, for rendering the
+// document many times, possibly concurrently. The compiled template is a
+// snapshot: changes made to the document afterwards, including functions
+// registered later, don't affect it.
+//
+//	compiled, err := doc.Compile()
+//	if err != nil {
+//		panic(err)
+//	}
+//	for _, customer := range customers {
+//		var buf bytes.Buffer
+//		err := compiled.Execute(&buf, customer)
+//		// ...
+//	}
+func (d *DocxTmpl) Compile() (*CompiledTemplate, error) {
+	return d.CompileWithOptions(RenderOptions{})
+}
+
+// CompileWithOptions compiles the document like Compile, with the options
+// applied to every render.
+//
+//	compiled, err := doc.CompileWithOptions(docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+func (d *DocxTmpl) CompileWithOptions(opts RenderOptions) (*CompiledTemplate, error) {
+	snapshot, err := d.Clone()
+	if err != nil {
+		return nil, err
+	}
+	snapshot.useJSONTags = d.useJSONTags
+	snapshot.imageResolver = d.imageResolver
+	snapshot.delims = d.delims
+	snapshot.partials = maps.Clone(d.partials)
+	snapshot.locale = d.locale
+	snapshot.limits = d.limits
+	snapshot.funcMap = d.funcsFor(snapshot)
+
+	parts, err := snapshot.compileParts(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledTemplate{doc: snapshot, parts: parts}, nil
+}
+
+// Execute renders the template with the data and writes the document to w.
+// Errors are reported like RenderWithOptions reports them, and nothing is
+// written when rendering fails.
+//
+//	f, _ := os.Create("letter.docx")
+//	defer f.Close()
+//	err := compiled.Execute(f, data)
+func (c *CompiledTemplate) Execute(w io.Writer, data any) error {
+	doc, err := c.Render(data)
+	if err != nil {
+		return err
+	}
+	return doc.Save(w)
+}
+
+// Render renders the template with the data into a new document, which can be
+// changed further before saving.
+//
+//	doc, err := compiled.Render(data)
+func (c *CompiledTemplate) Render(data any) (*DocxTmpl, error) {
+	return c.render(context.Background(), data)
+}
+
+// render renders the template with the data into a new document, stopping
+// once the context is done
+func (c *CompiledTemplate) render(ctx context.Context, data any) (*DocxTmpl, error) {
+	doc := c.doc.fork()
+	if err := doc.renderParts(ctx, c.parts, data); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fork returns a copy of the document to render, sharing what rendering
+// doesn't change
+func (d *DocxTmpl) fork() *DocxTmpl {
+	fork := &DocxTmpl{
+		Docx:             d.Docx.Fork(),
+		contentTypes:     d.contentTypes.Clone(),
+		processableFiles: slices.Clone(d.processableFiles),
+		hyperlinkReg:     hyperlinks.NewHyperlinkRegistry(),
+		useJSONTags:      d.useJSONTags,
+		partOverrides:    maps.Clone(d.partOverrides),
+		imageResolver:    d.imageResolver,
+		delims:           d.delims,
+		partials:         maps.Clone(d.partials),
+		locale:           d.locale,
+		limits:           d.limits,
+	}
+	fork.funcMap = d.funcsFor(fork)
+
+	// Images and tables are sized to the page before the body is rendered
+	for _, item := range slices.Backward(d.Document.Body.Items) {
+		if sect, ok := item.(*docx.SectPr); ok {
+			fork.Document.Body.Items = []any{sect}
+			break
+		}
+	}
+	return fork
+}
+
+// compileParts parses the parts of the document for rendering with the options
+func (d *DocxTmpl) compileParts(opts RenderOptions) (*compiledParts, error) {
+	locale := d.currentLocale()
+	if opts.Locale != "" {
+		tag, err := parseLocale(opts.Locale)
+		if err != nil {
+			return nil, err
+		}
+		locale = tag
+	}
+
+	partials, err := d.partialTemplates()
+	if err != nil {
+		return nil, err
+	}
+	parts := &compiledParts{
+		locale:  locale,
+		tagOpts: tags.Options{MissingKey: opts.MissingKey, Delimiters: d.delims, Partials: partials, Limits: d.limits},
+	}
+
+	// Get the document XML, with no 'part tags' left
+	documentXmlString, err := d.templateDocumentXml()
+	if err != nil {
+		return nil, err
+	}
+	if parts.body, err = d.compilePart(documentPath, documentXmlString, parts.tagOpts); err != nil {
+		return nil, err
+	}
+
+	parts.files = make([]*compiledPart, len(d.processableFiles))
+	for i, file := range d.processableFiles {
+		content := d.templateContent(file.Name, file.Content)
+		if parts.files[i], err = d.compilePart(file.Name, content, parts.tagOpts); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+// compilePart parses a part. Parts with placeholder pictures are parsed on
+// each render, once the pictures have been replaced.
+func (d *DocxTmpl) compilePart(name, content string, opts tags.Options) (*compiledPart, error) {
+	part := &compiledPart{name: name, content: content}
+	if !headerfooter.IsDocProps(name) && hasPlaceholderPictures(content) {
+		return part, nil
+	}
+
+	tmpl, err := d.parsePart(name, content, opts)
+	if err != nil {
+		return nil, d.restoreDelimiters(newRenderError(err, partName(name), nil))
+	}
+	part.tmpl = tmpl
+	return part, nil
+}
+
+// parsePart parses the content of a part for tag replacement
+func (d *DocxTmpl) parsePart(name, content string, opts tags.Options) (*tags.Template, error) {
+	if headerfooter.IsDocProps(name) {
+		// Document properties don't have <w:t> elements, process directly with templates
+		return tags.ParseText(content, d.funcMap, opts)
+	}
+	if name != documentPath {
+		// Merge fragmented tags in the XML (handles tags split across multiple <w:t> elements)
+		content = xmlutils.MergeFragmentedTagsInXml(content)
+	}
+	return tags.ParseXml(content, d.funcMap, opts)
+}
+
+// executePart replaces the tags of a part with the data, first replacing the
+// images of pictures whose alt text or name is a placeholder
+func (d *DocxTmpl) executePart(ctx context.Context, part *compiledPart, data map[string]any, imageFor func(picture *xmlutils.Picture) (*InlineImage, error), opts tags.Options) (string, error) {
+	tmpl := part.tmpl
+	if tmpl == nil {
+		content, _, err := d.replacePictures(part.content, part.name, imageFor)
+		if err != nil {
+			return "", err
+		}
+		if tmpl, err = d.parsePart(part.name, content, opts); err != nil {
+			return "", err
+		}
+	}
+	return tmpl.ExecuteContext(ctx, data, d.funcMap)
+}
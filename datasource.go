@@ -0,0 +1,430 @@
+package docxtpl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen/internal/xlsx"
+)
+
+// =============================================================================
+// Mail Merge Data Sources
+// =============================================================================
+
+// FieldType is the type a field of a data source is converted to.
+type FieldType int
+
+const (
+	// FieldText keeps the value as read: text for CSV, the JSON value for
+	// JSON Lines and the cell value for Excel.
+	FieldText FieldType = iota
+	// FieldNumber converts text to an int64, or a float64 when it has decimals.
+	FieldNumber
+	// FieldDate converts text written in one of the date layouts, and Excel
+	// serial dates, to a time.Time.
+	FieldDate
+	// FieldBool converts true/false, yes/no, y/n and 1/0 to a bool.
+	FieldBool
+)
+
+// defaultDateLayouts are the layouts of dates written as text when the source
+// options have none
+var defaultDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// SourceOptions configures how the rows of a data source become records.
+type SourceOptions struct {
+	// Columns renames columns, or JSON keys, to fields. Fields with dots, such
+	// as "Customer.Name", are fields of nested records. Other columns keep
+	// their name.
+	Columns map[string]string
+	// Types converts fields, by field name, from text. Empty values of typed
+	// fields are nil.
+	Types map[string]FieldType
+	// DateLayouts are the Go layouts of dates written as text, tried in
+	// order. RFC 3339 and 2006-01-02 with an optional time when empty.
+	DateLayouts []string
+	// GroupBy lists the fields identifying a record. Consecutive rows with the
+	// same values make one record, whose fields are those of the first row
+	// except for Lists.
+	GroupBy []string
+	// Lists names the nested fields, such as "Items", gathering one entry per
+	// row of a GroupBy record in a list: columns "Items.Name" and
+	// "Items.Price" make Items a list of records with a Name and a Price.
+	Lists []string
+	// Comma is the field separator of CSV data, ',' when zero.
+	Comma rune
+}
+
+// RecordSource reads mail merge records from CSV, JSON Lines or Excel data.
+// Records are read once, as they are iterated; the error that stopped the
+// iteration is returned by Err.
+//
+//	source := docxtpl.CSVSource(file, docxtpl.SourceOptions{
+//		Columns: map[string]string{"Customer name": "Name"},
+//		Types:   map[string]docxtpl.FieldType{"Total": docxtpl.FieldNumber},
+//	})
+//	err := doc.MailMergeStream(ctx, source.Records(), sink, docxtpl.MailMergeOptions{})
+//	if err == nil {
+//		err = source.Err()
+//	}
+type RecordSource struct {
+	rows     iter.Seq2[map[string]any, error] // the values of each row by column
+	opts     SourceOptions
+	date1904 bool // whether serial dates count from 1904, as in some Excel workbooks
+	err      error
+}
+
+// CSVSource returns the records of CSV data whose first row holds the column
+// names.
+func CSVSource(r io.Reader, opts SourceOptions) *RecordSource {
+	return &RecordSource{opts: opts, rows: func(yield func(map[string]any, error) bool) {
+		reader := csv.NewReader(r)
+		if opts.Comma != 0 {
+			reader.Comma = opts.Comma
+		}
+		reader.FieldsPerRecord = -1
+
+		header, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff") // byte order mark written by Excel
+		}
+
+		for {
+			values, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			row := make(map[string]any, len(header))
+			for i, name := range header {
+				if name != "" && i < len(values) {
+					row[name] = values[i]
+				}
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}}
+}
+
+// JSONLSource returns the records of JSON Lines data, one JSON object per
+// line. Numbers are int64, or float64 when they have decimals.
+func JSONLSource(r io.Reader, opts SourceOptions) *RecordSource {
+	return &RecordSource{opts: opts, rows: func(yield func(map[string]any, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(text))
+			decoder.UseNumber()
+			var row map[string]any
+			if err := decoder.Decode(&row); err != nil {
+				yield(nil, fmt.Errorf("line %d: %w", line, err))
+				return
+			}
+			if !yield(jsonNumbers(row).(map[string]any), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, err)
+		}
+	}}
+}
+
+// XLSXSource returns the records of a sheet of an Excel workbook, or of its
+// first sheet when sheet is empty, whose first row holds the column names.
+// Cells keep their type: numbers are int64 or float64, dates time.Time and
+// booleans bool. Empty rows are skipped.
+//
+//	file, _ := os.Open("orders.xlsx")
+//	info, _ := file.Stat()
+//	source, err := docxtpl.XLSXSource(file, info.Size(), "Orders", docxtpl.SourceOptions{})
+func XLSXSource(r io.ReaderAt, size int64, sheet string, opts SourceOptions) (*RecordSource, error) {
+	workbook, err := xlsx.Open(r, size)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := workbook.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordSource{opts: opts, date1904: workbook.Date1904(), rows: func(yield func(map[string]any, error) bool) {
+		if len(rows) == 0 {
+			return
+		}
+		header := make([]string, len(rows[0]))
+		for i, name := range rows[0] {
+			if name != nil {
+				header[i] = fmt.Sprint(name)
+			}
+		}
+
+		for _, values := range rows[1:] {
+			if !slices.ContainsFunc(values, func(value any) bool { return value != nil }) {
+				continue
+			}
+			row := make(map[string]any, len(header))
+			for i, name := range header {
+				if name == "" {
+					continue
+				}
+				var value any
+				if i < len(values) {
+					value = values[i]
+				}
+				row[name] = value
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}}, nil
+}
+
+// Records returns the records of the source as map[string]any values, for
+// MailMergeStream.
+func (s *RecordSource) Records() iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for record := range s.records() {
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+// ReadAll reads every record of the source, for MailMerge and
+// MailMergeToFiles.
+//
+//	records, err := source.ReadAll()
+//	err = doc.MailMergeToFiles(records, "letters/{{.CustomerID}}.docx")
+func (s *RecordSource) ReadAll() ([]map[string]any, error) {
+	records := slices.Collect(s.records())
+	return records, s.err
+}
+
+// Err returns the error that stopped reading the records, if any.
+func (s *RecordSource) Err() error {
+	return s.err
+}
+
+// records reads the rows as records, grouping consecutive rows
+func (s *RecordSource) records() iter.Seq[map[string]any] {
+	return func(yield func(map[string]any) bool) {
+		var group map[string]any
+		var groupKey string
+		row := 0
+		for values, err := range s.rows {
+			if err != nil {
+				s.err = err
+				return
+			}
+			row++
+			record, err := s.record(values)
+			if err != nil {
+				s.err = fmt.Errorf("row %d: %w", row, err)
+				return
+			}
+
+			if len(s.opts.GroupBy) == 0 {
+				if !yield(record) {
+					return
+				}
+				continue
+			}
+
+			key := s.groupKey(record)
+			if group != nil && key == groupKey {
+				appendEntries(group, record, s.opts.Lists)
+				continue
+			}
+			if group != nil && !yield(group) {
+				return
+			}
+			group, groupKey = maps.Clone(record), key
+			for _, list := range s.opts.Lists {
+				group[list] = []map[string]any{}
+			}
+			appendEntries(group, record, s.opts.Lists)
+		}
+		if group != nil {
+			yield(group)
+		}
+	}
+}
+
+// record converts the values of a row to a record
+func (s *RecordSource) record(values map[string]any) (map[string]any, error) {
+	record := make(map[string]any, len(values))
+	for column, value := range values {
+		field := column
+		if name, ok := s.opts.Columns[column]; ok {
+			field = name
+		}
+		value, err := s.convert(field, value)
+		if err != nil {
+			return nil, err
+		}
+
+		path := strings.Split(field, ".")
+		fields := record
+		for _, name := range path[:len(path)-1] {
+			child, ok := fields[name].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				fields[name] = child
+			}
+			fields = child
+		}
+		fields[path[len(path)-1]] = value
+	}
+	return record, nil
+}
+
+// convert converts the value of a field to the field's type
+func (s *RecordSource) convert(field string, value any) (any, error) {
+	fieldType := s.opts.Types[field]
+	if fieldType == FieldText {
+		return value, nil
+	}
+
+	text, isText := value.(string)
+	text = strings.TrimSpace(text)
+	if value == nil || (isText && text == "") {
+		return nil, nil
+	}
+
+	switch fieldType {
+	case FieldNumber:
+		if !isText {
+			return value, nil
+		}
+		if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return integer, nil
+		}
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %q is not a number", field, text)
+		}
+		return number, nil
+	case FieldDate:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case int64:
+			return xlsx.SerialDate(float64(v), s.date1904), nil
+		case float64:
+			return xlsx.SerialDate(v, s.date1904), nil
+		}
+		layouts := s.opts.DateLayouts
+		if len(layouts) == 0 {
+			layouts = defaultDateLayouts
+		}
+		for _, layout := range layouts {
+			if date, err := time.Parse(layout, text); err == nil {
+				return date, nil
+			}
+		}
+		return nil, fmt.Errorf("field %s: %q is not a date", field, text)
+	case FieldBool:
+		if truth, ok := value.(bool); ok {
+			return truth, nil
+		}
+		switch strings.ToLower(fmt.Sprint(value)) {
+		case "true", "t", "yes", "y", "1":
+			return true, nil
+		case "false", "f", "no", "n", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("field %s: %q is not a boolean", field, fmt.Sprint(value))
+	}
+	return value, nil
+}
+
+// groupKey returns the values of the GroupBy fields of a record
+func (s *RecordSource) groupKey(record map[string]any) string {
+	key := make([]string, len(s.opts.GroupBy))
+	for i, field := range s.opts.GroupBy {
+		var value any = record
+		for _, name := range strings.Split(field, ".") {
+			fields, _ := value.(map[string]any)
+			value = fields[name]
+		}
+		key[i] = fmt.Sprintf("%#v", value)
+	}
+	return strings.Join(key, "\x00")
+}
+
+// appendEntries appends the list fields of a row to the lists of its group,
+// skipping entries whose fields are all empty
+func appendEntries(group, record map[string]any, lists []string) {
+	for _, list := range lists {
+		entry, _ := record[list].(map[string]any)
+		if !hasValues(entry) {
+			continue
+		}
+		entries, _ := group[list].([]map[string]any)
+		group[list] = append(entries, entry)
+	}
+}
+
+// hasValues reports whether a nested record has values that aren't empty
+func hasValues(fields map[string]any) bool {
+	for _, value := range fields {
+		if value != nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonNumbers converts the json.Number values of decoded JSON to int64 and
+// float64 values
+func jsonNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if integer, err := v.Int64(); err == nil {
+			return integer
+		}
+		number, _ := v.Float64()
+		return number
+	case map[string]any:
+		for key, item := range v {
+			v[key] = jsonNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = jsonNumbers(item)
+		}
+	}
+	return value
+}
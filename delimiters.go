@@ -0,0 +1,79 @@
+package docxtpl
+
+import (
+	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
+	"github.com/abdokhaire/go-docxgen/internal/tags"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// Template Delimiters
+// =============================================================================
+
+// SetDelimiters sets the markers around template tags in the document, for
+// documents whose text contains literal double braces. Text outside the
+// delimiters, including {{ and }}, is left as written. Empty delimiters
+// restore the default {{ and }}.
+//
+//	doc.SetDelimiters("[[", "]]")
+//	err := doc.Render(data) // [[.Name]], [[range .Items]]...[[end]]
+func (d *DocxTmpl) SetDelimiters(left, right string) {
+	d.delims = xmlutils.Delimiters{Left: left, Right: right}
+}
+
+// templateDocumentXml returns the XML of the document body with its tags
+// merged and translated to the standard delimiters
+func (d *DocxTmpl) templateDocumentXml() (string, error) {
+	if d.delims.IsDefault() {
+		tags.MergeTags(d.Document.Body.Items)
+		return d.getDocumentXml()
+	}
+
+	documentXmlString, err := d.getDocumentXml()
+	if err != nil {
+		return "", err
+	}
+	return xmlutils.MergeFragmentedTagsInXml(xmlutils.TranslateDelimiters(documentXmlString, d.delims)), nil
+}
+
+// templateContent returns the content of a header, footer, footnotes,
+// endnotes or document properties part with its tags translated to the
+// standard delimiters
+func (d *DocxTmpl) templateContent(name, content string) string {
+	if headerfooter.IsDocProps(name) {
+		return xmlutils.TranslatePropertiesDelimiters(content, d.delims)
+	}
+	return xmlutils.TranslateDelimiters(content, d.delims)
+}
+
+// restoreDelimiters writes the tags reported by a TemplateError with the
+// document's delimiters
+func (d *DocxTmpl) restoreDelimiters(err error) error {
+	te, ok := err.(*TemplateError)
+	if !ok || d.delims.IsDefault() {
+		return err
+	}
+
+	te.Message = d.delims.Restore(te.Message)
+	te.Placeholder = d.delims.Restore(te.Placeholder)
+	te.Snippet = d.delims.Restore(te.Snippet)
+	for i := range te.Suggestions {
+		te.Suggestions[i] = d.delims.Restore(te.Suggestions[i])
+	}
+	for i := range te.Unresolved {
+		te.Unresolved[i].Placeholder = d.delims.Restore(te.Unresolved[i].Placeholder)
+		te.Unresolved[i].Snippet = d.delims.Restore(te.Unresolved[i].Snippet)
+	}
+	return te
+}
+
+// restoreValidationDelimiters writes the tags reported by validation errors
+// with the document's delimiters
+func (d *DocxTmpl) restoreValidationDelimiters(errs []ValidationError) []ValidationError {
+	for i := range errs {
+		errs[i].Field = d.delims.Restore(errs[i].Field)
+		errs[i].Message = d.delims.Restore(errs[i].Message)
+		errs[i].Placeholder = d.delims.Restore(errs[i].Placeholder)
+	}
+	return errs
+}
@@ -58,11 +58,13 @@
 //
 // # Inline Images
 //
-// Images can be inserted using file paths (auto-detected) or InlineImage:
+// Images can be inserted using InlineImage values, or strings naming image
+// files once an ImageResolver is set:
 //
-//	// Auto-detect from file path
+//	// Resolve file names in an assets directory
+//	doc.SetImageResolver(docxtpl.FSImageResolver(os.DirFS("/path/to/assets")))
 //	data := map[string]any{
-//	    "Logo": "/path/to/logo.png",
+//	    "Logo": "logo.png",
 //	}
 //
 //	// Or use InlineImage for size control
@@ -3,18 +3,24 @@ package docxtpl
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"io"
 	"os"
+	"path"
+	"strings"
 	"text/template"
 
 	"github.com/abdokhaire/go-docxgen/internal/docx"
 	"github.com/abdokhaire/go-docxgen/internal/contenttypes"
+	"github.com/abdokhaire/go-docxgen/internal/functions"
 	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
 	"github.com/abdokhaire/go-docxgen/internal/hyperlinks"
 	"github.com/abdokhaire/go-docxgen/internal/tags"
 	"github.com/abdokhaire/go-docxgen/internal/templatedata"
 	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+	"golang.org/x/text/language"
 )
 
 type DocxTmpl struct {
@@ -23,9 +29,29 @@ type DocxTmpl struct {
 	contentTypes     *contenttypes.ContentTypes
 	processableFiles []headerfooter.DocxFile // headers, footers, footnotes, endnotes
 	hyperlinkReg     *hyperlinks.HyperlinkRegistry
-	properties       *DocumentProperties // document metadata (stored in memory, serialized on save)
+	properties       *DocumentProperties        // document metadata (stored in memory, serialized on save)
+	useJSONTags      bool                       // fall back to json struct tags when converting data
+	partOverrides    map[string]string          // unparsed parts replaced on save, such as merged styles
+	imageResolver    ImageResolver              // turns string values into images, strings are text when nil
+	delims           xmlutils.Delimiters        // markers around template tags, {{ and }} when empty
+	partials         map[string]partial         // snippets every part can use with {{template}}
+	locale           language.Tag               // locale of the local* functions, the document's language when undetermined
+	formatter        *functions.LocaleFormatter // formatter of the render in progress
+	limits           Limits                     // resources templates may use
+	boundFuncs       map[string]string          // names of the functions bound to the document, to their name in builtinFuncs or localeFuncs
 }
 
+// TemplateValuer can be implemented by data types to control how they are
+// presented to the template. The value returned by TemplateValue is used in
+// place of the original value.
+//
+//	type Money struct{ cents int64 }
+//
+//	func (m Money) TemplateValue() any {
+//		return fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100)
+//	}
+type TemplateValuer = templatedata.TemplateValuer
+
 // Parse the document from a reader and store it in memory.
 // You can it invoke from a file.
 //
@@ -59,10 +85,15 @@ func Parse(reader io.ReaderAt, size int64) (*DocxTmpl, error) {
 
 	hyperlinkReg := hyperlinks.NewHyperlinkRegistry()
 
-	docTmpl := &DocxTmpl{doc, funcMap, contentTypes, processableFiles, hyperlinkReg, nil}
+	docTmpl := &DocxTmpl{
+		Docx:             doc,
+		funcMap:          funcMap,
+		contentTypes:     contentTypes,
+		processableFiles: processableFiles,
+		hyperlinkReg:     hyperlinkReg,
+	}
 
-	// Override the link function to use our hyperlink registry
-	docTmpl.funcMap["link"] = docTmpl.createLink
+	docTmpl.registerBuiltins()
 
 	return docTmpl, nil
 }
@@ -109,8 +140,24 @@ func ParseFromBytes(data []byte) (*DocxTmpl, error) {
 	return Parse(reader, int64(len(data)))
 }
 
+// UseJSONTags makes struct fields without a `docx` tag fall back to their
+// `json` tag name when data is converted for rendering. This allows API
+// structs to be reused as template data without duplicating them.
+//
+//	type Customer struct {
+//		Name string `json:"customer_name"`
+//	}
+//
+//	doc.UseJSONTags(true)
+//	err = doc.Render(customer) // {{.customer_name}}
+func (d *DocxTmpl) UseJSONTags(enabled bool) {
+	d.useJSONTags = enabled
+}
+
 // Replace the placeholders in the document with passed in data.
-// Data can be a struct or map
+// Data can be a struct or map.
+// Struct fields can be renamed with a `docx:"name"` tag, skipped with
+// `docx:"-"` or left out when empty with `docx:"name,omitempty"`.
 //
 //	data := struct {
 //		FirstName     string
@@ -134,82 +181,143 @@ func ParseFromBytes(data []byte) (*DocxTmpl, error) {
 //
 // err = doc.Render(data)
 func (d *DocxTmpl) Render(data any) error {
-	// Ensure that there are no 'part tags' in the XML document
-	tags.MergeTags(d.Document.Body.Items)
+	return d.RenderWithOptions(data, RenderOptions{})
+}
 
-	// Process the template data
-	processedData, err := d.processTemplateData(data)
+// MissingKeyMode controls how placeholders that resolve to missing or nil values are rendered.
+type MissingKeyMode = tags.MissingKeyMode
+
+const (
+	// MissingKeyZero renders unresolved placeholders as empty text (the default).
+	MissingKeyZero = tags.MissingKeyZero
+	// MissingKeyError fails rendering with a *TemplateError listing every unresolved placeholder.
+	MissingKeyError = tags.MissingKeyError
+	// MissingKeyKeep leaves unresolved placeholders in the document as written, for human review.
+	MissingKeyKeep = tags.MissingKeyKeep
+)
+
+// RenderOptions configures how a document is rendered.
+type RenderOptions struct {
+	// MissingKey controls how placeholders without a value are handled.
+	MissingKey MissingKeyMode
+	// Locale is a BCP 47 language tag that replaces the document's locale for
+	// this render, such as "fr-FR".
+	Locale string
+}
+
+// RenderWithOptions replaces the placeholders in the document like Render,
+// using the given options.
+//
+//	err := doc.RenderWithOptions(data, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+//	if te, ok := docxtpl.IsTemplateError(err); ok {
+//		for _, p := range te.Unresolved {
+//			fmt.Printf("%s in %s, paragraph %d\n", p.Placeholder, p.Part, p.Paragraph)
+//		}
+//	}
+//
+// In MissingKeyError mode the document is left unchanged when rendering fails.
+func (d *DocxTmpl) RenderWithOptions(data any, opts RenderOptions) error {
+	parts, err := d.compileParts(opts)
 	if err != nil {
 		return err
 	}
+	return d.renderParts(context.Background(), parts, data)
+}
 
-	// Get the document XML
-	documentXmlString, err := d.getDocumentXml()
-	if err != nil {
-		return err
+// renderParts replaces the placeholders in the document with the data, using
+// the parsed parts of the document or of the template it was forked from. It
+// stops once the context is done or the time limit has passed.
+func (d *DocxTmpl) renderParts(ctx context.Context, parts *compiledParts, data any) error {
+	ctx, cancel := parts.tagOpts.Limits.Context(ctx)
+	defer cancel()
+	d.formatter = functions.NewLocaleFormatter(parts.locale)
+	defer func() { d.formatter = nil }()
+	var unresolved []PlaceholderLocation
+
+	// Load the fields the document uses from a resolver
+	if resolver, ok := data.(Resolver); ok {
+		resolved, err := d.resolveFields(ctx, parts, resolver)
+		if err != nil {
+			return err
+		}
+		data = resolved
 	}
 
-	// Replace the tags in XML
-	documentXmlString, err = tags.ReplaceTagsInXml(documentXmlString, processedData, d.funcMap)
+	// Process the template data
+	processedData, err := d.processTemplateData(data)
 	if err != nil {
 		return err
 	}
 
-	// Unmarshal the modified XML and replace the document body with it
-	decoder := xml.NewDecoder(bytes.NewBufferString(documentXmlString))
-	for {
-		t, err := decoder.Token()
-		if err == io.EOF {
-			break
+	// collectUnresolved records unresolved placeholders so every part is checked before failing.
+	// Other errors are mapped to a TemplateError located in the part.
+	collectUnresolved := func(err error, part string) error {
+		var unresolvedErr *tags.UnresolvedTagsError
+		if !errors.As(err, &unresolvedErr) {
+			return d.restoreDelimiters(newRenderError(err, part, processedData))
 		}
-		if err != nil {
-			return err
+		for _, tag := range unresolvedErr.Tags {
+			unresolved = append(unresolved, PlaceholderLocation{
+				Placeholder: tag.Tag,
+				Part:        part,
+				Paragraph:   tag.Location.Paragraph,
+				Snippet:     tag.Location.Snippet,
+			})
 		}
-		if start, ok := t.(xml.StartElement); ok {
-			if start.Name.Local == "Body" {
-				clear(d.Document.Body.Items)
-				err = d.Document.Body.UnmarshalXML(decoder, start)
-				if err != nil {
-					return err
-				}
-				break
-			}
+		return nil
+	}
+
+	// Replace the tags in the body, and the images of pictures whose alt text or name is a placeholder
+	placeholderImages := d.placeholderImages(data)
+	documentXmlString, err := d.executePart(ctx, parts.body, processedData, placeholderImages, parts.tagOpts)
+	if err != nil {
+		if err := collectUnresolved(err, partName(documentPath)); err != nil {
+			return err
 		}
 	}
 
 	// Process headers, footers, footnotes, endnotes, and document properties
-	for i := range d.processableFiles {
-		var processedContent string
-		var err error
-
-		if headerfooter.IsDocProps(d.processableFiles[i].Name) {
-			// Document properties don't have <w:t> elements, process directly with templates
-			processedContent, err = tags.ReplaceTagsInText(d.processableFiles[i].Content, processedData, d.funcMap)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Merge fragmented tags in the XML (handles tags split across multiple <w:t> elements)
-			mergedContent := xmlutils.MergeFragmentedTagsInXml(d.processableFiles[i].Content)
-
-			// Process regular text placeholders
-			processedContent, err = tags.ReplaceTagsInXml(mergedContent, processedData, d.funcMap)
-			if err != nil {
+	processedContents := make([]string, len(d.processableFiles))
+	for i, part := range parts.files {
+		processedContent, err := d.executePart(ctx, part, processedData, placeholderImages, parts.tagOpts)
+		if err != nil {
+			if err := collectUnresolved(err, partName(part.name)); err != nil {
 				return err
 			}
+			continue
+		}
 
-			// Process watermark templates in headers (watermarks are VML shapes with textpath)
-			if headerfooter.IsHeaderOrFooter(d.processableFiles[i].Name) {
-				processedContent, err = headerfooter.ProcessWatermarkTemplates(processedContent, func(watermarkText string) (string, error) {
-					return tags.ReplaceTagsInText(watermarkText, processedData, d.funcMap)
-				})
+		// Process watermark templates in headers (watermarks are VML shapes with textpath)
+		if headerfooter.IsHeaderOrFooter(part.name) {
+			processedContent, err = headerfooter.ProcessWatermarkTemplates(processedContent, func(watermarkText string) (string, error) {
+				tmpl, err := tags.ParseText(watermarkText, d.funcMap, parts.tagOpts)
 				if err != nil {
+					return "", err
+				}
+				return tmpl.ExecuteContext(ctx, processedData, d.funcMap)
+			})
+			if err != nil {
+				if err := collectUnresolved(err, partName(part.name)); err != nil {
 					return err
 				}
+				continue
 			}
 		}
 
-		d.processableFiles[i].Content = processedContent
+		processedContents[i] = xmlutils.RestoreBraces(processedContent)
+	}
+
+	if len(unresolved) > 0 {
+		return d.restoreDelimiters(ErrUnresolvedPlaceholders(unresolved).WithSuggestions(fieldSuggestions(unresolved, processedData)...))
+	}
+
+	// Unmarshal the modified XML and replace the document body with it
+	if err := d.setDocumentXml(xmlutils.RestoreBraces(documentXmlString)); err != nil {
+		return err
+	}
+
+	for i := range d.processableFiles {
+		d.processableFiles[i].Content = processedContents[i]
 	}
 
 	return nil
@@ -253,7 +361,9 @@ func (d *DocxTmpl) Save(writer io.Writer) error {
 	hyperlinkLinks := d.hyperlinkReg.GetLinks()
 	hasHyperlinks := len(hyperlinkLinks) > 0
 
+	written := make(map[string]bool)
 	for _, f := range zipReader.File {
+		written[f.Name] = true
 		newFile, err := generatedZip.Create(f.Name)
 		if err != nil {
 			return err
@@ -295,6 +405,12 @@ func (d *DocxTmpl) Save(writer io.Writer) error {
 			if err != nil {
 				return err
 			}
+		} else if content, ok := d.partOverrides[f.Name]; ok {
+			// Write parts updated when embedding sub-documents (styles, numbering)
+			_, err = newFile.Write([]byte(content))
+			if err != nil {
+				return err
+			}
 		} else if content := d.getProcessableFileContent(f.Name); content != "" {
 			// Write our processed content (headers, footers, footnotes, endnotes)
 			_, err = newFile.Write([]byte(content))
@@ -332,6 +448,20 @@ func (d *DocxTmpl) Save(writer io.Writer) error {
 		}
 	}
 
+	// Add parts created when embedding sub-documents
+	for name, content := range d.partOverrides {
+		if written[name] {
+			continue
+		}
+		newFile, err := generatedZip.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := newFile.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+
 	if err := generatedZip.Close(); err != nil {
 		return err
 	}
@@ -363,11 +493,21 @@ func (d *DocxTmpl) SaveToFile(filename string) error {
 //	placeholders, err := doc.GetPlaceholders()
 //	// Returns: []string{"{{.FirstName}}", "{{.LastName}}", "{{range .Items}}", "{{end}}"}
 func (d *DocxTmpl) GetPlaceholders() ([]string, error) {
-	// Merge tags first to handle fragmented placeholders
-	tags.MergeTags(d.Document.Body.Items)
+	placeholders, err := d.placeholders()
+	if err != nil {
+		return nil, err
+	}
+	for i := range placeholders {
+		placeholders[i] = d.delims.Restore(placeholders[i])
+	}
+	return placeholders, nil
+}
 
-	// Get the document XML
-	documentXmlString, err := d.getDocumentXml()
+// placeholders returns the placeholders of the document body with the
+// standard delimiters
+func (d *DocxTmpl) placeholders() ([]string, error) {
+	// Merge tags first to handle fragmented placeholders
+	documentXmlString, err := d.templateDocumentXml()
 	if err != nil {
 		return nil, err
 	}
@@ -397,8 +537,34 @@ func (d *DocxTmpl) getDocumentXml() (string, error) {
 	return string(out), err
 }
 
+// setDocumentXml replaces the document body with the body in the XML
+func (d *DocxTmpl) setDocumentXml(documentXmlString string) error {
+	decoder := xml.NewDecoder(bytes.NewBufferString(documentXmlString))
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := t.(xml.StartElement); ok {
+			if start.Name.Local == "Body" {
+				clear(d.Document.Body.Items)
+				err = d.Document.Body.UnmarshalXML(decoder, start)
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
 func (d *DocxTmpl) processTemplateData(data any) (map[string]any, error) {
-	convertedData, err := templatedata.DataToMap(data)
+	convertedData, err := templatedata.DataToMapWithOptions(data, templatedata.Options{UseJSONTags: d.useJSONTags})
 	if err != nil {
 		return nil, err
 	}
@@ -421,14 +587,10 @@ func (d *DocxTmpl) processTemplateData(data any) (map[string]any, error) {
 	processValue = func(value any) (any, error) {
 		switch v := value.(type) {
 		case string:
-			// Check for image files
-			if isImage, err := templatedata.IsImageFilePath(v); err != nil {
+			// Check for images when a resolver is set
+			if image, err := d.resolveImage(v); err != nil {
 				return nil, err
-			} else if isImage {
-				image, err := CreateInlineImage(v)
-				if err != nil {
-					return nil, err
-				}
+			} else if image != nil {
 				return d.addInlineImage(image)
 			}
 			// XML escape regular strings
@@ -462,6 +624,18 @@ func (d *DocxTmpl) processTemplateData(data any) (map[string]any, error) {
 		case *InlineImage:
 			return d.addInlineImage(v)
 
+		case *RichText:
+			return d.richTextXml(v)
+
+		case *DocxTmpl:
+			return d.subDocumentXml(v)
+
+		case *TableSpec:
+			return d.tableSpecXml(v)
+
+		case TableSpec:
+			return d.tableSpecXml(&v)
+
 		default:
 			// Return other types as-is (int, float, bool, etc.)
 			return value, nil
@@ -476,6 +650,19 @@ func (d *DocxTmpl) processTemplateData(data any) (map[string]any, error) {
 	return convertedData, nil
 }
 
+// documentPath is the location of the main document part within the archive
+const documentPath = "word/document.xml"
+
+// partName converts an archive path to the short part name used in error
+// locations, e.g. "word/header2.xml" -> "header2" and the main document -> "body".
+func partName(filePath string) string {
+	if filePath == documentPath {
+		return "body"
+	}
+	name := strings.TrimPrefix(filePath, "word/")
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
 // getProcessableFileContent returns the processed content for a processable file,
 // or an empty string if the file was not processed.
 func (d *DocxTmpl) getProcessableFileContent(name string) string {
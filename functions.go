@@ -8,6 +8,78 @@ import (
 	"github.com/abdokhaire/go-docxgen/internal/functions"
 )
 
+// builtinFuncs returns the functions every document has, bound to the document
+func (d *DocxTmpl) builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"link":          d.createLink,
+		"markdown":      d.markdownContent,
+		"htmlContent":   d.htmlContent,
+		"paragraphs":    paragraphsContent,
+		"localNumber":   d.localNumber,
+		"localPercent":  d.localPercent,
+		"localCurrency": d.localCurrency,
+		"localDate":     d.localDate,
+	}
+}
+
+// localeFuncs returns the functions of the Word function library that format
+// for the locale of the render, bound to the document
+func (d *DocxTmpl) localeFuncs() template.FuncMap {
+	return template.FuncMap{
+		"formatNumber":   d.formatNumber,
+		"formatCurrency": d.formatCurrency,
+	}
+}
+
+// registerBuiltins registers the built-in functions, such as link which uses
+// the document's hyperlink registry
+func (d *DocxTmpl) registerBuiltins() {
+	for name, fn := range d.builtinFuncs() {
+		d.registerFunc(name, fn, name)
+	}
+}
+
+// registerFunc registers a function under a name. Bound is the name of the
+// function of builtinFuncs or localeFuncs it is, empty for other functions.
+func (d *DocxTmpl) registerFunc(name string, fn any, bound string) {
+	d.funcMap[name] = fn
+	if bound == "" {
+		delete(d.boundFuncs, name)
+		return
+	}
+	if d.boundFuncs == nil {
+		d.boundFuncs = make(map[string]string)
+	}
+	d.boundFuncs[name] = bound
+}
+
+// register registers a function under a name, binding the functions of the
+// Word function library that format for the locale to the document
+func (d *DocxTmpl) register(name string, fn any) {
+	switch fn.(type) {
+	case functions.NumberFunc:
+		d.registerFunc(name, d.formatNumber, "formatNumber")
+	case functions.CurrencyFunc:
+		d.registerFunc(name, d.formatCurrency, "formatCurrency")
+	default:
+		d.registerFunc(name, fn, "")
+	}
+}
+
+// funcsFor returns the functions of the document for another document, with
+// the functions bound to the document bound to the other document instead,
+// which records them as its own bound functions
+func (d *DocxTmpl) funcsFor(other *DocxTmpl) template.FuncMap {
+	funcMap := maps.Clone(d.funcMap)
+	bound := other.builtinFuncs()
+	maps.Copy(bound, other.localeFuncs())
+	for name, fn := range d.boundFuncs {
+		funcMap[name] = bound[fn]
+	}
+	other.boundFuncs = maps.Clone(d.boundFuncs)
+	return funcMap
+}
+
 // Register a function which can then be used within your template
 //
 //	d.RegisterFunction("sayHello", func(text string) string {
@@ -18,7 +90,7 @@ func (d *DocxTmpl) RegisterFunction(name string, fn any) error {
 		return fmt.Errorf("function name %q is not a valid identifier", name)
 	}
 	// Go's text/template handles function signature validation at execution time
-	d.funcMap[name] = fn
+	d.register(name, fn)
 	return nil
 }
 
@@ -34,5 +106,28 @@ func (d *DocxTmpl) GetRegisteredFunctions() *template.FuncMap {
 //
 //	doc.RegisterFuncMap(sprig.FuncMap())
 func (d *DocxTmpl) RegisterFuncMap(funcs template.FuncMap) {
-	maps.Copy(d.funcMap, funcs)
+	for name, fn := range funcs {
+		d.register(name, fn)
+	}
+}
+
+// DocxFunctions returns an opt-in library of template functions that write
+// proper WordprocessingML, unlike general purpose libraries such as Sprig:
+//
+//   - breaks renders newlines as line breaks and tabs as tabs
+//   - pageBreak starts a new page and nbsp is a non-breaking space
+//   - checkbox shows a checked or empty box for a condition
+//   - formatDate, formatNumber and formatCurrency format dates, numbers and
+//     amounts, the last two for the document's locale
+//   - default gives a fallback for empty values
+//   - join joins a list, with line breaks for "\n" separators
+//   - upper, lower and title change case following the Unicode case mappings
+//
+// Register the library before rendering. Functions registered later with the
+// same name replace those of the library.
+//
+//	doc.RegisterFuncMap(docxtpl.DocxFunctions())
+//	// {{formatCurrency "$" .Total}}, {{formatDate "2 January 2006" .Date}}
+func DocxFunctions() template.FuncMap {
+	return functions.DocxFuncMap()
 }
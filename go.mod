@@ -7,6 +7,8 @@ require (
 	github.com/dlclark/regexp2 v1.11.4
 	github.com/fumiama/imgsz v0.0.2
 	github.com/go-sprout/sprout v1.0.2
+	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c
+	github.com/srwiley/rasterx v0.0.0-20210519020934-456a8d69b780
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/image v0.21.0
 	golang.org/x/text v0.28.0
@@ -22,5 +24,6 @@ require (
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/cast v1.9.2 // indirect
+	golang.org/x/net v0.43.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,312 @@
+package docxtpl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// HTML Parsing
+// =============================================================================
+
+// htmlWhitespaceRegex matches runs of whitespace, which HTML renders as a single space
+var htmlWhitespaceRegex = regexp.MustCompile(`\s+`)
+
+// htmlList is an open ul or ol element
+type htmlList struct {
+	ordered bool
+	number  int
+}
+
+// htmlParser builds blocks from the tokens of an HTML fragment
+type htmlParser struct {
+	blocks  []markupBlock
+	current *markupBlock // paragraph, heading or list item receiving text
+
+	lists []htmlList
+	links []string
+
+	table      *markupBlock
+	row        []*RichText
+	cell       *RichText
+	headerRow  bool
+	inTableRow bool
+
+	bold, italic, underline, strike, code int
+}
+
+// parseHTML parses paragraphs, headings, line breaks, bold, italic, underline,
+// strikethrough and code, links, nested ul/ol lists and simple tables. Other
+// elements contribute their text.
+func parseHTML(source string) ([]markupBlock, error) {
+	decoder := xml.NewDecoder(strings.NewReader(source))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	p := &htmlParser{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing html: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == "script" || name == "style" {
+				if err := decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("parsing html: %w", err)
+				}
+				continue
+			}
+			p.start(name, t.Attr)
+		case xml.EndElement:
+			p.end(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			p.text(string(t))
+		}
+	}
+	p.endBlock()
+	p.endTable()
+
+	return p.blocks, nil
+}
+
+func (p *htmlParser) start(name string, attrs []xml.Attr) {
+	switch name {
+	case "p", "div", "blockquote", "pre":
+		if !p.inlineContainer() {
+			p.endBlock()
+			p.current = &markupBlock{kind: markupParagraph, text: NewRichText()}
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		if !p.inlineContainer() {
+			p.endBlock()
+			p.current = &markupBlock{kind: markupHeading, level: int(name[1] - '0'), text: NewRichText()}
+		}
+	case "ul", "ol":
+		p.endBlock()
+		p.lists = append(p.lists, htmlList{ordered: name == "ol"})
+	case "li":
+		p.endBlock()
+		level := min(max(len(p.lists)-1, 0), 8)
+		prefix := getBulletPrefix(ListTypeBullet, level)
+		if len(p.lists) > 0 && p.lists[len(p.lists)-1].ordered {
+			p.lists[len(p.lists)-1].number++
+			prefix = fmt.Sprintf("%d.", p.lists[len(p.lists)-1].number)
+		}
+		p.current = &markupBlock{kind: markupListItem, level: level, prefix: prefix, text: NewRichText()}
+	case "table":
+		p.endBlock()
+		p.endTable()
+		p.table = &markupBlock{kind: markupTable}
+	case "tr":
+		if p.table != nil {
+			p.row = nil
+			p.headerRow = true
+			p.inTableRow = true
+		}
+	case "td", "th":
+		if p.inTableRow {
+			p.cell = NewRichText()
+			if name == "td" {
+				p.headerRow = false
+			}
+		}
+	case "br":
+		if target := p.target(); target != nil {
+			target.Break()
+		}
+	case "b", "strong":
+		p.bold++
+	case "i", "em":
+		p.italic++
+	case "u", "ins":
+		p.underline++
+	case "s", "strike", "del":
+		p.strike++
+	case "code", "kbd", "samp", "tt":
+		p.code++
+	case "a":
+		href := ""
+		for _, attr := range attrs {
+			if strings.EqualFold(attr.Name.Local, "href") {
+				href = attr.Value
+			}
+		}
+		p.links = append(p.links, href)
+	}
+}
+
+func (p *htmlParser) end(name string) {
+	switch name {
+	case "p", "div", "blockquote", "pre", "h1", "h2", "h3", "h4", "h5", "h6":
+		if !p.inlineContainer() {
+			p.endBlock()
+		}
+	case "li":
+		p.endBlock()
+	case "ul", "ol":
+		p.endBlock()
+		if len(p.lists) > 0 {
+			p.lists = p.lists[:len(p.lists)-1]
+		}
+	case "table":
+		p.endTable()
+	case "tr":
+		if p.inTableRow {
+			p.endCell()
+			if len(p.row) > 0 {
+				if len(p.table.rows) == 0 && p.headerRow {
+					p.table.header = true
+				}
+				p.table.rows = append(p.table.rows, p.row)
+			}
+			p.row = nil
+			p.inTableRow = false
+		}
+	case "td", "th":
+		p.endCell()
+	case "b", "strong":
+		p.bold = max(p.bold-1, 0)
+	case "i", "em":
+		p.italic = max(p.italic-1, 0)
+	case "u", "ins":
+		p.underline = max(p.underline-1, 0)
+	case "s", "strike", "del":
+		p.strike = max(p.strike-1, 0)
+	case "code", "kbd", "samp", "tt":
+		p.code = max(p.code-1, 0)
+	case "a":
+		if len(p.links) > 0 {
+			p.links = p.links[:len(p.links)-1]
+		}
+	}
+}
+
+// text adds text with the formatting in effect, collapsing whitespace
+func (p *htmlParser) text(text string) {
+	text = htmlWhitespaceRegex.ReplaceAllString(text, " ")
+	if strings.TrimSpace(text) == "" && p.cell == nil && p.current == nil {
+		// Whitespace between block elements
+		return
+	}
+
+	target := p.target()
+	if target == nil {
+		return
+	}
+	if atLineStart(target) {
+		text = strings.TrimLeft(text, " ")
+	}
+	if text == "" {
+		return
+	}
+
+	target.AddText(text)
+	if p.bold > 0 {
+		target.Bold()
+	}
+	if p.italic > 0 {
+		target.Italic()
+	}
+	if p.underline > 0 {
+		target.Underline()
+	}
+	if p.strike > 0 {
+		target.Strike()
+	}
+	if p.code > 0 {
+		target.Font("Courier New")
+	}
+	if len(p.links) > 0 && p.links[len(p.links)-1] != "" {
+		target.Link(p.links[len(p.links)-1])
+	}
+}
+
+// target returns the rich text receiving inline content, starting a paragraph
+// for text outside block elements
+func (p *htmlParser) target() *RichText {
+	if p.table != nil {
+		if p.cell == nil {
+			return nil
+		}
+		return p.cell
+	}
+	if p.current == nil {
+		p.current = &markupBlock{kind: markupParagraph, text: NewRichText()}
+	}
+	return p.current.text
+}
+
+// inlineContainer reports whether block elements are treated as inline, inside list items and tables
+func (p *htmlParser) inlineContainer() bool {
+	return p.table != nil || (p.current != nil && p.current.kind == markupListItem)
+}
+
+// endBlock adds the current block, dropping paragraphs and headings without text
+func (p *htmlParser) endBlock() {
+	if p.current == nil {
+		return
+	}
+	trimTrailingSpace(p.current.text)
+	if len(p.current.text.fragments) > 0 || p.current.kind == markupListItem {
+		p.blocks = append(p.blocks, *p.current)
+	}
+	p.current = nil
+}
+
+// endCell adds the current cell to the current row
+func (p *htmlParser) endCell() {
+	if p.cell == nil {
+		return
+	}
+	trimTrailingSpace(p.cell)
+	p.row = append(p.row, p.cell)
+	p.cell = nil
+}
+
+// endTable adds the current table if it has rows
+func (p *htmlParser) endTable() {
+	if p.table == nil {
+		return
+	}
+	if len(p.table.rows) > 0 {
+		p.blocks = append(p.blocks, *p.table)
+	}
+	p.table = nil
+	p.row = nil
+	p.cell = nil
+	p.inTableRow = false
+}
+
+// atLineStart reports whether text added to the rich text starts a line
+func atLineStart(r *RichText) bool {
+	if len(r.fragments) == 0 {
+		return true
+	}
+	last := r.fragments[len(r.fragments)-1]
+	return last.lineBreak || strings.HasSuffix(last.text, " ")
+}
+
+// trimTrailingSpace removes trailing spaces from the last fragment of the rich text
+func trimTrailingSpace(r *RichText) {
+	for len(r.fragments) > 0 {
+		last := &r.fragments[len(r.fragments)-1]
+		if last.lineBreak {
+			return
+		}
+		last.text = strings.TrimRight(last.text, " ")
+		if last.text != "" {
+			return
+		}
+		r.fragments = r.fragments[:len(r.fragments)-1]
+	}
+}
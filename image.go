@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"path"
@@ -22,17 +24,48 @@ import (
 	"github.com/fumiama/imgsz"
 	"github.com/abdokhaire/go-docxgen/internal/contenttypes"
 	"github.com/abdokhaire/go-docxgen/internal/templatedata"
+	"golang.org/x/image/bmp"
 	"golang.org/x/image/draw"
+	"golang.org/x/image/tiff"
 )
 
 const (
-	EMUS_PER_INCH = 914400
-	DEFAULT_DPI   = 72
+	EMUS_PER_INCH  = 914400
+	EMUS_PER_CM    = 360000
+	EMUS_PER_POINT = 12700
+	EMUS_PER_TWIP  = 635
+	DEFAULT_DPI    = 72
 )
 
+// Length is a distance in EMUs (English Metric Units), the unit of drawing sizes in Word.
+// Use Cm, Inches or Points to convert from other units.
+type Length int64
+
+// Cm converts centimeters to a Length.
+func Cm(cm float64) Length {
+	return Length(math.Round(cm * EMUS_PER_CM))
+}
+
+// Inches converts inches to a Length.
+func Inches(inches float64) Length {
+	return Length(math.Round(inches * EMUS_PER_INCH))
+}
+
+// Points converts points to a Length.
+func Points(points float64) Length {
+	return Length(math.Round(points * EMUS_PER_POINT))
+}
+
 type InlineImage struct {
 	data *[]byte
 	Ext  string
+
+	width, height       Length // displayed size, derived from the image when zero
+	maxWidth, maxHeight Length // bounding box the displayed size is scaled down to, unbounded when zero
+	fitToPage           bool   // scale down to the width between the page margins
+	keepFrameSize       bool   // fill the frame of a replaced picture instead of fitting inside it
+	altText, title      string
+	fallback            *[]byte // PNG shown by readers without SVG support
 }
 
 type InlineImageError struct {
@@ -43,8 +76,14 @@ func (e *InlineImageError) Error() string {
 	return fmt.Sprintf("Image error: %v", e.Message)
 }
 
+// TemplateValue keeps inline images intact when they are nested inside struct data.
+func (i *InlineImage) TemplateValue() any {
+	return i
+}
+
 // Take a filenane for an image and return a pointer to an InlineImage struct.
-// Images can be Jpegs (.jpg or .jpeg) or PNGs
+// Images can be JPEG (.jpg or .jpeg), PNG, GIF, BMP, TIFF (.tif or .tiff),
+// WebP or SVG. WebP images are converted to PNG.
 //
 //	img, err := CreateInlineImage("example_img.png")
 func CreateInlineImage(filepath string) (*InlineImage, error) {
@@ -61,13 +100,11 @@ func CreateInlineImage(filepath string) (*InlineImage, error) {
 		return nil, err
 	}
 
-	ext := path.Ext(filepath)
-
-	return &InlineImage{&file, ext}, nil
+	return newInlineImage(file, path.Ext(filepath))
 }
 
 // CreateInlineImageFromURL downloads an image from a URL and returns an InlineImage.
-// Images can be Jpegs (.jpg or .jpeg) or PNGs.
+// The URL must end in the extension of a format supported by CreateInlineImage.
 // Timeout defaults to 30 seconds.
 //
 //	img, err := CreateInlineImageFromURL("https://example.com/image.png")
@@ -82,7 +119,7 @@ func CreateInlineImageFromURLWithTimeout(url string, timeout time.Duration) (*In
 	// Validate URL has image extension
 	ext := getExtensionFromURL(url)
 	if ext == "" {
-		return nil, &InlineImageError{"URL does not point to a supported image format (jpg, jpeg, png)"}
+		return nil, &InlineImageError{"URL does not point to a supported image format (jpg, jpeg, png, gif, bmp, tif, tiff, webp, svg)"}
 	}
 
 	// Create HTTP client with timeout
@@ -113,24 +150,24 @@ func CreateInlineImageFromURLWithTimeout(url string, timeout time.Duration) (*In
 		return nil, &InlineImageError{"downloaded content is not a valid image"}
 	}
 
-	return &InlineImage{&data, ext}, nil
+	return newInlineImage(data, ext)
 }
 
 // CreateInlineImageFromBytes creates an InlineImage from raw bytes.
-// You must specify the extension (.jpg, .jpeg, or .png).
+// You must specify the extension of a format supported by CreateInlineImage,
+// such as ".png".
 //
 //	img, err := CreateInlineImageFromBytes(imageData, ".png")
 func CreateInlineImageFromBytes(data []byte, ext string) (*InlineImage, error) {
-	ext = strings.ToLower(ext)
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return nil, &InlineImageError{"unsupported image format: must be .jpg, .jpeg, or .png"}
+	if !isImageExtension(ext) {
+		return nil, &InlineImageError{"unsupported image format: must be " + strings.Join(imageExtensions, ", ")}
 	}
 
 	if !isValidImageData(data) {
 		return nil, &InlineImageError{"data is not a valid image"}
 	}
 
-	return &InlineImage{&data, ext}, nil
+	return newInlineImage(data, ext)
 }
 
 // getExtensionFromURL extracts the image extension from a URL.
@@ -140,20 +177,21 @@ func getExtensionFromURL(url string) string {
 	url = strings.Split(url, "#")[0]
 
 	ext := strings.ToLower(path.Ext(url))
-	switch ext {
-	case ".jpg", ".jpeg", ".png":
-		return ext
-	default:
+	if !isImageExtension(ext) {
 		return ""
 	}
+	return ext
 }
 
 // isValidImageContentType checks if the content type is a supported image type.
 func isValidImageContentType(contentType string) bool {
 	contentType = strings.ToLower(contentType)
-	return strings.Contains(contentType, "image/jpeg") ||
-		strings.Contains(contentType, "image/jpg") ||
-		strings.Contains(contentType, "image/png")
+	for _, imageType := range []string{"image/jpeg", "image/jpg", "image/png", "image/gif", "image/bmp", "image/tiff", "image/webp", "image/svg+xml"} {
+		if strings.Contains(contentType, imageType) {
+			return true
+		}
+	}
+	return false
 }
 
 // isValidImageData checks if the data starts with valid image magic bytes.
@@ -172,15 +210,27 @@ func isValidImageData(data []byte) bool {
 		return true
 	}
 
-	return false
+	// Check for GIF, BMP, TIFF (little and big endian) and WebP magic bytes
+	for _, magic := range []string{"GIF8", "BM", "II*\x00", "MM\x00*"} {
+		if bytes.HasPrefix(data, []byte(magic)) {
+			return true
+		}
+	}
+	if len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+		return true
+	}
+
+	return isSVGData(data)
 }
 
 func (i *InlineImage) getImageFormat() (imagemeta.ImageFormat, error) {
-	switch i.Ext {
+	switch strings.ToLower(i.Ext) {
 	case ".jpg", ".jpeg":
 		return imagemeta.JPEG, nil
 	case ".png":
 		return imagemeta.PNG, nil
+	case ".tif", ".tiff":
+		return imagemeta.TIFF, nil
 	default:
 		return 0, errors.New("Unknown image format: " + i.Ext)
 	}
@@ -226,7 +276,12 @@ func (i *InlineImage) GetExifData() (map[string]imagemeta.TagInfo, error) {
 }
 
 // Resize the image. Width and height should be pixel values.
+// SVG images can't be resized; set their displayed size with Width or Height.
 func (i *InlineImage) Resize(width int, height int) error {
+	if i.isSVG() {
+		return &InlineImageError{"SVG images can't be resized, set their displayed size instead"}
+	}
+
 	src, err := i.getImage()
 	if err != nil {
 		return err
@@ -246,36 +301,44 @@ func (i *InlineImage) Resize(width int, height int) error {
 }
 
 func (i *InlineImage) getImage() (*image.Image, error) {
-	format, err := i.getImageFormat()
-	if err != nil {
-		return nil, err
-	}
-
 	var img image.Image
+	var err error
 	imgReader := bytes.NewReader(*i.data)
 
-	switch format {
-	case imagemeta.JPEG:
+	switch strings.ToLower(i.Ext) {
+	case ".jpg", ".jpeg":
 		img, err = jpeg.Decode(imgReader)
-	case imagemeta.PNG:
+	case ".png":
 		img, err = png.Decode(imgReader)
+	case ".gif":
+		img, err = gif.Decode(imgReader)
+	case ".bmp":
+		img, err = bmp.Decode(imgReader)
+	case ".tif", ".tiff":
+		img, err = tiff.Decode(imgReader)
+	default:
+		return nil, errors.New("Unknown image format: " + i.Ext)
 	}
 
 	return &img, err
 }
 
 func (i *InlineImage) replaceImage(rgba *image.Image) error {
-	format, err := i.getImageFormat()
-	if err != nil {
-		return err
-	}
-
 	var buf bytes.Buffer
-	switch format {
-	case imagemeta.JPEG:
+	var err error
+	switch strings.ToLower(i.Ext) {
+	case ".jpg", ".jpeg":
 		err = jpeg.Encode(&buf, *rgba, &jpeg.Options{Quality: 100})
-	case imagemeta.PNG:
+	case ".png":
 		err = png.Encode(&buf, *rgba)
+	case ".gif":
+		err = gif.Encode(&buf, *rgba, nil)
+	case ".bmp":
+		err = bmp.Encode(&buf, *rgba)
+	case ".tif", ".tiff":
+		err = tiff.Encode(&buf, *rgba, nil)
+	default:
+		err = errors.New("Unknown image format: " + i.Ext)
 	}
 	if err != nil {
 		return err
@@ -289,22 +352,43 @@ func (i *InlineImage) replaceImage(rgba *image.Image) error {
 
 // Get the size of the image in EMUs.
 func (i *InlineImage) GetSize() (w int64, h int64, err error) {
-	sz, _, err := imgsz.DecodeSize(bytes.NewReader(*i.data))
+	width, height, err := i.pixelSize()
 	if err != nil {
 		return 0, 0, nil
 	}
 
 	wDpi, hDpi := i.GetResolution()
 
-	w = int64(sz.Width/wDpi) * int64(EMUS_PER_INCH)
-	h = int64(sz.Height/hDpi) * int64(EMUS_PER_INCH)
+	w = int64(width/wDpi) * int64(EMUS_PER_INCH)
+	h = int64(height/hDpi) * int64(EMUS_PER_INCH)
 
 	return w, h, nil
 }
 
+// pixelSize returns the size of the image in pixels
+func (i *InlineImage) pixelSize() (w int, h int, err error) {
+	if i.isSVG() {
+		width, height, err := svgSize(*i.data)
+		if err != nil {
+			return 0, 0, err
+		}
+		return int(math.Round(width)), int(math.Round(height)), nil
+	}
+	sz, _, err := imgsz.DecodeSize(bytes.NewReader(*i.data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return sz.Width, sz.Height, nil
+}
+
 // Get the resolution (DPI) of the image.
-// It gets this from EXIF data and defaults to 72 if not found.
+// It gets this from EXIF data and defaults to 72 if not found. SVG images
+// are always 96 DPI.
 func (i *InlineImage) GetResolution() (wDpi int, hDpi int) {
+	if i.isSVG() {
+		return SVG_DPI, SVG_DPI
+	}
+
 	exif, err := i.GetExifData()
 	if err != nil {
 		return DEFAULT_DPI, DEFAULT_DPI
@@ -351,29 +435,38 @@ func getResolutionFromString(resolution string) (int, error) {
 }
 
 func (i *InlineImage) getContentTypes() ([]*contenttypes.ContentType, error) {
-	format, err := i.getImageFormat()
-	if err != nil {
-		return nil, err
-	}
-
-	switch format {
-	case imagemeta.JPEG:
+	switch strings.ToLower(i.Ext) {
+	case ".jpg", ".jpeg":
 		return []*contenttypes.ContentType{&contenttypes.JPG_CONTENT_TYPE, &contenttypes.JPEG_CONTENT_TYPE}, nil
-	case imagemeta.PNG:
+	case ".png":
 		return []*contenttypes.ContentType{&contenttypes.PNG_CONTENT_TYPE}, nil
+	case ".gif":
+		return []*contenttypes.ContentType{&contenttypes.GIF_CONTENT_TYPE}, nil
+	case ".bmp":
+		return []*contenttypes.ContentType{&contenttypes.BMP_CONTENT_TYPE}, nil
+	case ".tif", ".tiff":
+		return []*contenttypes.ContentType{&contenttypes.TIF_CONTENT_TYPE, &contenttypes.TIFF_CONTENT_TYPE}, nil
+	case ".svg":
+		return []*contenttypes.ContentType{&contenttypes.SVG_CONTENT_TYPE, &contenttypes.PNG_CONTENT_TYPE}, nil
 	}
 
-	return []*contenttypes.ContentType{}, nil
+	return nil, errors.New("Unknown image format: " + i.Ext)
 }
 
 func (d *DocxTmpl) addInlineImage(i *InlineImage) (xmlString string, err error) {
 	// Add the image to the document (use underlying docx method)
 	paragraph := d.Docx.AddParagraph()
-	run, err := paragraph.AddInlineDrawing(*i.data)
+	run, err := paragraph.AddInlineDrawing(i.rasterData())
 	if err != nil {
 		return "", err
 	}
 
+	// SVG images are shown from an extension of the PNG fallback's blip
+	var svgRelation string
+	if i.isSVG() {
+		svgRelation = d.Docx.AddRelation(docx.REL_IMAGE, d.Docx.AddMedia("svg", *i.data))
+	}
+
 	// Append the content types
 	contentTypes, err := i.getContentTypes()
 	if err != nil {
@@ -384,7 +477,7 @@ func (d *DocxTmpl) addInlineImage(i *InlineImage) (xmlString string, err error)
 	}
 
 	// Correctly size the image
-	w, h, err := i.GetSize()
+	w, h, err := i.displaySize(d.contentWidth())
 	if err != nil {
 		return "", err
 	}
@@ -392,6 +485,12 @@ func (d *DocxTmpl) addInlineImage(i *InlineImage) (xmlString string, err error)
 		if drawing, ok := child.(*docx.Drawing); ok {
 			drawing.Inline.Extent.CX = w
 			drawing.Inline.Extent.CY = h
+			drawing.Inline.Graphic.GraphicData.Pic.SpPr.Xfrm.Ext = docx.AExt{CX: w, CY: h}
+			drawing.Inline.DocPr.Descr = i.altText
+			drawing.Inline.DocPr.Title = i.title
+			if svgRelation != "" {
+				drawing.Inline.Graphic.GraphicData.Pic.BlipFill.Blip.ExtLst = docx.NewSVGBlipExtList(svgRelation)
+			}
 			break
 		}
 	}
@@ -426,3 +525,129 @@ func (d *DocxTmpl) addInlineImage(i *InlineImage) (xmlString string, err error)
 
 	return xmlString, nil
 }
+
+// Width sets the displayed width. Without a height, the height follows the
+// image's aspect ratio.
+//
+//	img.Width(docxtpl.Cm(5))
+func (i *InlineImage) Width(width Length) *InlineImage {
+	i.width = width
+	return i
+}
+
+// Height sets the displayed height. Without a width, the width follows the
+// image's aspect ratio.
+func (i *InlineImage) Height(height Length) *InlineImage {
+	i.height = height
+	return i
+}
+
+// Size sets the displayed width and height.
+func (i *InlineImage) Size(width, height Length) *InlineImage {
+	i.width, i.height = width, height
+	return i
+}
+
+// MaxSize scales the displayed image down to fit in a box, keeping its aspect
+// ratio. A zero width or height leaves that side unbounded.
+//
+//	img.MaxSize(docxtpl.Cm(8), docxtpl.Cm(6))
+func (i *InlineImage) MaxSize(width, height Length) *InlineImage {
+	i.maxWidth, i.maxHeight = width, height
+	return i
+}
+
+// FitToPage scales the displayed image down, keeping its aspect ratio, to the
+// width between the page margins of the document it's rendered into.
+func (i *InlineImage) FitToPage() *InlineImage {
+	i.fitToPage = true
+	return i
+}
+
+// AltText sets the alternative text read by screen readers.
+func (i *InlineImage) AltText(text string) *InlineImage {
+	i.altText = text
+	return i
+}
+
+// Title sets the image title.
+func (i *InlineImage) Title(title string) *InlineImage {
+	i.title = title
+	return i
+}
+
+// displaySize returns the size the image is displayed at in EMUs, in a document
+// whose page content is contentWidth wide
+func (i *InlineImage) displaySize(contentWidth Length) (w int64, h int64, err error) {
+	w, h, err = i.GetSize()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ratio := i.aspectRatio(w, h)
+
+	switch {
+	case i.width > 0 && i.height > 0:
+		w, h = int64(i.width), int64(i.height)
+	case i.width > 0:
+		w, h = int64(i.width), int64(math.Round(float64(i.width)*ratio))
+	case i.height > 0 && ratio > 0:
+		w, h = int64(math.Round(float64(i.height)/ratio)), int64(i.height)
+	}
+
+	w, h = i.limitSize(w, h, contentWidth)
+	return w, h, nil
+}
+
+// aspectRatio returns the height of the image divided by its width. It comes
+// from the pixel size, which isn't rounded to whole inches like the size in
+// EMUs w by h.
+func (i *InlineImage) aspectRatio(w, h int64) float64 {
+	if width, height, err := i.pixelSize(); err == nil && width > 0 {
+		return float64(height) / float64(width)
+	}
+	if w > 0 {
+		return float64(h) / float64(w)
+	}
+	return 1
+}
+
+// limitSize scales a displayed size down, keeping its aspect ratio, to the
+// image's maximum size and, when fitting to the page, to contentWidth
+func (i *InlineImage) limitSize(w, h int64, contentWidth Length) (int64, int64) {
+	maxWidth := i.maxWidth
+	if i.fitToPage && contentWidth > 0 && (maxWidth == 0 || contentWidth < maxWidth) {
+		maxWidth = contentWidth
+	}
+	scale := 1.0
+	if maxWidth > 0 && w > int64(maxWidth) {
+		scale = float64(maxWidth) / float64(w)
+	}
+	if i.maxHeight > 0 && h > int64(i.maxHeight) {
+		scale = min(scale, float64(i.maxHeight)/float64(h))
+	}
+	if scale < 1 {
+		w = int64(math.Round(float64(w) * scale))
+		h = int64(math.Round(float64(h) * scale))
+	}
+	return w, h
+}
+
+// contentWidth returns the width between the page margins of the document's
+// last section, or the width of a Letter page with 1 inch margins
+func (d *DocxTmpl) contentWidth() Length {
+	for j := len(d.Document.Body.Items) - 1; j >= 0; j-- {
+		sect, ok := d.Document.Body.Items[j].(*docx.SectPr)
+		if !ok || sect.PgSz == nil {
+			continue
+		}
+		width := sect.PgSz.W
+		if sect.PgMar != nil {
+			width -= sect.PgMar.Left + sect.PgMar.Right + sect.PgMar.Gutter
+		}
+		if width > 0 {
+			return Length(width * EMUS_PER_TWIP)
+		}
+	}
+	return Length(defaultTableWidth * EMUS_PER_TWIP)
+}
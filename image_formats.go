@@ -0,0 +1,202 @@
+package docxtpl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/webp"
+)
+
+// =============================================================================
+// Image Formats
+// =============================================================================
+
+const (
+	// SVG_DPI is the resolution SVG sizes in pixels are measured at
+	SVG_DPI = 96
+
+	// maxFallbackSize limits the sides of the PNG rendered for SVG images, in pixels
+	maxFallbackSize = 4096
+)
+
+// imageExtensions are the supported image file extensions. WebP images are
+// converted to PNG, and SVG images get a PNG fallback for older readers.
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tif", ".tiff", ".webp", ".svg"}
+
+// isImageExtension reports whether ext, including its dot, is a supported image format
+func isImageExtension(ext string) bool {
+	ext = strings.ToLower(ext)
+	for _, imageExt := range imageExtensions {
+		if ext == imageExt {
+			return true
+		}
+	}
+	return false
+}
+
+// newInlineImage returns an image of the data in the format of ext, converting
+// WebP images to PNG and rendering the PNG fallback of SVG images
+func newInlineImage(data []byte, ext string) (*InlineImage, error) {
+	ext = strings.ToLower(ext)
+	switch ext {
+	case ".webp":
+		img, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, &InlineImageError{fmt.Sprintf("failed to decode WebP image: %v", err)}
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+		return &InlineImage{data: &data, Ext: ".png"}, nil
+	case ".svg":
+		fallback, err := svgFallback(data)
+		if err != nil {
+			return nil, &InlineImageError{fmt.Sprintf("failed to render SVG image: %v", err)}
+		}
+		return &InlineImage{data: &data, Ext: ext, fallback: &fallback}, nil
+	}
+	return &InlineImage{data: &data, Ext: ext}, nil
+}
+
+// isSVG reports whether the image is an SVG image
+func (i *InlineImage) isSVG() bool {
+	return strings.EqualFold(i.Ext, ".svg")
+}
+
+// rasterData returns the data of the image, or of its PNG fallback for SVG images
+func (i *InlineImage) rasterData() []byte {
+	if i.fallback != nil {
+		return *i.fallback
+	}
+	return *i.data
+}
+
+// isSVGData reports whether the data looks like an SVG document
+func isSVGData(data []byte) bool {
+	head := data[:min(len(data), 1024)]
+	return bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
+// svgSize returns the size of an SVG image in pixels, from the width and height
+// of its root element or else its viewBox
+func svgSize(data []byte) (w float64, h float64, err error) {
+	w, h, _, err = svgDimensions(data)
+	return w, h, err
+}
+
+// svgDimensions returns the size of an SVG image in pixels and the x, y, width
+// and height of its viewBox, which are zero without one
+func svgDimensions(data []byte) (w float64, h float64, viewBox [4]float64, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, viewBox, errors.New("no svg element found")
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return 0, 0, viewBox, errors.New("the root element isn't svg")
+		}
+
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				w = svgLength(attr.Value)
+			case "height":
+				h = svgLength(attr.Value)
+			case "viewBox":
+				fields := strings.FieldsFunc(attr.Value, func(r rune) bool { return r == ',' || r == ' ' })
+				if len(fields) == 4 {
+					for j, field := range fields {
+						viewBox[j], _ = strconv.ParseFloat(field, 64)
+					}
+				}
+			}
+		}
+
+		viewW, viewH := viewBox[2], viewBox[3]
+		hasViewBox := viewW > 0 && viewH > 0
+		if !hasViewBox {
+			viewBox = [4]float64{}
+		}
+		switch {
+		case w > 0 && h > 0:
+		case w > 0 && hasViewBox:
+			h = w * viewH / viewW
+		case h > 0 && hasViewBox:
+			w = h * viewW / viewH
+		case hasViewBox:
+			w, h = viewW, viewH
+		default:
+			// The default size of replaced elements in browsers
+			w, h = 300, 150
+		}
+		return w, h, viewBox, nil
+	}
+}
+
+// svgLength converts an SVG length to pixels. Percentages and invalid lengths
+// are 0.
+func svgLength(value string) float64 {
+	value = strings.TrimSpace(value)
+	units := map[string]float64{"px": 1, "pt": SVG_DPI / 72.0, "pc": SVG_DPI / 6.0, "in": SVG_DPI, "cm": SVG_DPI / 2.54, "mm": SVG_DPI / 25.4}
+	scale := 1.0
+	if len(value) > 2 {
+		if unit, ok := units[value[len(value)-2:]]; ok {
+			value, scale = value[:len(value)-2], unit
+		}
+	}
+	length, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || length < 0 {
+		return 0
+	}
+	return length * scale
+}
+
+// svgFallback renders an SVG image to PNG at its size in pixels
+func svgFallback(data []byte) ([]byte, error) {
+	w, h, viewBox, err := svgDimensions(data)
+	if err != nil {
+		return nil, err
+	}
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// oksvg ignores the viewBox when the size has units
+	icon.ViewBox.X, icon.ViewBox.Y, icon.ViewBox.W, icon.ViewBox.H = viewBox[0], viewBox[1], viewBox[2], viewBox[3]
+	if icon.ViewBox.W == 0 {
+		icon.ViewBox.W, icon.ViewBox.H = w, h
+	}
+
+	if scale := maxFallbackSize / max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	width, height := max(int(math.Round(w)), 1), max(int(math.Round(h)), 1)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	icon.SetTarget(0, 0, float64(width), float64(height))
+	icon.Draw(rasterx.NewDasher(width, height, rasterx.NewScannerGV(width, height, img, img.Bounds())), 1)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
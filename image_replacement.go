@@ -0,0 +1,262 @@
+package docxtpl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/docx"
+	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
+	"github.com/abdokhaire/go-docxgen/internal/hyperlinks"
+	"github.com/abdokhaire/go-docxgen/internal/templatedata"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// Picture Replacement
+// =============================================================================
+
+// picturePlaceholderRegex matches a field placeholder used as the alt text or
+// name of a picture, e.g. {{.Logo}} or {{ .Company.Logo }}
+var picturePlaceholderRegex = regexp.MustCompile(`^\{\{-?\s*\.(\w+(?:\.\w+)*)\s*-?\}\}$`)
+
+// KeepFrameSize makes the image fill the frame of the template picture it
+// replaces, even when that changes its aspect ratio. By default the image is
+// scaled to fit inside the frame.
+func (i *InlineImage) KeepFrameSize() *InlineImage {
+	i.keepFrameSize = true
+	return i
+}
+
+// ReplaceImage replaces the image of the pictures in the body, headers and
+// footers whose alt text or name is key. The pictures keep their position,
+// wrapping and border. The new image is scaled to fit inside each picture's
+// frame unless the image has its own size.
+// An error is returned when no picture matches.
+//
+//	logo, _ := docxtpl.CreateInlineImage("logo.png")
+//	err := doc.ReplaceImage("Company logo", logo)
+func (d *DocxTmpl) ReplaceImage(key string, img *InlineImage) error {
+	imageFor := func(picture *xmlutils.Picture) (*InlineImage, error) {
+		if picture.Descr == key || picture.Name == key {
+			return img, nil
+		}
+		return nil, nil
+	}
+
+	documentXmlString, err := d.getDocumentXml()
+	if err != nil {
+		return err
+	}
+	documentXmlString, replaced, err := d.replacePictures(documentXmlString, documentPath, imageFor)
+	if err != nil {
+		return err
+	}
+	if replaced > 0 {
+		if err := d.setDocumentXml(documentXmlString); err != nil {
+			return err
+		}
+	}
+
+	for i := range d.processableFiles {
+		if headerfooter.IsDocProps(d.processableFiles[i].Name) {
+			continue
+		}
+		content, count, err := d.replacePictures(d.processableFiles[i].Content, d.processableFiles[i].Name, imageFor)
+		if err != nil {
+			return err
+		}
+		d.processableFiles[i].Content = content
+		replaced += count
+	}
+
+	if replaced == 0 {
+		return fmt.Errorf("no picture has the alt text or name %q", key)
+	}
+	return nil
+}
+
+// placeholderImages returns the images of pictures whose alt text or name is a
+// field placeholder, looked up in the template data. Pictures whose field
+// doesn't hold an image are left for the template to report.
+func (d *DocxTmpl) placeholderImages(data any) func(picture *xmlutils.Picture) (*InlineImage, error) {
+	var values map[string]any
+	return func(picture *xmlutils.Picture) (*InlineImage, error) {
+		fields := pictureFields(picture)
+		if fields == nil {
+			return nil, nil
+		}
+
+		if values == nil {
+			var err error
+			values, err = templatedata.DataToMapWithOptions(data, templatedata.Options{UseJSONTags: d.useJSONTags})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var value any = values
+		for _, field := range fields {
+			fields, ok := value.(map[string]any)
+			if !ok {
+				return nil, nil
+			}
+			value = fields[field]
+		}
+
+		switch v := value.(type) {
+		case *InlineImage:
+			return v, nil
+		case string:
+			return d.resolveImage(v)
+		}
+		return nil, nil
+	}
+}
+
+// pictureFields returns the fields of the placeholder used as the alt text or
+// name of a picture, or nil when there is none
+func pictureFields(picture *xmlutils.Picture) []string {
+	for _, key := range []string{picture.Descr, picture.Name} {
+		if match := picturePlaceholderRegex.FindStringSubmatch(strings.TrimSpace(key)); match != nil {
+			return strings.Split(match[1], ".")
+		}
+	}
+	return nil
+}
+
+// hasPlaceholderPictures reports whether the XML of a part has pictures whose
+// alt text or name is a placeholder
+func hasPlaceholderPictures(xmlString string) bool {
+	found := false
+	_, err := xmlutils.ReplacePictures(xmlString, func(picture *xmlutils.Picture) (bool, error) {
+		found = found || pictureFields(picture) != nil
+		return false, nil
+	})
+	return found || err != nil
+}
+
+// replacePictures replaces the images of the pictures in the XML of a part for
+// which imageFor returns an image, returning the XML and the number of pictures
+// replaced. Each image is added to the media and related from the part.
+func (d *DocxTmpl) replacePictures(xmlString, part string, imageFor func(picture *xmlutils.Picture) (*InlineImage, error)) (string, int, error) {
+	var rels *hyperlinks.Relationships
+	relsPath := hyperlinks.GetRelsPath(part)
+	addRelation := func(target string) (string, error) {
+		if part == documentPath {
+			return d.Docx.AddRelation(docx.REL_IMAGE, target), nil
+		}
+		if rels == nil {
+			content, exists, err := d.partContent(relsPath)
+			if err != nil {
+				return "", err
+			}
+			rels = &hyperlinks.Relationships{Xmlns: hyperlinks.RelationshipsNamespace}
+			if exists {
+				if err := xml.Unmarshal([]byte(content), rels); err != nil {
+					return "", err
+				}
+			}
+		}
+		return rels.Add(hyperlinks.ImageType, target), nil
+	}
+
+	replaced := 0
+	type mediaTargets struct{ image, svg string }
+	targets := make(map[*InlineImage]mediaTargets)
+	xmlString, err := xmlutils.ReplacePictures(xmlString, func(picture *xmlutils.Picture) (bool, error) {
+		img, err := imageFor(picture)
+		if err != nil || img == nil {
+			return false, err
+		}
+
+		contentTypes, err := img.getContentTypes()
+		if err != nil {
+			return false, err
+		}
+		for _, contentType := range contentTypes {
+			d.contentTypes.AddContentType(contentType)
+		}
+
+		// SVG images are shown from an extension of their PNG fallback
+		target, ok := targets[img]
+		if !ok {
+			if img.isSVG() {
+				target.image = d.Docx.AddMedia("png", img.rasterData())
+				target.svg = d.Docx.AddMedia("svg", *img.data)
+			} else {
+				target.image = d.Docx.AddMedia(strings.TrimPrefix(strings.ToLower(img.Ext), "."), *img.data)
+			}
+			targets[img] = target
+		}
+		if picture.Embed, err = addRelation(target.image); err != nil {
+			return false, err
+		}
+		picture.SVGEmbed = ""
+		if target.svg != "" {
+			if picture.SVGEmbed, err = addRelation(target.svg); err != nil {
+				return false, err
+			}
+		}
+
+		picture.Width, picture.Height, err = img.frameSize(picture.Width, picture.Height, d.contentWidth())
+		if err != nil {
+			return false, err
+		}
+
+		// Placeholders are removed so the template doesn't render them into the attributes
+		if img.altText != "" || strings.Contains(picture.Descr, "{{") {
+			picture.Descr = img.altText
+		}
+		if img.title != "" || strings.Contains(picture.Title, "{{") {
+			picture.Title = img.title
+		}
+		if strings.Contains(picture.Name, "{{") {
+			picture.Name = "Picture"
+		}
+
+		replaced++
+		return true, nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	if rels != nil {
+		relsXml, err := rels.ToXML()
+		if err != nil {
+			return "", 0, err
+		}
+		d.setPartContent(relsPath, relsXml)
+	}
+	return xmlString, replaced, nil
+}
+
+// frameSize returns the size the image is displayed at when it replaces a
+// picture whose frame is width by height EMUs. The image fits inside the frame
+// unless it fills it or has its own size.
+func (i *InlineImage) frameSize(width, height int64, contentWidth Length) (w int64, h int64, err error) {
+	if i.width > 0 || i.height > 0 || width <= 0 || height <= 0 {
+		return i.displaySize(contentWidth)
+	}
+
+	w, h = width, height
+	if !i.keepFrameSize {
+		sizeW, sizeH, err := i.GetSize()
+		if err != nil {
+			return 0, 0, err
+		}
+		if ratio := i.aspectRatio(sizeW, sizeH); ratio > 0 {
+			if fitted := int64(math.Round(float64(width) * ratio)); fitted <= height {
+				h = fitted
+			} else {
+				w = int64(math.Round(float64(height) / ratio))
+			}
+		}
+	}
+
+	w, h = i.limitSize(w, h, contentWidth)
+	return w, h, nil
+}
@@ -0,0 +1,102 @@
+package docxtpl
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/templatedata"
+)
+
+// =============================================================================
+// Image Resolution
+// =============================================================================
+
+// ImageResolver turns string data values into images when rendering. Strings
+// are only checked for images when a resolver is set with SetImageResolver;
+// otherwise they are always rendered as text.
+type ImageResolver interface {
+	// ResolveImage returns the image a value refers to, or nil when the value
+	// should be rendered as text.
+	ResolveImage(value string) (*InlineImage, error)
+}
+
+// ImageResolverFunc adapts a function to an ImageResolver.
+//
+//	doc.SetImageResolver(docxtpl.ImageResolverFunc(func(value string) (*docxtpl.InlineImage, error) {
+//		data, ok := assets[value]
+//		if !ok {
+//			return nil, nil
+//		}
+//		return docxtpl.CreateInlineImageFromBytes(data, path.Ext(value))
+//	}))
+type ImageResolverFunc func(value string) (*InlineImage, error)
+
+// ResolveImage calls f(value).
+func (f ImageResolverFunc) ResolveImage(value string) (*InlineImage, error) {
+	return f(value)
+}
+
+// FSImageResolver resolves values naming image files in fsys, such as
+// "logos/acme.png". Values that aren't valid paths within fsys, like absolute
+// paths or paths containing "..", and files that don't exist are rendered as
+// text. Note that os.DirFS follows symbolic links out of its
+// directory.
+//
+//	doc.SetImageResolver(docxtpl.FSImageResolver(os.DirFS("/srv/tenant-42/assets")))
+func FSImageResolver(fsys fs.FS) ImageResolver {
+	return ImageResolverFunc(func(value string) (*InlineImage, error) {
+		ext := strings.ToLower(path.Ext(value))
+		if !isImageExtension(ext) || !fs.ValidPath(value) {
+			return nil, nil
+		}
+
+		info, err := fs.Stat(fsys, value)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := fs.ReadFile(fsys, value)
+		if err != nil {
+			return nil, err
+		}
+		return CreateInlineImageFromBytes(data, ext)
+	})
+}
+
+// FilePathImageResolver resolves values that are paths of image files on disk,
+// the automatic detection of earlier versions. Any readable image on the
+// machine can be embedded, so only use it when the data is trusted.
+func FilePathImageResolver() ImageResolver {
+	return ImageResolverFunc(func(value string) (*InlineImage, error) {
+		if isImage, err := templatedata.IsImageFilePath(value); err != nil || !isImage {
+			return nil, err
+		}
+		return CreateInlineImage(value)
+	})
+}
+
+// SetImageResolver enables turning string data values into images with the
+// resolver. Pass nil to render all strings as text, the default.
+//
+//	doc.SetImageResolver(docxtpl.FSImageResolver(os.DirFS("assets")))
+//	err := doc.Render(map[string]any{"Logo": "acme.png"}) // {{.Logo}}
+func (d *DocxTmpl) SetImageResolver(resolver ImageResolver) {
+	d.imageResolver = resolver
+}
+
+// resolveImage returns the image a string value refers to, or nil when it's text
+func (d *DocxTmpl) resolveImage(value string) (*InlineImage, error) {
+	if d.imageResolver == nil {
+		return nil, nil
+	}
+	img, err := d.imageResolver.ResolveImage(value)
+	if err != nil {
+		return nil, ErrImageLoad(value, err)
+	}
+	return img, nil
+}
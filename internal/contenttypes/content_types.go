@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"slices"
+	"strings"
 )
 
 type ContentTypes struct {
@@ -39,8 +40,7 @@ func GetContentTypes(reader io.ReaderAt, size int64) (*ContentTypes, error) {
 			}
 			defer zf.Close()
 
-			dataBuf := make([]byte, f.UncompressedSize64)
-			_, err = zf.Read(dataBuf)
+			dataBuf, err := io.ReadAll(zf)
 			if err != nil {
 				return nil, err
 			}
@@ -61,6 +61,31 @@ func GetContentTypes(reader io.ReaderAt, size int64) (*ContentTypes, error) {
 var PNG_CONTENT_TYPE = ContentType{Extension: "png", ContentType: "image/png"}
 var JPG_CONTENT_TYPE = ContentType{Extension: "jpg", ContentType: "image/jpg"}
 var JPEG_CONTENT_TYPE = ContentType{Extension: "jpeg", ContentType: "image/jpeg"}
+var GIF_CONTENT_TYPE = ContentType{Extension: "gif", ContentType: "image/gif"}
+var BMP_CONTENT_TYPE = ContentType{Extension: "bmp", ContentType: "image/bmp"}
+var TIF_CONTENT_TYPE = ContentType{Extension: "tif", ContentType: "image/tiff"}
+var TIFF_CONTENT_TYPE = ContentType{Extension: "tiff", ContentType: "image/tiff"}
+var SVG_CONTENT_TYPE = ContentType{Extension: "svg", ContentType: "image/svg+xml"}
+
+// imageContentTypes are the content types of image file extensions
+var imageContentTypes = map[string]ContentType{
+	"png":  PNG_CONTENT_TYPE,
+	"jpg":  JPG_CONTENT_TYPE,
+	"jpeg": JPEG_CONTENT_TYPE,
+	"gif":  GIF_CONTENT_TYPE,
+	"bmp":  BMP_CONTENT_TYPE,
+	"tif":  TIF_CONTENT_TYPE,
+	"tiff": TIFF_CONTENT_TYPE,
+	"emf":  {Extension: "emf", ContentType: "image/x-emf"},
+	"wmf":  {Extension: "wmf", ContentType: "image/x-wmf"},
+	"svg":  SVG_CONTENT_TYPE,
+}
+
+// ImageContentType returns the content type of an image file extension such as "png"
+func ImageContentType(extension string) (ContentType, bool) {
+	contentType, ok := imageContentTypes[strings.ToLower(extension)]
+	return contentType, ok
+}
 
 func (ct *ContentTypes) AddContentType(contentType *ContentType) {
 	if slices.Contains(ct.Defaults, *contentType) {
@@ -69,6 +94,36 @@ func (ct *ContentTypes) AddContentType(contentType *ContentType) {
 	ct.Defaults = append(ct.Defaults, *contentType)
 }
 
+// MergeDefaults adds the default content types of other for extensions that ct lacks
+func (ct *ContentTypes) MergeDefaults(other *ContentTypes) {
+	for _, contentType := range other.Defaults {
+		if !slices.ContainsFunc(ct.Defaults, func(c ContentType) bool {
+			return strings.EqualFold(c.Extension, contentType.Extension)
+		}) {
+			ct.Defaults = append(ct.Defaults, contentType)
+		}
+	}
+}
+
+// AddOverride adds the content type of a part, replacing any existing override for the part
+func (ct *ContentTypes) AddOverride(override Override) {
+	for i := range ct.Overrides {
+		if ct.Overrides[i].PartName == override.PartName {
+			ct.Overrides[i] = override
+			return
+		}
+	}
+	ct.Overrides = append(ct.Overrides, override)
+}
+
+// Clone returns a copy of the content types that can be changed independently
+func (ct *ContentTypes) Clone() *ContentTypes {
+	clone := *ct
+	clone.Defaults = slices.Clone(ct.Defaults)
+	clone.Overrides = slices.Clone(ct.Overrides)
+	return &clone
+}
+
 func (ct *ContentTypes) MarshalXml() (string, error) {
 	output, err := xml.MarshalIndent(ct, "", "  ")
 	if err != nil {
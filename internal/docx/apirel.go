@@ -20,6 +20,11 @@
 
 package docx
 
+import (
+	"strconv"
+	"sync/atomic"
+)
+
 // RangeRelationships goes through each doc relation
 func (f *Docx) RangeRelationships(iter func(*Relationship) error) error {
 	for _, r := range f.docRelation.Relationship {
@@ -30,3 +35,19 @@ func (f *Docx) RangeRelationships(iter func(*Relationship) error) error {
 	}
 	return nil
 }
+
+// AddRelation adds a relationship of relType to target, relative to the
+// document part, and returns its id
+//
+//	this func is not thread-safe
+func (f *Docx) AddRelation(relType, target string) string {
+	rel := Relationship{
+		ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&f.rID, 1))),
+		Type:   relType,
+		Target: target,
+	}
+
+	f.docRelation.Relationship = append(f.docRelation.Relationship, rel)
+
+	return rel.ID
+}
@@ -191,6 +191,19 @@ func (w *WTableRow) Justification(val string) *WTableRow {
 	return w
 }
 
+// Style sets the table style by its id, e.g. "TableGrid"
+func (t *Table) Style(val string) *Table {
+	t.TableProperties.Style = &WTableStyle{Val: val}
+	return t
+}
+
+// RepeatHeader repeats the row at the top of each page the table spans.
+// It applies to rows at the start of the table.
+func (w *WTableRow) RepeatHeader() *WTableRow {
+	w.TableRowProperties.TableHeader = &WTableHeader{}
+	return w
+}
+
 // Shade allows to set cell's shade
 func (c *WTableCell) Shade(val, color, fill string) *WTableCell {
 	c.TableCellProperties.Shade = &Shade{
@@ -27,8 +27,11 @@ import (
 	"encoding/xml"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
+	"slices"
 	"sync"
+	"sync/atomic"
 )
 
 // Docx is the structure that allow to access the internal represntation
@@ -148,6 +151,35 @@ func LoadBodyItems(items []interface{}, media []Media) *Docx {
 	return doc
 }
 
+// Fork returns a copy of the document with an empty body, sharing the parts
+// that aren't parsed and the data of the media. Media, relations and ids
+// added to the copy don't change the document, so copies can be used
+// concurrently.
+func (f *Docx) Fork() *Docx {
+	f.slowIDsMu.Lock()
+	slowIDs := maps.Clone(f.slowIDs)
+	f.slowIDsMu.Unlock()
+
+	fork := &Docx{
+		Document: f.Document,
+		docRelation: Relationships{
+			Xmlns:        f.docRelation.Xmlns,
+			Relationship: slices.Clone(f.docRelation.Relationship),
+		},
+		media:        slices.Clone(f.media),
+		mediaNameIdx: maps.Clone(f.mediaNameIdx),
+		rID:          atomic.LoadUintptr(&f.rID),
+		imageID:      atomic.LoadUintptr(&f.imageID),
+		docID:        atomic.LoadUintptr(&f.docID),
+		slowIDs:      slowIDs,
+		template:     f.template,
+		tmplfs:       f.tmplfs,
+		tmpfslst:     f.tmpfslst,
+	}
+	fork.Document.Body = Body{file: fork}
+	return fork
+}
+
 // WriteTo allows to save a docx to a writer
 func (f *Docx) WriteTo(writer io.Writer) (_ int64, err error) {
 	zipWriter := zip.NewWriter(writer)
@@ -160,3 +192,14 @@ func (f *Docx) WriteTo(writer io.Writer) (_ int64, err error) {
 func (f *Docx) Read(_ []byte) (int, error) {
 	return 0, os.ErrInvalid
 }
+
+// ReadFile reads a part of the package that isn't parsed, such as word/styles.xml
+func (f *Docx) ReadFile(name string) ([]byte, error) {
+	if !slices.Contains(f.tmpfslst, name) {
+		return nil, fs.ErrNotExist
+	}
+	if f.template != "" {
+		return fs.ReadFile(f.tmplfs, "xml/"+f.template+"/"+name)
+	}
+	return fs.ReadFile(f.tmplfs, name)
+}
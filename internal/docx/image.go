@@ -21,13 +21,45 @@
 package docx
 
 import (
+	"image"
+	"io"
 	"strconv"
 	"sync/atomic"
+
+	"github.com/fumiama/imgsz"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
 )
 
+// imgsz only reads the size of JPEG, PNG, GIF and WebP images
+func init() {
+	imgsz.RegisterFormat("bmp", "BM????\x00\x00\x00\x00", configSize(bmp.DecodeConfig))
+	imgsz.RegisterFormat("tiff", "II*\x00", configSize(tiff.DecodeConfig))
+	imgsz.RegisterFormat("tiff", "MM\x00*", configSize(tiff.DecodeConfig))
+}
+
+// configSize adapts an image config decoder to imgsz
+func configSize(decodeConfig func(io.Reader) (image.Config, error)) func(io.Reader) (imgsz.Size, error) {
+	return func(r io.Reader) (imgsz.Size, error) {
+		config, err := decodeConfig(r)
+		if err != nil {
+			return imgsz.Size{}, err
+		}
+		return imgsz.Size{Width: config.Width, Height: config.Height}, nil
+	}
+}
+
 // addImage add image to docx and return its rId
 func (f *Docx) addImage(format string, data []byte) string {
 	m := Media{Name: "image" + strconv.Itoa(int(atomic.AddUintptr(&f.imageID, 1))) + "." + format, Data: data}
 	f.addMedia(m)
 	return f.addImageRelation(m)
 }
+
+// AddMedia adds data to word/media under a new image name with the given
+// extension and returns its target relative to the document part
+func (f *Docx) AddMedia(format string, data []byte) string {
+	m := Media{Name: "image" + strconv.Itoa(int(atomic.AddUintptr(&f.imageID, 1))) + "." + format, Data: data}
+	f.addMedia(m)
+	return "media/" + m.Name
+}
@@ -273,10 +273,10 @@ newdoclop:
 					docs = append(docs, ndoc)
 					continue newdoclop
 				}
-				np := o.copymedia(ndoc)
+				np := o.copymedia(ndoc, nil)
 				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, &np)
 			case *Table:
-				nt := o.copymedia(ndoc)
+				nt := o.copymedia(ndoc, nil)
 				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, &nt)
 			default:
 				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, o)
@@ -305,7 +305,9 @@ func (r *Run) copymedia(to *Docx) *Run {
 	return &nr
 }
 
-func (p *Paragraph) copymedia(to *Docx) (np Paragraph) {
+// copymedia copies the paragraph into to with its media and hyperlinks.
+// links resolves hyperlink ids that aren't relationships of the paragraph's file.
+func (p *Paragraph) copymedia(to *Docx, links map[string]string) (np Paragraph) {
 	np = *p
 	np.Children = make([]interface{}, 0, len(p.Children))
 	np.file = to
@@ -317,7 +319,10 @@ func (p *Paragraph) copymedia(to *Docx) (np Paragraph) {
 		if h, ok := pc.(*Hyperlink); ok {
 			tgt, err := p.file.ReferTarget(h.ID)
 			if err != nil {
-				continue
+				var ok bool
+				if tgt, ok = links[h.ID]; !ok {
+					continue
+				}
 			}
 			rid := to.addLinkRelation(tgt)
 			np.Children = append(np.Children, &Hyperlink{
@@ -331,7 +336,7 @@ func (p *Paragraph) copymedia(to *Docx) (np Paragraph) {
 	return
 }
 
-func (t *Table) copymedia(to *Docx) (nt Table) {
+func (t *Table) copymedia(to *Docx, links map[string]string) (nt Table) {
 	nt = *t
 	nt.TableRows = make([]*WTableRow, 0, len(t.TableRows))
 	nt.file = to
@@ -344,7 +349,7 @@ func (t *Table) copymedia(to *Docx) (nt Table) {
 			ntc.Paragraphs = make([]*Paragraph, 0, len(tc.Paragraphs))
 			ntc.file = to
 			for _, p := range tc.Paragraphs {
-				np := p.copymedia(to)
+				np := p.copymedia(to, links)
 				ntc.Paragraphs = append(ntc.Paragraphs, &np)
 			}
 			ntr.TableCells = append(ntr.TableCells, &ntc)
@@ -356,16 +361,25 @@ func (t *Table) copymedia(to *Docx) (nt Table) {
 
 // AppendFile appends all contents in af to f
 func (f *Docx) AppendFile(af *Docx) {
+	f.Document.Body.Items = append(f.Document.Body.Items, f.CopyBodyItems(af, nil)...)
+}
+
+// CopyBodyItems copies the body items of af so they can be inserted in f,
+// adding their media and hyperlinks to f. links holds the targets of
+// hyperlink ids that aren't relationships of af.
+func (f *Docx) CopyBodyItems(af *Docx, links map[string]string) []interface{} {
+	items := make([]interface{}, 0, len(af.Document.Body.Items))
 	for _, item := range af.Document.Body.Items {
 		switch o := item.(type) {
 		case *Paragraph:
-			np := o.copymedia(f)
-			f.Document.Body.Items = append(f.Document.Body.Items, &np)
+			np := o.copymedia(f, links)
+			items = append(items, &np)
 		case *Table:
-			nt := o.copymedia(f)
-			f.Document.Body.Items = append(f.Document.Body.Items, &nt)
+			nt := o.copymedia(f, links)
+			items = append(items, &nt)
 		default:
-			f.Document.Body.Items = append(f.Document.Body.Items, o)
+			items = append(items, o)
 		}
 	}
+	return items
 }
@@ -40,8 +40,12 @@ const (
 const (
 	XMLNS_DRAWINGML_MAIN    = `http://schemas.openxmlformats.org/drawingml/2006/main`
 	XMLNS_DRAWINGML_PICTURE = `http://schemas.openxmlformats.org/drawingml/2006/picture`
+	XMLNS_ASVG              = `http://schemas.microsoft.com/office/drawing/2016/SVG/main`
 )
 
+// SVGBlipExtURI identifies the blip extension holding an SVG image
+const SVGBlipExtURI = `{96DAC541-7B7A-43D3-8B79-37D633B846F1}`
+
 // Drawing element contains photos
 type Drawing struct {
 	XMLName xml.Name `xml:"w:drawing,omitempty"`
@@ -325,6 +329,7 @@ func (r *WPInline) copymedia(to *Docx) *WPInline { //nolint: dupl
 				Blip: ABlip{
 					Embed:  rid,
 					Cstate: r.Graphic.GraphicData.Pic.BlipFill.Blip.Cstate,
+					ExtLst: r.Graphic.GraphicData.Pic.BlipFill.Blip.ExtLst.copyExtensions(r.file, to),
 				},
 				Stretch: r.Graphic.GraphicData.Pic.BlipFill.Stretch,
 			}
@@ -406,6 +411,8 @@ type WPDocPr struct {
 	XMLName xml.Name `xml:"wp:docPr,omitempty"`
 	ID      int      `xml:"id,attr"`
 	Name    string   `xml:"name,attr,omitempty"`
+	Descr   string   `xml:"descr,attr,omitempty"` // alternative text
+	Title   string   `xml:"title,attr,omitempty"`
 }
 
 // UnmarshalXML ...
@@ -420,7 +427,10 @@ func (r *WPDocPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			r.ID = id
 		case "name":
 			r.Name = attr.Value
-
+		case "descr":
+			r.Descr = attr.Value
+		case "title":
+			r.Title = attr.Value
 		default:
 			// ignore other attributes
 		}
@@ -807,6 +817,7 @@ type ABlip struct {
 	Embed       string   `xml:"r:embed,attr"`
 	Cstate      string   `xml:"cstate,attr,omitempty"`
 	AlphaModFix *AAlphaModFix
+	ExtLst      *ABlipExtList
 }
 
 // UnmarshalXML ...
@@ -843,6 +854,15 @@ func (a *ABlip) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 					return err
 				}
 				a.AlphaModFix = &value
+			case "extLst":
+				var value ABlipExtList
+				err = d.DecodeElement(&value, &tt)
+				if err != nil {
+					return err
+				}
+				if len(value.Ext) > 0 {
+					a.ExtLst = &value
+				}
 			default:
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
@@ -855,6 +875,113 @@ func (a *ABlip) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
+// ABlipExtList holds the extensions of a blip. Only the SVG extension is kept.
+type ABlipExtList struct {
+	XMLName xml.Name `xml:"a:extLst,omitempty"`
+	Ext     []ABlipExt
+}
+
+// UnmarshalXML ...
+func (a *ABlipExtList) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if tt, ok := t.(xml.StartElement); ok {
+			if tt.Name.Local == "ext" && getAtt(tt.Attr, "uri") == SVGBlipExtURI {
+				var value ABlipExt
+				err = d.DecodeElement(&value, &tt)
+				if err != nil {
+					return err
+				}
+				if value.SVGBlip != nil {
+					a.Ext = append(a.Ext, value)
+				}
+				continue
+			}
+			err = d.Skip() // skip unsupported tags
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ABlipExt is an extension of a blip
+type ABlipExt struct {
+	XMLName xml.Name `xml:"a:ext,omitempty"`
+	URI     string   `xml:"uri,attr"`
+	SVGBlip *ASVGBlip
+}
+
+// UnmarshalXML ...
+func (a *ABlipExt) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	a.URI = getAtt(start.Attr, "uri")
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if tt, ok := t.(xml.StartElement); ok {
+			if tt.Name.Local == "svgBlip" {
+				a.SVGBlip = &ASVGBlip{XMLNS: XMLNS_ASVG, Embed: getAtt(tt.Attr, "embed")}
+			}
+			err = d.Skip()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ASVGBlip refers to the SVG image shown instead of the blip by readers that
+// support it. The blip itself is a raster fallback.
+type ASVGBlip struct {
+	XMLName xml.Name `xml:"asvg:svgBlip,omitempty"`
+	XMLNS   string   `xml:"xmlns:asvg,attr"`
+	Embed   string   `xml:"r:embed,attr"`
+}
+
+// NewSVGBlipExtList returns the extensions showing the SVG image with the rId
+func NewSVGBlipExtList(rid string) *ABlipExtList {
+	return &ABlipExtList{Ext: []ABlipExt{{URI: SVGBlipExtURI, SVGBlip: &ASVGBlip{XMLNS: XMLNS_ASVG, Embed: rid}}}}
+}
+
+// copyExtensions returns the blip extensions with their media copied into to
+func (a *ABlipExtList) copyExtensions(from, to *Docx) *ABlipExtList {
+	if a == nil {
+		return nil
+	}
+	var exts ABlipExtList
+	for _, ext := range a.Ext {
+		tgt, err := from.ReferTarget(ext.SVGBlip.Embed)
+		if err != nil || !strings.HasPrefix(tgt, "media/") {
+			continue
+		}
+		m := from.Media(tgt[6:])
+		if m == nil {
+			continue
+		}
+		rid := to.addImage(tgt[strings.LastIndex(tgt, ".")+1:], m.Data)
+		exts.Ext = append(exts.Ext, ABlipExt{URI: ext.URI, SVGBlip: &ASVGBlip{XMLNS: XMLNS_ASVG, Embed: rid}})
+	}
+	if len(exts.Ext) == 0 {
+		return nil
+	}
+	return &exts
+}
+
 // AAlphaModFix ...
 type AAlphaModFix struct {
 	XMLName xml.Name `xml:"a:alphaModFix,omitempty"`
@@ -1415,6 +1542,7 @@ func (r *WPAnchor) copymedia(to *Docx) *WPAnchor { //nolint: dupl
 				Blip: ABlip{
 					Embed:  rid,
 					Cstate: r.Graphic.GraphicData.Pic.BlipFill.Blip.Cstate,
+					ExtLst: r.Graphic.GraphicData.Pic.BlipFill.Blip.ExtLst.copyExtensions(r.file, to),
 				},
 				Stretch: r.Graphic.GraphicData.Pic.BlipFill.Stretch,
 			}
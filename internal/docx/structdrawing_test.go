@@ -104,3 +104,28 @@ func TestDrawingStructure(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestBlipSVGExtension(t *testing.T) {
+	in := `<a:blip r:embed="rId5"><a:extLst>` +
+		`<a:ext uri="{28A0092B-C50C-407E-A947-70E740481C1C}"><a14:useLocalDpi val="0"></a14:useLocalDpi></a:ext>` +
+		`<a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}"><asvg:svgBlip xmlns:asvg="` + XMLNS_ASVG + `" r:embed="rId6"></asvg:svgBlip></a:ext>` +
+		`</a:extLst></a:blip>`
+	var blip ABlip
+	err := xml.Unmarshal([]byte(in), &blip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blip.ExtLst == nil || len(blip.ExtLst.Ext) != 1 || blip.ExtLst.Ext[0].SVGBlip.Embed != "rId6" {
+		t.Fatalf("svg extension not kept: %+v", blip.ExtLst)
+	}
+	out, err := xml.Marshal(&blip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<a:blip r:embed="rId5"><a:extLst>` +
+		`<a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}"><asvg:svgBlip xmlns:asvg="` + XMLNS_ASVG + `" r:embed="rId6"></asvg:svgBlip></a:ext>` +
+		`</a:extLst></a:blip>`
+	if string(out) != expected {
+		t.Fatalf("unexpected blip %s", out)
+	}
+}
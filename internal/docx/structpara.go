@@ -46,6 +46,7 @@ type ParagraphProperties struct {
 	OverflowPunct  *OverflowPunct
 
 	RunProperties *RunProperties
+	SectPr        *SectPr // properties of the section the paragraph ends
 }
 
 // UnmarshalXML ...
@@ -108,6 +109,13 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 					return err
 				}
 				p.RunProperties = &value
+			case "sectPr":
+				var value SectPr
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				p.SectPr = &value
 			case "pStyle":
 				p.Style = &Style{Val: getAtt(tt.Attr, "val")}
 			case "numPr":
@@ -29,8 +29,10 @@ type SectPr struct {
 	XMLName          xml.Name           `xml:"w:sectPr,omitempty"` // properties of the document, including paper size
 	HeaderReferences []*HeaderReference `xml:"w:headerReference,omitempty"`
 	FooterReferences []*FooterReference `xml:"w:footerReference,omitempty"`
+	Type             *SectType          `xml:"w:type,omitempty"` // how the section starts
 	PgSz             *PgSz              `xml:"w:pgSz,omitempty"`
 	PgMar            *PgMar             `xml:"w:pgMar,omitempty"`
+	PgNumType        *PgNumType         `xml:"w:pgNumType,omitempty"` // page numbering of the section
 	Cols             *Cols              `xml:"w:cols,omitempty"`
 	DocGrid          *DocGrid           `xml:"w:docGrid,omitempty"`
 	TitlePg          *TitlePg           `xml:"w:titlePg,omitempty"` // Different first page header/footer
@@ -48,6 +50,17 @@ type FooterReference struct {
 	ID   string `xml:"r:id,attr"`   // relationship ID (e.g., "rId6")
 }
 
+// SectType is the kind of break starting a section
+type SectType struct {
+	Val string `xml:"w:val,attr"` // "nextPage", "oddPage", "evenPage", "continuous", "nextColumn"
+}
+
+// PgNumType sets the page numbering of a section
+type PgNumType struct {
+	Fmt   string `xml:"w:fmt,attr,omitempty"`   // number format, such as "lowerRoman"
+	Start *int   `xml:"w:start,attr,omitempty"` // number of the first page, following the previous section when nil
+}
+
 // TitlePg indicates different first page header/footer
 type TitlePg struct {
 	Val string `xml:"w:val,attr,omitempty"`
@@ -107,6 +120,18 @@ func (sect *SectPr) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 					return err
 				}
 				sect.FooterReferences = append(sect.FooterReferences, &value)
+			case "type":
+				sect.Type = &SectType{Val: getAtt(tt.Attr, "val")}
+			case "pgNumType":
+				value := PgNumType{Fmt: getAtt(tt.Attr, "fmt")}
+				if v := getAtt(tt.Attr, "start"); v != "" {
+					start, err := strconv.Atoi(v)
+					if err != nil {
+						return err
+					}
+					value.Start = &start
+				}
+				sect.PgNumType = &value
 			case "pgSz":
 				var value PgSz
 				err = d.DecodeElement(&value, &tt)
@@ -0,0 +1,57 @@
+/*
+   Copyright (c) 2024 mabiao0525 (马飚)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParagraphSectionProperties(t *testing.T) {
+	in := `<w:p><w:pPr><w:sectPr>` +
+		`<w:headerReference w:type="default" r:id="rId7"></w:headerReference>` +
+		`<w:type w:val="oddPage"></w:type>` +
+		`<w:pgSz w:w="11906" w:h="16838"></w:pgSz>` +
+		`<w:pgNumType w:fmt="lowerRoman" w:start="1"></w:pgNumType>` +
+		`</w:sectPr></w:pPr></w:p>`
+	var p Paragraph
+	err := xml.Unmarshal([]byte(in), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sect := p.Properties.SectPr
+	if sect == nil || sect.Type == nil || sect.Type.Val != "oddPage" {
+		t.Fatalf("section break not kept: %+v", sect)
+	}
+	if sect.PgNumType == nil || sect.PgNumType.Start == nil || *sect.PgNumType.Start != 1 || sect.PgNumType.Fmt != "lowerRoman" {
+		t.Fatalf("page numbering not kept: %+v", sect.PgNumType)
+	}
+	out, err := xml.Marshal(sect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:sectPr>` +
+		`<w:headerReference w:type="default" r:id="rId7"></w:headerReference>` +
+		`<w:type w:val="oddPage"></w:type>` +
+		`<w:pgSz w:w="11906" w:h="16838"></w:pgSz>` +
+		`<w:pgNumType w:fmt="lowerRoman" w:start="1"></w:pgNumType>` +
+		`</w:sectPr>`
+	if string(out) != expected {
+		t.Fatalf("unexpected section properties %s", out)
+	}
+}
@@ -466,6 +466,7 @@ func (w *WTableRow) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 type WTableRowProperties struct {
 	XMLName        xml.Name `xml:"w:trPr,omitempty"`
 	TableRowHeight *WTableRowHeight
+	TableHeader    *WTableHeader
 	Justification  *Justification
 }
 
@@ -500,6 +501,14 @@ func (t *WTableRowProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 				if err != nil {
 					return err
 				}
+			case "tblHeader":
+				if val := getAtt(tt.Attr, "val"); val != "false" && val != "0" {
+					t.TableHeader = new(WTableHeader)
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
 			case "jc":
 				th := new(Justification)
 				for _, attr := range tt.Attr {
@@ -531,6 +540,11 @@ type WTableRowHeight struct {
 	Val     int64    `xml:"w:val,attr"`
 }
 
+// WTableHeader marks a row to be repeated at the top of each page the table spans.
+type WTableHeader struct {
+	XMLName xml.Name `xml:"w:tblHeader,omitempty"`
+}
+
 // WTableCell represents a cell within a table.
 type WTableCell struct {
 	XMLName             xml.Name `xml:"w:tc,omitempty"`
@@ -3,5 +3,6 @@ package functions
 import "text/template"
 
 // DefaultFuncMap is empty by default. Users can register their own functions
-// using RegisterFunction or RegisterFuncMap (e.g., with Sprig).
+// using RegisterFunction or RegisterFuncMap (e.g., with Sprig or the opt-in
+// DocxFuncMap library).
 var DefaultFuncMap = template.FuncMap{}
@@ -0,0 +1,226 @@
+package functions
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const (
+	// wordPageBreak closes the text of the run holding the placeholder, adds a
+	// page break and reopens the text
+	wordPageBreak = `</w:t><w:br w:type="page"/><w:t>`
+
+	checkedBox   = "\u2612" // ☒
+	uncheckedBox = "\u2610" // ☐
+)
+
+// defaultFormatter formats the numbers of the library outside documents
+var defaultFormatter = NewLocaleFormatter(language.AmericanEnglish)
+
+// dateLayouts are the layouts tried, in order, for dates given as text
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02", time.RFC1123Z, time.RFC1123}
+
+// NumberFunc and CurrencyFunc are the types of the formatNumber and
+// formatCurrency functions of the library, which documents format with the
+// locale of their renders instead
+type (
+	NumberFunc   func(decimals int, value any) (string, error)
+	CurrencyFunc func(symbol string, value any) (string, error)
+)
+
+// DocxFuncMap returns the functions of the opt-in library. They take data
+// values, which are escaped for XML before rendering, as well as string
+// literals from the template, and return escaped text ready for the text of
+// a run.
+func DocxFuncMap() template.FuncMap {
+	return template.FuncMap{
+		"breaks":         breaks,
+		"pageBreak":      pageBreak,
+		"nbsp":           nbsp,
+		"checkbox":       checkbox,
+		"formatDate":     formatDate,
+		"formatNumber":   NumberFunc(formatNumber),
+		"formatCurrency": CurrencyFunc(formatCurrency),
+		"default":        defaultValue,
+		"join":           join,
+		"upper":          upper,
+		"lower":          lower,
+		"title":          title,
+	}
+}
+
+// breaks renders newlines as line breaks and tabs as tabs.
+//
+//	{{breaks .Address}}
+func breaks(value any) (string, error) {
+	return xmlutils.EscapeXmlString(textOf(value))
+}
+
+// pageBreak starts a new page.
+//
+//	{{pageBreak}}
+func pageBreak() string {
+	return wordPageBreak
+}
+
+// nbsp returns a non-breaking space.
+//
+//	Mr.{{nbsp}}{{.Name}}
+func nbsp() string {
+	return "\u00a0"
+}
+
+// checkbox returns a checked box for true values and an empty box otherwise.
+//
+//	{{checkbox .Accepted}} I accept the terms
+func checkbox(value any) string {
+	if truth, ok := template.IsTrue(value); ok && truth {
+		return checkedBox
+	}
+	return uncheckedBox
+}
+
+// formatDate formats a time.Time, or a date written as text, with a Go layout.
+// Empty values give empty text.
+//
+//	{{formatDate "2 January 2006" .Date}}
+func formatDate(layout string, value any) (string, error) {
+	date, ok, err := dateOf(value)
+	if err != nil {
+		return "", fmt.Errorf("formatDate: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+	return xmlutils.EscapeXmlString(date.Format(textOf(layout)))
+}
+
+// dateOf returns the date of a time.Time, or of a date written as text.
+// Empty values give no date.
+func dateOf(value any) (time.Time, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case time.Time:
+		return v, true, nil
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false, nil
+		}
+		return *v, true, nil
+	default:
+		text := strings.TrimSpace(textOf(v))
+		if text == "" {
+			return time.Time{}, false, nil
+		}
+		date, err := parseDate(text)
+		return date, err == nil, err
+	}
+}
+
+// parseDate parses a date written in one of the dateLayouts
+func parseDate(text string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", text)
+}
+
+// formatNumber formats a number with the number of decimals and the
+// separators of en-US. Documents format with the locale of the render.
+//
+//	{{formatNumber 2 .Total}} → 1,234.50
+func formatNumber(decimals int, value any) (string, error) {
+	return defaultFormatter.FormatNumber(decimals, value)
+}
+
+// formatCurrency formats an amount with two decimals and the currency symbol
+// placed as en-US places it. Documents format with the locale of the render.
+//
+//	{{formatCurrency "$" .Total}} → $1,234.50
+func formatCurrency(symbol string, value any) (string, error) {
+	return defaultFormatter.FormatCurrency(symbol, value)
+}
+
+// defaultValue returns the value, or the fallback when the value is empty.
+//
+//	{{default "N/A" .Phone}}
+func defaultValue(fallback any, value any) (any, error) {
+	if truth, ok := template.IsTrue(value); ok && truth {
+		return value, nil
+	}
+	if text, ok := fallback.(string); ok {
+		return xmlutils.EscapeXmlString(textOf(text))
+	}
+	return fallback, nil
+}
+
+// join joins the items of a list with a separator, in which newlines are line
+// breaks.
+//
+//	{{join "\n" .Lines}}
+func join(separator string, items any) (string, error) {
+	v := reflect.ValueOf(items)
+	if items == nil {
+		return "", nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %T is not a list", items)
+	}
+
+	separator, err := xmlutils.EscapeXmlString(textOf(separator))
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		if parts[i], err = xmlutils.EscapeXmlString(textOf(v.Index(i).Interface())); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(parts, separator), nil
+}
+
+// upper converts text to upper case, following the Unicode case mappings.
+//
+//	{{upper .Name}}
+func upper(value any) (string, error) {
+	return xmlutils.EscapeXmlString(cases.Upper(language.Und).String(textOf(value)))
+}
+
+// lower converts text to lower case, following the Unicode case mappings.
+//
+//	{{lower .Email}}
+func lower(value any) (string, error) {
+	return xmlutils.EscapeXmlString(cases.Lower(language.Und).String(textOf(value)))
+}
+
+// title capitalizes the first letter of every word, following the Unicode
+// case mappings.
+//
+//	{{title .City}}
+func title(value any) (string, error) {
+	return xmlutils.EscapeXmlString(cases.Title(language.Und).String(textOf(value)))
+}
+
+// textOf returns the text of a value. Strings are unescaped, so escaped data
+// values and literals from the template give the same text.
+func textOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return xmlutils.UnescapeXmlString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,128 @@
+package functions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocxFuncMap(t *testing.T) {
+	for name, fn := range DocxFuncMap() {
+		assert.True(t, FunctionNameValid(name), name)
+		assert.NoError(t, FunctionValid(fn), name)
+	}
+}
+
+func TestDocxFunctions(t *testing.T) {
+	t.Run("breaks", func(t *testing.T) {
+		result, err := breaks("a & b\nc\td")
+		require.NoError(t, err)
+		assert.Equal(t, "a &amp; b</w:t><w:br/><w:t>c</w:t><w:tab/><w:t>d", result)
+
+		// Escaped data values give the same result
+		result, err = breaks("a &amp; b</w:t><w:br/><w:t>c&#x9;d")
+		require.NoError(t, err)
+		assert.Equal(t, "a &amp; b</w:t><w:br/><w:t>c</w:t><w:tab/><w:t>d", result)
+	})
+
+	t.Run("checkbox", func(t *testing.T) {
+		assert.Equal(t, "☒", checkbox(true))
+		assert.Equal(t, "☐", checkbox(false))
+		assert.Equal(t, "☐", checkbox(nil))
+		assert.Equal(t, "☒", checkbox("yes"))
+	})
+
+	t.Run("formatDate", func(t *testing.T) {
+		date := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+		tests := []struct {
+			layout string
+			value  any
+			want   string
+		}{
+			{"2 January 2006", date, "5 March 2024"},
+			{"02/01/2006", &date, "05/03/2024"},
+			{"Jan 2, 2006", "2024-03-05", "Mar 5, 2024"},
+			{"15:04", "2024-03-05T14:30:00Z", "14:30"},
+			{"2006", "", ""},
+			{"2006", nil, ""},
+		}
+		for _, tt := range tests {
+			result, err := formatDate(tt.layout, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		}
+
+		_, err := formatDate("2006", "yesterday")
+		assert.Error(t, err)
+	})
+
+	t.Run("formatNumber and formatCurrency", func(t *testing.T) {
+		tests := []struct {
+			decimals int
+			value    any
+			want     string
+		}{
+			{2, 1234.5, "1,234.50"},
+			{0, 1234567, "1,234,567"},
+			{2, -987654.321, "-987,654.32"},
+			{1, "42", "42.0"},
+			{0, 999, "999"},
+			{3, uint64(18446744073709551615), "18,446,744,073,709,551,615.000"},
+		}
+		for _, tt := range tests {
+			result, err := formatNumber(tt.decimals, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		}
+
+		result, err := formatCurrency("€", -1234.5)
+		require.NoError(t, err)
+		assert.Equal(t, "-€1,234.50", result)
+
+		_, err = formatNumber(2, "abc")
+		assert.Error(t, err)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		result, err := defaultValue("N/A & none", "")
+		require.NoError(t, err)
+		assert.Equal(t, "N/A &amp; none", result)
+
+		result, err = defaultValue("N/A", "Tom")
+		require.NoError(t, err)
+		assert.Equal(t, "Tom", result)
+
+		result, err = defaultValue(0, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, result)
+	})
+
+	t.Run("join", func(t *testing.T) {
+		result, err := join("\n", []any{"a &amp; b", "c", 3})
+		require.NoError(t, err)
+		assert.Equal(t, "a &amp; b</w:t><w:br/><w:t>c</w:t><w:br/><w:t>3", result)
+
+		result, err = join(", ", []string{"x", "y"})
+		require.NoError(t, err)
+		assert.Equal(t, "x, y", result)
+
+		_, err = join(", ", "x")
+		assert.Error(t, err)
+	})
+
+	t.Run("case", func(t *testing.T) {
+		result, err := upper("straße &amp; co")
+		require.NoError(t, err)
+		assert.Equal(t, "STRASSE &amp; CO", result)
+
+		result, err = lower("ΟΔΟΣ")
+		require.NoError(t, err)
+		assert.Equal(t, "οδος", result)
+
+		result, err = title("élan vital o'neil")
+		require.NoError(t, err)
+		assert.Equal(t, "Élan Vital O&#39;neil", result)
+	})
+}
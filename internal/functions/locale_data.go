@@ -0,0 +1,141 @@
+package functions
+
+import "golang.org/x/text/language"
+
+// The tables below hold the CLDR data golang.org/x/text doesn't expose: its
+// date package has no exported API and its currency package always writes the
+// symbol before the amount. They give the names of months and days, the date
+// formats and where the currency symbol goes for the listed languages, which
+// other locales of the same language share. Other languages have no date
+// formats and place the currency symbol as the CLDR root locale does.
+
+// dateNames are the names of the months and days of a language
+type dateNames struct {
+	months      [12]string
+	shortMonths [12]string
+	days        [7]string // Sunday first, like time.Weekday
+	shortDays   [7]string
+}
+
+// dateLocale is the date data of a locale
+type dateLocale struct {
+	tag   string
+	names *dateNames
+	// styles are the CLDR short, medium, long and full date formats written as
+	// Go layouts
+	styles map[string]string
+}
+
+var (
+	englishNames = &dateNames{
+		months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	}
+	germanNames = &dateNames{
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	}
+	frenchNames = &dateNames{
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	}
+	spanishNames = &dateNames{
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	}
+	italianNames = &dateNames{
+		months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		shortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+	}
+	dutchNames = &dateNames{
+		months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortDays:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+	}
+	portugueseNames = &dateNames{
+		months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		shortMonths: [12]string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+		days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortDays:   [7]string{"dom.", "seg.", "ter.", "qua.", "qui.", "sex.", "sáb."},
+	}
+	arabicNames = &dateNames{
+		months:      [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		shortMonths: [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		days:        [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+		shortDays:   [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+	}
+)
+
+// dateLocales are the locales with date data
+var dateLocales = []dateLocale{
+	{"en", englishNames, map[string]string{"short": "1/2/06", "medium": "Jan 2, 2006", "long": "January 2, 2006", "full": "Monday, January 2, 2006"}},
+	{"en-GB", englishNames, map[string]string{"short": "02/01/2006", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"}},
+	{"de", germanNames, map[string]string{"short": "02.01.06", "medium": "02.01.2006", "long": "2. January 2006", "full": "Monday, 2. January 2006"}},
+	{"fr", frenchNames, map[string]string{"short": "02/01/2006", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"}},
+	{"es", spanishNames, map[string]string{"short": "2/1/06", "medium": "2 Jan 2006", "long": "2 de January de 2006", "full": "Monday, 2 de January de 2006"}},
+	{"it", italianNames, map[string]string{"short": "02/01/06", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"}},
+	{"nl", dutchNames, map[string]string{"short": "02-01-2006", "medium": "2 Jan 2006", "long": "2 January 2006", "full": "Monday 2 January 2006"}},
+	{"pt", portugueseNames, map[string]string{"short": "02/01/2006", "medium": "2 de Jan de 2006", "long": "2 de January de 2006", "full": "Monday, 2 de January de 2006"}},
+	{"ar", arabicNames, map[string]string{"short": "2\u200f/1\u200f/2006", "medium": "02\u200f/01\u200f/2006", "long": "2 January 2006", "full": "Monday، 2 January 2006"}},
+}
+
+// currencyLocale places the currency symbol of a locale, before or after the
+// amount and with or without a space
+type currencyLocale struct {
+	tag         string
+	symbolFirst bool
+	spaced      bool
+}
+
+// rootCurrency is the currency placement of the CLDR root locale, used by
+// languages that aren't listed
+var rootCurrency = currencyLocale{"und", true, true}
+
+// currencyLocales are the locales with currency placement
+var currencyLocales = []currencyLocale{
+	{"en", true, false},
+	{"de", false, true},
+	{"de-AT", true, true},
+	{"de-CH", true, true},
+	{"fr", false, true},
+	{"es", false, true},
+	{"es-MX", true, false},
+	{"es-US", true, false},
+	{"it", false, true},
+	{"nl", true, true},
+	{"pt", true, true},
+	{"pt-PT", false, true},
+	{"ar", false, true},
+}
+
+var (
+	dateMatcher     = newMatcher(len(dateLocales), func(i int) string { return dateLocales[i].tag })
+	currencyMatcher = newMatcher(len(currencyLocales), func(i int) string { return currencyLocales[i].tag })
+)
+
+// newMatcher returns a matcher for the tags of a table
+func newMatcher(count int, tag func(int) string) language.Matcher {
+	tags := make([]language.Tag, count)
+	for i := range tags {
+		tags[i] = language.MustParse(tag(i))
+	}
+	return language.NewMatcher(tags)
+}
+
+// matchLocale returns the index of the closest locale of a table, if one has
+// the same language
+func matchLocale(matcher language.Matcher, tag language.Tag) (int, bool) {
+	_, index, confidence := matcher.Match(tag)
+	return index, confidence >= language.High
+}
@@ -0,0 +1,245 @@
+package functions
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// dateNameRegex matches the parts of a Go layout written with the names of
+// months and days, longest first like the time package
+var dateNameRegex = regexp.MustCompile(`January|Monday|Jan|Mon`)
+
+// LocaleFormatter formats numbers, amounts and dates following the CLDR
+// conventions of a locale. Its methods take data values, which are escaped
+// for XML before rendering, and return escaped text.
+type LocaleFormatter struct {
+	tag     language.Tag
+	printer *message.Printer
+}
+
+// NewLocaleFormatter returns a formatter for a locale
+func NewLocaleFormatter(tag language.Tag) *LocaleFormatter {
+	return &LocaleFormatter{tag: tag, printer: message.NewPrinter(tag)}
+}
+
+// Number formats a number with the number of decimals and the separators of
+// the locale.
+//
+//	{{localNumber 2 .Total}} → 1.234,50 (de)
+func (f *LocaleFormatter) Number(decimals int, value any) (string, error) {
+	n, err := numberOf(value)
+	if err != nil {
+		return "", fmt.Errorf("localNumber: %w", err)
+	}
+	return xmlutils.EscapeXmlString(f.printer.Sprint(number.Decimal(n, number.Scale(max(decimals, 0)))))
+}
+
+// Percent formats a fraction as a percentage with the number of decimals.
+//
+//	{{localPercent 1 .Rate}} → 12,5 % (de)
+func (f *LocaleFormatter) Percent(decimals int, value any) (string, error) {
+	n, err := numberOf(value)
+	if err != nil {
+		return "", fmt.Errorf("localPercent: %w", err)
+	}
+	return xmlutils.EscapeXmlString(f.printer.Sprint(number.Percent(n, number.Scale(max(decimals, 0)))))
+}
+
+// Currency formats an amount in a currency, given by its ISO 4217 code or
+// empty for the currency of the locale's region, with the currency's decimals
+// and the symbol placed as the locale places it.
+//
+//	{{localCurrency "EUR" .Total}} → 1.234,50 € (de), €1,234.50 (en)
+func (f *LocaleFormatter) Currency(code string, value any) (string, error) {
+	unit, err := f.currencyUnit(textOf(code))
+	if err != nil {
+		return "", fmt.Errorf("localCurrency: %w", err)
+	}
+	n, err := numberOf(value)
+	if err != nil {
+		return "", fmt.Errorf("localCurrency: %w", err)
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return f.amount(f.printer.Sprint(currency.Symbol(unit)), scale, n)
+}
+
+// FormatNumber formats a number like Number, for the formatNumber function of
+// the Word function library.
+//
+//	{{formatNumber 2 .Total}} → 1.234,50 (de), 1,234.50 (en)
+func (f *LocaleFormatter) FormatNumber(decimals int, value any) (string, error) {
+	n, err := numberOf(value)
+	if err != nil {
+		return "", fmt.Errorf("formatNumber: %w", err)
+	}
+	return xmlutils.EscapeXmlString(f.printer.Sprint(number.Decimal(n, number.Scale(max(decimals, 0)))))
+}
+
+// FormatCurrency formats an amount with two decimals and a currency symbol
+// placed as the locale places it, for the formatCurrency function of the Word
+// function library.
+//
+//	{{formatCurrency "€" .Total}} → 1.234,50 € (de), €1,234.50 (en)
+func (f *LocaleFormatter) FormatCurrency(symbol string, value any) (string, error) {
+	n, err := numberOf(value)
+	if err != nil {
+		return "", fmt.Errorf("formatCurrency: %w", err)
+	}
+	return f.amount(textOf(symbol), 2, n)
+}
+
+// amount formats a number with the decimals and a currency symbol placed as
+// the locale places it
+func (f *LocaleFormatter) amount(symbol string, decimals int, n any) (string, error) {
+	negative := false
+	switch v := n.(type) {
+	case int64:
+		if v < 0 {
+			negative, n = true, uint64(-v)
+		}
+	case float64:
+		if v < 0 {
+			negative, n = true, math.Abs(v)
+		}
+	}
+	amount := f.printer.Sprint(number.Decimal(n, number.Scale(decimals)))
+
+	placement := rootCurrency
+	if index, ok := matchLocale(currencyMatcher, f.tag); ok {
+		placement = currencyLocales[index]
+	}
+	space := ""
+	if placement.spaced {
+		space = "\u00a0" // no-break space, as in CLDR
+	}
+	formatted := amount + space + symbol
+	if placement.symbolFirst {
+		formatted = symbol + space + amount
+	}
+	if negative {
+		formatted = f.minusSign() + formatted
+	}
+	return xmlutils.EscapeXmlString(formatted)
+}
+
+// currencyUnit returns the currency of an ISO 4217 code, or of the locale's
+// region when the code is empty
+func (f *LocaleFormatter) currencyUnit(code string) (currency.Unit, error) {
+	if code == "" {
+		unit, confidence := currency.FromTag(f.tag)
+		if confidence == language.No {
+			return currency.Unit{}, fmt.Errorf("locale %s has no currency", f.tag)
+		}
+		return unit, nil
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return currency.Unit{}, fmt.Errorf("%q is not a currency code", code)
+	}
+	return unit, nil
+}
+
+// minusSign returns the minus sign of the locale, with any bidi marks
+func (f *LocaleFormatter) minusSign() string {
+	return strings.TrimSuffix(f.printer.Sprint(number.Decimal(-1)), f.printer.Sprint(number.Decimal(1)))
+}
+
+// Date formats a time.Time, or a date written as text, with the short,
+// medium, long or full date format of the locale, or with a Go layout whose
+// month and day names are translated. Empty values give empty text. Locales
+// without date data can only use layouts without names.
+//
+//	{{localDate "long" .Date}} → 5. März 2024 (de)
+//	{{localDate "Monday 2 January" .Date}} → mardi 5 mars (fr)
+func (f *LocaleFormatter) Date(layout string, value any) (string, error) {
+	date, ok, err := dateOf(value)
+	if err != nil {
+		return "", fmt.Errorf("localDate: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+
+	layout = textOf(layout)
+	index, ok := matchLocale(dateMatcher, f.tag)
+	if !ok {
+		if _, style := dateLocales[0].styles[layout]; style || dateNameRegex.MatchString(layout) {
+			return "", fmt.Errorf("localDate: no date formats for locale %s", f.tag)
+		}
+		return xmlutils.EscapeXmlString(f.localDigits(date.Format(layout)))
+	}
+	locale := dateLocales[index]
+	if style, ok := locale.styles[layout]; ok {
+		layout = style
+	}
+
+	var formatted strings.Builder
+	last := 0
+	for _, name := range dateNameRegex.FindAllStringIndex(layout, -1) {
+		formatted.WriteString(f.localDigits(date.Format(layout[last:name[0]])))
+		switch layout[name[0]:name[1]] {
+		case "January":
+			formatted.WriteString(locale.names.months[date.Month()-1])
+		case "Jan":
+			formatted.WriteString(locale.names.shortMonths[date.Month()-1])
+		case "Monday":
+			formatted.WriteString(locale.names.days[date.Weekday()])
+		case "Mon":
+			formatted.WriteString(locale.names.shortDays[date.Weekday()])
+		}
+		last = name[1]
+	}
+	formatted.WriteString(f.localDigits(date.Format(layout[last:])))
+	return xmlutils.EscapeXmlString(formatted.String())
+}
+
+// localDigits writes the digits of text with the digits of the locale's
+// numbering system
+func (f *LocaleFormatter) localDigits(text string) string {
+	digits := []rune(f.printer.Sprint(number.Decimal(1234567890, number.NoSeparator())))
+	if string(digits) == "1234567890" || len(digits) != 10 {
+		return text
+	}
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return r
+		}
+		return digits[(r-'0'+9)%10]
+	}, text)
+}
+
+// numberOf returns a number, or a number written as text, as an int64,
+// uint64 or float64. Integers are kept exact.
+func numberOf(value any) (any, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		text := strings.TrimSpace(textOf(v.String()))
+		if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return integer, nil
+		}
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+		return number, nil
+	default:
+		return nil, fmt.Errorf("%v is not a number", value)
+	}
+}
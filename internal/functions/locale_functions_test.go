@@ -0,0 +1,142 @@
+package functions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestLocaleFormatter(t *testing.T) {
+	formatter := func(tag string) *LocaleFormatter {
+		return NewLocaleFormatter(language.MustParse(tag))
+	}
+
+	t.Run("Number", func(t *testing.T) {
+		tests := []struct {
+			locale   string
+			decimals int
+			value    any
+			want     string
+		}{
+			{"en-US", 2, 1234.5, "1,234.50"},
+			{"de-DE", 2, 1234.5, "1.234,50"},
+			{"fr-FR", 0, 1234567, "1\u00a0234\u00a0567"},
+			{"de-CH", 2, "1234.5", "1’234.50"},
+			{"en-US", 0, int64(-42), "-42"},
+			{"de-DE", 1, "1234.5&#xA;", "1.234,5"},
+		}
+		for _, tt := range tests {
+			result, err := formatter(tt.locale).Number(tt.decimals, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result, tt.locale)
+		}
+
+		_, err := formatter("en").Number(2, "lots")
+		assert.ErrorContains(t, err, `localNumber: "lots" is not a number`)
+	})
+
+	t.Run("Percent", func(t *testing.T) {
+		result, err := formatter("en-US").Percent(1, 0.125)
+		require.NoError(t, err)
+		assert.Equal(t, "12.5%", result)
+
+		result, err = formatter("de-DE").Percent(0, 0.25)
+		require.NoError(t, err)
+		assert.Equal(t, "25\u00a0%", result)
+	})
+
+	t.Run("Currency", func(t *testing.T) {
+		tests := []struct {
+			locale string
+			code   string
+			value  any
+			want   string
+		}{
+			{"en-US", "USD", 1234.56, "$1,234.56"},
+			{"en-US", "EUR", 1234.56, "€1,234.56"},
+			{"de-DE", "EUR", 1234.56, "1.234,56\u00a0€"},
+			{"fr-FR", "EUR", 1234.5, "1\u00a0234,50\u00a0€"},
+			{"de-CH", "CHF", 1234.5, "CHF\u00a01’234.50"},
+			{"nl-NL", "EUR", -5, "-€\u00a05,00"},
+			{"en-US", "USD", "-1234.5", "-$1,234.50"},
+			{"en-US", "JPY", 1234, "¥1,234"},
+			{"de-DE", "", 10, "10,00\u00a0€"},
+			{"ar-AE", "AED", 1234.5, "1,234.50\u00a0د.إ.\u200f"},
+		}
+		for _, tt := range tests {
+			result, err := formatter(tt.locale).Currency(tt.code, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result, tt.locale)
+		}
+
+		_, err := formatter("en").Currency("XYZW", 1)
+		assert.ErrorContains(t, err, `"XYZW" is not a currency code`)
+	})
+
+	t.Run("FormatNumber and FormatCurrency", func(t *testing.T) {
+		result, err := formatter("de-DE").FormatNumber(2, 1234.5)
+		require.NoError(t, err)
+		assert.Equal(t, "1.234,50", result)
+
+		tests := []struct {
+			locale string
+			symbol string
+			value  any
+			want   string
+		}{
+			{"en-US", "$", 1234.5, "$1,234.50"},
+			{"de-DE", "€", -1234.5, "-1.234,50\u00a0€"},
+			{"fr-FR", "&amp;", 3, "3,00\u00a0&amp;"},
+			{"pl-PL", "zł", 1234.5, "zł\u00a01\u00a0234,50"},
+		}
+		for _, tt := range tests {
+			result, err := formatter(tt.locale).FormatCurrency(tt.symbol, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result, tt.locale)
+		}
+
+		_, err = formatter("de-DE").FormatCurrency("€", "abc")
+		assert.ErrorContains(t, err, `formatCurrency: "abc" is not a number`)
+	})
+
+	t.Run("Date", func(t *testing.T) {
+		date := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+		tests := []struct {
+			locale string
+			layout string
+			want   string
+		}{
+			{"en-US", "long", "March 5, 2024"},
+			{"en-GB", "long", "5 March 2024"},
+			{"de-DE", "long", "5. März 2024"},
+			{"de-DE", "short", "05.03.24"},
+			{"fr-FR", "full", "mardi 5 mars 2024"},
+			{"es-ES", "long", "5 de marzo de 2024"},
+			{"pt-BR", "medium", "5 de mar. de 2024"},
+			{"ar-AE", "long", "5 مارس 2024"},
+			{"ar", "long", "٥ مارس ٢٠٢٤"},
+			{"fr-FR", "Mon 2 Jan 2006, 15:04", "mar. 5 mars 2024, 14:30"},
+			{"ja-JP", "2006/01/02", "2024/03/05"},
+		}
+		for _, tt := range tests {
+			result, err := formatter(tt.locale).Date(tt.layout, date)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result, tt.locale)
+		}
+
+		result, err := formatter("de").Date("long", "")
+		require.NoError(t, err)
+		assert.Empty(t, result)
+
+		_, err = formatter("de").Date("long", "yesterday")
+		assert.ErrorContains(t, err, `localDate: "yesterday" is not a date`)
+
+		for _, layout := range []string{"long", "2 January 2006"} {
+			_, err = formatter("ja-JP").Date(layout, date)
+			assert.ErrorContains(t, err, "localDate: no date formats for locale ja-JP", layout)
+		}
+	})
+}
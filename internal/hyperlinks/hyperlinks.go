@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -81,6 +82,7 @@ type Relationship struct {
 const (
 	RelationshipsNamespace = "http://schemas.openxmlformats.org/package/2006/relationships"
 	HyperlinkType          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	ImageType              = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
 )
 
 // GetDocumentRels reads the document.xml.rels file from a zip
@@ -130,6 +132,20 @@ func (r *Relationships) AddHyperlinks(links map[string]string) {
 	}
 }
 
+// Add adds a relationship to an internal target under an unused id and returns the id
+func (r *Relationships) Add(relType, target string) string {
+	used := make(map[string]bool, len(r.Relationships))
+	for _, rel := range r.Relationships {
+		used[rel.ID] = true
+	}
+	id := ""
+	for n := len(r.Relationships) + 1; id == "" || used[id]; n++ {
+		id = "rId" + strconv.Itoa(n)
+	}
+	r.Relationships = append(r.Relationships, Relationship{ID: id, Type: relType, Target: target})
+	return id
+}
+
 // ToXML returns the XML representation of the relationships as a string
 func (r *Relationships) ToXML() (string, error) {
 	output, err := xml.MarshalIndent(r, "", "  ")
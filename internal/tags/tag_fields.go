@@ -0,0 +1,129 @@
+package tags
+
+import (
+	"strings"
+	"text/template/parse"
+)
+
+// FieldReference is a field of the data a template uses.
+type FieldReference struct {
+	Path     string          // Field names joined by dots, e.g. "Customer.Name"
+	Tag      string          // First tag using the field
+	Location *SourceLocation // Position of the tag within the part, nil in partials
+}
+
+// Fields returns the fields of the data the template uses, in the order they
+// are first used. Fields used within {{range}} and {{with}} blocks are fields
+// of the values the blocks iterate over or select and aren't included, except
+// those reached from the data with $, such as $.Company.
+func (t *Template) Fields() []FieldReference {
+	if t.tmpl == nil {
+		return nil
+	}
+	c := &fieldCollector{template: t, seen: make(map[string]bool), walked: make(map[string]bool)}
+	c.walkTemplate(t.tmpl.Name())
+	return c.fields
+}
+
+// fieldCollector walks the parsed templates executed with the data as dot,
+// collecting the fields they use
+type fieldCollector struct {
+	template *Template
+	fields   []FieldReference
+	seen     map[string]bool // paths already collected
+	walked   map[string]bool // templates already walked
+}
+
+func (c *fieldCollector) walkTemplate(name string) {
+	if c.walked[name] {
+		return
+	}
+	c.walked[name] = true
+	tmpl := c.template.tmpl.Lookup(name)
+	if tmpl == nil || tmpl.Tree == nil {
+		return
+	}
+	c.walk(tmpl.Tree, tmpl.Tree.Root, true)
+}
+
+// walk collects the fields under the node. Dot is the data when top is true.
+func (c *fieldCollector) walk(tree *parse.Tree, node parse.Node, top bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(tree, child, top)
+		}
+	case *parse.ActionNode:
+		c.walk(tree, n.Pipe, top)
+	case *parse.IfNode:
+		c.walk(tree, n.Pipe, top)
+		c.walk(tree, n.List, top)
+		c.walk(tree, n.ElseList, top)
+	case *parse.RangeNode:
+		c.walk(tree, n.Pipe, top)
+		c.walk(tree, n.List, false)
+		c.walk(tree, n.ElseList, top)
+	case *parse.WithNode:
+		c.walk(tree, n.Pipe, top)
+		c.walk(tree, n.List, false)
+		c.walk(tree, n.ElseList, top)
+	case *parse.TemplateNode:
+		c.walk(tree, n.Pipe, top)
+		if top && isDot(n.Pipe) {
+			c.walkTemplate(n.Name)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			c.walk(tree, cmd, top)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			c.walk(tree, arg, top)
+		}
+	case *parse.ChainNode:
+		c.walk(tree, n.Node, top)
+	case *parse.FieldNode:
+		if top {
+			c.add(tree, n.Pos, n.Ident)
+		}
+	case *parse.VariableNode:
+		// $ is the data in every template walked, which are only those executed with the data
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			c.add(tree, n.Pos, n.Ident[1:])
+		}
+	}
+}
+
+func (c *fieldCollector) add(tree *parse.Tree, pos parse.Pos, names []string) {
+	path := strings.Join(names, ".")
+	if c.seen[path] {
+		return
+	}
+	c.seen[path] = true
+
+	field := FieldReference{Path: path}
+	offset := int(pos)
+	if tree.ParseName == c.template.tmpl.Name() {
+		field.Tag = enclosingTag(c.template.source, offset)
+		location := LocateOffset(c.template.source, offset)
+		field.Location = &location
+	} else if partial, ok := c.template.partials[tree.ParseName]; ok && offset <= len(partial) {
+		field.Tag = enclosingTag(partial, offset)
+	}
+	c.fields = append(c.fields, field)
+}
+
+// isDot reports whether a pipeline is just dot
+func isDot(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	_, ok := pipe.Cmds[0].Args[0].(*parse.DotNode)
+	return ok
+}
@@ -0,0 +1,59 @@
+package tags
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFields(t *testing.T) {
+	funcMap := template.FuncMap{"upper": func(s string) string { return s }}
+
+	t.Run("Should list the fields of the data in order of first use", func(t *testing.T) {
+		text := `{{.Customer.Name}} {{upper .Title}} {{if .Paid}}{{.Customer.Name}}{{end}}` +
+			`{{range .Items}}{{.Name}} {{$.Currency}}{{end}}{{with .Address}}{{.City}}{{else}}{{.Country}}{{end}}`
+		tmpl, err := ParseText(text, funcMap, Options{})
+		require.NoError(t, err)
+
+		var paths []string
+		for _, field := range tmpl.Fields() {
+			paths = append(paths, field.Path)
+		}
+		assert.Equal(t, []string{"Customer.Name", "Title", "Paid", "Items", "Currency", "Address", "Country"}, paths)
+	})
+
+	t.Run("Should locate the first tag using a field", func(t *testing.T) {
+		xml := `<w:p><w:r><w:t>Dear {{.Name}},</w:t></w:r></w:p><w:p><w:r><w:t>{{.Total}} {{.Name}}</w:t></w:r></w:p>`
+		tmpl, err := ParseXml(xml, funcMap, Options{MissingKey: MissingKeyError})
+		require.NoError(t, err)
+
+		fields := tmpl.Fields()
+		require.Len(t, fields, 2)
+		assert.Equal(t, "Total", fields[1].Path)
+		assert.Equal(t, "{{.Total}}", fields[1].Tag)
+		require.NotNil(t, fields[1].Location)
+		assert.Equal(t, 1, fields[1].Location.Paragraph)
+	})
+
+	t.Run("Should follow templates executed with the data", func(t *testing.T) {
+		opts := Options{Partials: map[string]string{
+			"address": `{{.Street}} {{.City}}`,
+			"sender":  `{{.Company}}`,
+		}}
+		tmpl, err := ParseText(`{{template "address" .Shipping}} {{template "sender" .}} {{template "sender" .}}`, funcMap, opts)
+		require.NoError(t, err)
+
+		assert.Equal(t, []FieldReference{
+			{Path: "Shipping", Tag: `{{template "address" .Shipping}}`, Location: &SourceLocation{Paragraph: -1, Table: -1}},
+			{Path: "Company", Tag: "{{.Company}}"},
+		}, tmpl.Fields())
+	})
+
+	t.Run("Should have no fields without tags", func(t *testing.T) {
+		tmpl, err := ParseText("plain text", funcMap, Options{})
+		require.NoError(t, err)
+		assert.Empty(t, tmpl.Fields())
+	})
+}
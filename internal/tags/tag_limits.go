@@ -0,0 +1,261 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Limits bound the resources templates may use, for rendering templates that
+// aren't trusted. Zero values don't limit.
+type Limits struct {
+	// Timeout is the longest rendering may take.
+	Timeout time.Duration
+	// MaxOutputBytes is the most bytes a part may render to.
+	MaxOutputBytes int64
+	// MaxIterations is the most iterations the {{range}} loops of a part may
+	// run, counted over every loop.
+	MaxIterations int64
+	// AllowedFuncs lists the registered functions templates may call. Every
+	// function is allowed when nil. The built-in functions of Go templates,
+	// such as len, index and printf, are always allowed.
+	AllowedFuncs []string
+}
+
+// LimitKind is the limit a template exceeded.
+type LimitKind int
+
+const (
+	LimitTimeout LimitKind = iota
+	LimitOutput
+	LimitIterations
+	LimitFunc
+)
+
+// LimitError is returned when a template exceeds a limit.
+type LimitError struct {
+	Kind  LimitKind
+	Limit int64  // the limit exceeded: nanoseconds, bytes or iterations
+	Func  string // the function that isn't allowed, for LimitFunc
+}
+
+func (e *LimitError) Error() string {
+	switch e.Kind {
+	case LimitTimeout:
+		return fmt.Sprintf("template execution exceeded the time limit of %v", time.Duration(e.Limit))
+	case LimitOutput:
+		return fmt.Sprintf("template output exceeded the limit of %d bytes", e.Limit)
+	case LimitIterations:
+		return fmt.Sprintf("template loops exceeded the limit of %d iterations", e.Limit)
+	}
+	return fmt.Sprintf("function %q is not allowed", e.Func)
+}
+
+// Context returns a context that is canceled with a *LimitError as its cause
+// once the timeout has passed.
+func (l Limits) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, l.Timeout, &LimitError{Kind: LimitTimeout, Limit: int64(l.Timeout)})
+}
+
+const (
+	// checkFuncName is the internal function called at the start of every
+	// template so recursion stops once the context is done
+	checkFuncName = "__docxtplCheck"
+	// iterateFuncName is the internal function called at the start of every
+	// loop iteration to count iterations and stop once the context is done
+	iterateFuncName = "__docxtplIterate"
+)
+
+// instrumentLimits inserts calls to the limit functions at the start of every
+// template and loop body of the parsed template
+func instrumentLimits(tmpl *template.Template) {
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		root := t.Tree.Root
+		root.Nodes = slices.Insert(root.Nodes, 0, parse.Node(limitAction(t.Tree, root.Pos, checkFuncName)))
+		instrumentLoops(t.Tree, root)
+	}
+	var l limiter
+	l.bind(tmpl)
+}
+
+func instrumentLoops(tree *parse.Tree, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.IfNode:
+			instrumentLoops(tree, n.List)
+			instrumentLoops(tree, n.ElseList)
+		case *parse.RangeNode:
+			instrumentLoops(tree, n.List)
+			instrumentLoops(tree, n.ElseList)
+			if n.List != nil {
+				n.List.Nodes = slices.Insert(n.List.Nodes, 0, parse.Node(limitAction(tree, n.Pos, iterateFuncName)))
+			}
+		case *parse.WithNode:
+			instrumentLoops(tree, n.List)
+			instrumentLoops(tree, n.ElseList)
+		case *parse.ListNode:
+			instrumentLoops(tree, n)
+		}
+	}
+}
+
+// limitAction returns an action calling a limit function, which outputs
+// nothing, positioned at the tag it checks
+func limitAction(tree *parse.Tree, pos parse.Pos, name string) *parse.ActionNode {
+	return &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      pos,
+		Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe,
+			Pos:      pos,
+			Cmds: []*parse.CommandNode{{
+				NodeType: parse.NodeCommand,
+				Pos:      pos,
+				Args:     []parse.Node{parse.NewIdentifier(name).SetTree(tree).SetPos(pos)},
+			}},
+		},
+	}
+}
+
+// checkFuncs returns an error locating the first call to a registered function
+// that the limits don't allow
+func checkFuncs(tmpl *template.Template, source string, funcMap template.FuncMap, limits Limits) error {
+	if limits.AllowedFuncs == nil {
+		return nil
+	}
+	allowed := func(name string) bool {
+		_, registered := funcMap[name]
+		return !registered || slices.Contains(limits.AllowedFuncs, name)
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		ident := disallowedFunc(t.Tree.Root, allowed)
+		if ident == nil {
+			continue
+		}
+		limitErr := &LimitError{Kind: LimitFunc, Func: ident.Ident}
+		located := &LocatedError{Kind: ParseError, Message: limitErr.Error(), Err: limitErr}
+		if t.Tree.ParseName == tmpl.Name() {
+			offset := int(ident.Pos)
+			located.Tag = enclosingTag(source, offset)
+			location := LocateOffset(source, offset)
+			located.Location = &location
+		}
+		return located
+	}
+	return nil
+}
+
+// disallowedFunc returns the first identifier under the node naming a
+// function that isn't allowed
+func disallowedFunc(node parse.Node, allowed func(string) bool) *parse.IdentifierNode {
+	var children []parse.Node
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		children = n.Nodes
+	case *parse.ActionNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.IfNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.RangeNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.WithNode:
+		children = []parse.Node{n.Pipe, n.List, n.ElseList}
+	case *parse.TemplateNode:
+		children = []parse.Node{n.Pipe}
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			children = append(children, cmd)
+		}
+	case *parse.CommandNode:
+		children = n.Args
+	case *parse.ChainNode:
+		children = []parse.Node{n.Node}
+	case *parse.IdentifierNode:
+		if !allowed(n.Ident) {
+			return n
+		}
+	}
+	for _, child := range children {
+		if ident := disallowedFunc(child, allowed); ident != nil {
+			return ident
+		}
+	}
+	return nil
+}
+
+// limiter enforces the limits on an execution of a template: it is called at
+// the start of templates and loop iterations, and the output is written
+// through it.
+type limiter struct {
+	ctx        context.Context
+	limits     Limits
+	w          io.Writer
+	iterations int64
+	written    int64
+}
+
+// bind makes the template call the limiter's functions
+func (l *limiter) bind(tmpl *template.Template) {
+	tmpl.Funcs(template.FuncMap{checkFuncName: l.check, iterateFuncName: l.iterate})
+}
+
+func (l *limiter) check() (string, error) {
+	if l.ctx != nil && l.ctx.Err() != nil {
+		return "", context.Cause(l.ctx)
+	}
+	return "", nil
+}
+
+func (l *limiter) iterate() (string, error) {
+	l.iterations++
+	if l.limits.MaxIterations > 0 && l.iterations > l.limits.MaxIterations {
+		return "", &LimitError{Kind: LimitIterations, Limit: l.limits.MaxIterations}
+	}
+	return l.check()
+}
+
+func (l *limiter) Write(p []byte) (int, error) {
+	if _, err := l.check(); err != nil {
+		return 0, err
+	}
+	l.written += int64(len(p))
+	if l.limits.MaxOutputBytes > 0 && l.written > l.limits.MaxOutputBytes {
+		return 0, &LimitError{Kind: LimitOutput, Limit: l.limits.MaxOutputBytes}
+	}
+	return l.w.Write(p)
+}
+
+// limitMessage returns the message of an error returned by a limit function,
+// without text/template's mention of the internal function
+func limitMessage(message string) string {
+	for _, name := range []string{checkFuncName, iterateFuncName} {
+		if rest, ok := strings.CutPrefix(message, "error calling "+name+": "); ok {
+			return rest
+		}
+	}
+	return message
+}
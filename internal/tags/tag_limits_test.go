@@ -0,0 +1,92 @@
+package tags
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	funcMap := template.FuncMap{
+		"upper": strings.ToUpper,
+		"shell": func(string) string { return "" },
+	}
+	items := make([]int, 20)
+
+	t.Run("Should count the iterations of every loop", func(t *testing.T) {
+		text := `{{range .Items}}{{range .}}x{{end}}{{end}}`
+		opts := Options{Limits: Limits{MaxIterations: 10}}
+		data := map[string]any{"Items": [][]int{{1, 2, 3}, {4, 5}}}
+		output, err := ReplaceTagsInTextWithOptions(text, data, funcMap, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "xxxxx", output)
+
+		_, err = ReplaceTagsInTextWithOptions(`{{range .Items}}{{.}}{{end}}`, map[string]any{"Items": items}, funcMap, opts)
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, LimitIterations, limitErr.Kind)
+		var located *LocatedError
+		require.ErrorAs(t, err, &located)
+		assert.Equal(t, "template loops exceeded the limit of 10 iterations", located.Message)
+		assert.Equal(t, "{{range .Items}}", located.Tag)
+	})
+
+	t.Run("Should limit the output of a part", func(t *testing.T) {
+		opts := Options{Limits: Limits{MaxOutputBytes: 10}}
+		output, err := ReplaceTagsInTextWithOptions(`{{.Name}}`, map[string]any{"Name": "Ada"}, funcMap, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada", output)
+
+		_, err = ReplaceTagsInTextWithOptions(`{{range .Items}}{{.}}{{end}}`, map[string]any{"Items": items}, funcMap, opts)
+		assert.Equal(t, &LimitError{Kind: LimitOutput, Limit: 10}, err)
+	})
+
+	t.Run("Should only allow the listed functions", func(t *testing.T) {
+		opts := Options{Limits: Limits{AllowedFuncs: []string{"upper"}}}
+		output, err := ReplaceTagsInTextWithOptions(`{{upper .Name}} {{len .Name}}`, map[string]any{"Name": "Ada"}, funcMap, opts)
+		require.NoError(t, err)
+		assert.Equal(t, "ADA 3", output)
+
+		xml := `<w:p><w:r><w:t>{{if .Run}}{{shell .Command}}{{end}}</w:t></w:r></w:p>`
+		_, err = ReplaceTagsInXmlWithOptions(xml, map[string]any{}, funcMap, opts)
+		var located *LocatedError
+		require.ErrorAs(t, err, &located)
+		assert.Equal(t, ParseError, located.Kind)
+		assert.Equal(t, "{{shell .Command}}", located.Tag)
+		require.NotNil(t, located.Location)
+		assert.Equal(t, 0, located.Location.Paragraph)
+		assert.Equal(t, &LimitError{Kind: LimitFunc, Func: "shell"}, located.Err)
+
+		opts.Partials = map[string]string{"cmd": `{{shell "ls"}}`}
+		_, err = ReplaceTagsInTextWithOptions(`{{template "cmd"}}`, map[string]any{}, funcMap, opts)
+		assert.EqualError(t, err, `error parsing template: function "shell" is not allowed`)
+	})
+
+	t.Run("Should stop once the context is done", func(t *testing.T) {
+		tmpl, err := ParseText(`{{range .Items}}{{.}}{{end}}`, funcMap, Options{})
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = tmpl.ExecuteContext(ctx, map[string]any{"Items": items}, funcMap)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		output, err := tmpl.Execute(map[string]any{"Items": []int{1, 2}}, funcMap)
+		require.NoError(t, err)
+		assert.Equal(t, "12", output)
+	})
+
+	t.Run("Should stop after the timeout", func(t *testing.T) {
+		text := `{{range 1000000000}}{{end}}`
+		opts := Options{Limits: Limits{Timeout: 20 * time.Millisecond}}
+		_, err := ReplaceTagsInTextWithOptions(text, map[string]any{}, funcMap, opts)
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, LimitTimeout, limitErr.Kind)
+		assert.EqualError(t, err, "template execution exceeded the time limit of 20ms")
+	})
+}
@@ -0,0 +1,260 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// SourceLocation describes where a template tag sits within an XML part.
+type SourceLocation struct {
+	Paragraph int    // Zero-based paragraph index within the part, -1 if not in a paragraph
+	Table     int    // Zero-based index of the innermost enclosing table, -1 if not in a table
+	Row       int    // Zero-based row index within that table
+	Cell      int    // Zero-based cell index within that row
+	Snippet   string // Visible text of the surrounding paragraph
+}
+
+// String returns a short description such as "paragraph 3" or "table 0, row 2, cell 1, paragraph 7".
+func (l SourceLocation) String() string {
+	var parts []string
+	if l.Table >= 0 {
+		parts = append(parts, fmt.Sprintf("table %d, row %d, cell %d", l.Table, l.Row, l.Cell))
+	}
+	if l.Paragraph >= 0 {
+		parts = append(parts, fmt.Sprintf("paragraph %d", l.Paragraph))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// structureTagRegex matches the opening and closing tags that define document structure
+var structureTagRegex = regexp.MustCompile(`<(/?)w:(p|tbl|tr|tc)[ >]`)
+
+// xmlTagRegex matches any XML tag
+var xmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// maxSnippetLength limits the amount of surrounding text included in a location
+const maxSnippetLength = 80
+
+// LocateOffset works out the paragraph and table cell containing a byte offset in XML.
+func LocateOffset(source string, offset int) SourceLocation {
+	if offset > len(source) {
+		offset = len(source)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	type tableState struct {
+		index, row, cell int
+	}
+	location := SourceLocation{Paragraph: -1, Table: -1}
+	var tables []tableState
+	tableCount := 0
+	paragraphCount := 0
+	paragraphStart := -1
+
+	for _, match := range structureTagRegex.FindAllStringSubmatchIndex(source[:offset], -1) {
+		closing := match[3] > match[2]
+		element := source[match[4]:match[5]]
+		switch element {
+		case "p":
+			if closing {
+				paragraphStart = -1
+			} else {
+				paragraphStart = match[0]
+				paragraphCount++
+			}
+		case "tbl":
+			if closing {
+				if len(tables) > 0 {
+					tables = tables[:len(tables)-1]
+				}
+			} else {
+				tables = append(tables, tableState{index: tableCount, row: -1, cell: -1})
+				tableCount++
+			}
+		case "tr":
+			if !closing && len(tables) > 0 {
+				tables[len(tables)-1].row++
+				tables[len(tables)-1].cell = -1
+			}
+		case "tc":
+			if !closing && len(tables) > 0 {
+				tables[len(tables)-1].cell++
+			}
+		}
+	}
+
+	if paragraphStart >= 0 {
+		location.Paragraph = paragraphCount - 1
+		paragraphEnd := strings.Index(source[offset:], "</w:p>")
+		if paragraphEnd < 0 {
+			paragraphEnd = len(source)
+		} else {
+			paragraphEnd += offset
+		}
+		location.Snippet = visibleText(source[paragraphStart:paragraphEnd])
+	}
+	if len(tables) > 0 {
+		innermost := tables[len(tables)-1]
+		location.Table = innermost.index
+		location.Row = max(innermost.row, 0)
+		location.Cell = max(innermost.cell, 0)
+	}
+
+	return location
+}
+
+// visibleText strips XML markup and shortens the text for use in error messages
+func visibleText(xmlString string) string {
+	text := html.UnescapeString(xmlTagRegex.ReplaceAllString(xmlString, ""))
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxSnippetLength {
+		text = string(runes[:maxSnippetLength-3]) + "..."
+	}
+	return text
+}
+
+// ErrorKind distinguishes template parse errors from execution errors.
+type ErrorKind int
+
+const (
+	ParseError ErrorKind = iota
+	ExecError
+)
+
+// LocatedError is a text/template error mapped back to the tag and position in the XML part it came from.
+type LocatedError struct {
+	Kind     ErrorKind
+	Message  string          // Error message without text/template's position prefix
+	Tag      string          // Offending tag, empty if it couldn't be determined
+	Location *SourceLocation // Position in the part, nil if it couldn't be determined
+	Err      error           // Original text/template error
+}
+
+func (e *LocatedError) Error() string {
+	if e.Kind == ParseError {
+		return fmt.Sprintf("error parsing template: %v", e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *LocatedError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// execErrorRegex matches text/template execution errors, e.g.
+	// template: :1:57: executing "" at <.A.B>: nil pointer evaluating interface {}.B
+	execErrorRegex = regexp.MustCompile(`(?s)^template: ([^:]*):(\d+):(\d+): executing "[^"]*" at <.*?>: (.*)$`)
+
+	// parseErrorRegex matches text/template parse errors, e.g. template: :1: function "foo" not defined
+	parseErrorRegex = regexp.MustCompile(`(?s)^template: [^:]*:(\d+):(?:\d+:)? (.*)$`)
+)
+
+// locateExecError maps an execution error onto the tag that caused it. Errors
+// in partials are mapped onto the tag in the partial, with no location.
+func locateExecError(err error, source string, partials map[string]string) error {
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) {
+		return err
+	}
+
+	located := &LocatedError{Kind: ExecError, Message: err.Error(), Err: err}
+	match := execErrorRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return located
+	}
+	located.Message = limitMessage(match[4])
+
+	line, _ := strconv.Atoi(match[2])
+	column, _ := strconv.Atoi(match[3])
+	if partial, ok := partials[match[1]]; ok && match[1] != "" {
+		if offset := lineOffset(partial, line) + column; offset >= 0 && offset <= len(partial) {
+			located.Tag = enclosingTag(partial, offset)
+		}
+		return located
+	}
+	offset := lineOffset(source, line) + column
+	if offset < 0 || offset > len(source) {
+		return located
+	}
+
+	located.Tag = enclosingTag(source, offset)
+	location := LocateOffset(source, offset)
+	located.Location = &location
+	return located
+}
+
+// locateParseError maps a parse error onto the tag that caused it. text/template
+// only reports line numbers for parse errors, so the tags are re-parsed one per
+// line to find out which one is at fault.
+func locateParseError(err error, source string, newTmpl func() *template.Template) error {
+	located := &LocatedError{Kind: ParseError, Message: err.Error(), Err: err}
+	if match := parseErrorRegex.FindStringSubmatch(err.Error()); match != nil {
+		located.Message = match[2]
+	}
+	if strings.Contains(located.Message, "unexpected EOF") {
+		// The error is at the end of the template, which says nothing about the tag at fault
+		return located
+	}
+
+	tagIndexes := tagRegex.FindAllStringIndex(source, -1)
+	if len(tagIndexes) == 0 {
+		return located
+	}
+	lines := make([]string, len(tagIndexes))
+	for i, idx := range tagIndexes {
+		lines[i] = strings.ReplaceAll(source[idx[0]:idx[1]], "\n", " ")
+	}
+
+	_, diagErr := newTmpl().Parse(strings.Join(lines, "\n"))
+	if diagErr == nil {
+		return located
+	}
+	match := parseErrorRegex.FindStringSubmatch(diagErr.Error())
+	if match == nil {
+		return located
+	}
+	line, _ := strconv.Atoi(match[1])
+	if line < 1 || line > len(tagIndexes) {
+		return located
+	}
+
+	offset := tagIndexes[line-1][0]
+	located.Tag = source[offset:tagIndexes[line-1][1]]
+	location := LocateOffset(source, offset)
+	located.Location = &location
+	return located
+}
+
+// lineOffset returns the byte offset of the start of a 1-based line
+func lineOffset(source string, line int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(source[offset:], '\n')
+		if next < 0 {
+			return -1
+		}
+		offset += next + 1
+	}
+	return offset
+}
+
+// enclosingTag returns the template tag surrounding the offset
+func enclosingTag(source string, offset int) string {
+	start := strings.LastIndex(source[:offset], "{{")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(source[start:], "}}")
+	if end < 0 {
+		return ""
+	}
+	return source[start : start+end+2]
+}
@@ -0,0 +1,73 @@
+package tags
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocateOffset(t *testing.T) {
+	xmlString := `<w:body><w:p><w:r><w:t>Intro</w:t></w:r></w:p>` +
+		`<w:tbl><w:tblPr></w:tblPr><w:tr><w:tc><w:p><w:r><w:t>A1</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:p><w:r><w:t>B1</w:t></w:r></w:p></w:tc><w:tc><w:p><w:pPr></w:pPr><w:r><w:t>Total: {{.Total}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+		`<w:p><w:r><w:t>After &amp; done</w:t></w:r></w:p></w:body>`
+
+	t.Run("Inside a table cell", func(t *testing.T) {
+		location := LocateOffset(xmlString, strings.Index(xmlString, "{{.Total}}"))
+		assert.Equal(t, SourceLocation{Paragraph: 3, Table: 0, Row: 1, Cell: 1, Snippet: "Total: {{.Total}}"}, location)
+		assert.Equal(t, "table 0, row 1, cell 1, paragraph 3", location.String())
+	})
+
+	t.Run("After a table", func(t *testing.T) {
+		location := LocateOffset(xmlString, strings.Index(xmlString, "After"))
+		assert.Equal(t, SourceLocation{Paragraph: 4, Table: -1, Snippet: "After & done"}, location)
+	})
+
+	t.Run("Outside any paragraph", func(t *testing.T) {
+		location := LocateOffset(xmlString, 0)
+		assert.Equal(t, SourceLocation{Paragraph: -1, Table: -1}, location)
+		assert.Equal(t, "", location.String())
+	})
+}
+
+func TestReplaceTagsInXmlErrorLocations(t *testing.T) {
+	xmlString := `<w:body><w:p><w:r><w:t>Hello {{.Name}}</w:t></w:r></w:p><w:p><w:r><w:t>Due {{%s}}</w:t></w:r></w:p></w:body>`
+
+	t.Run("Parse errors point at the offending tag", func(t *testing.T) {
+		_, err := ReplaceTagsInXml(strings.Replace(xmlString, "%s", "formatDate .Due", 1), map[string]any{}, template.FuncMap{})
+
+		var located *LocatedError
+		require.True(t, errors.As(err, &located))
+		assert.Equal(t, ParseError, located.Kind)
+		assert.Equal(t, `function "formatDate" not defined`, located.Message)
+		assert.Equal(t, "{{formatDate .Due}}", located.Tag)
+		require.NotNil(t, located.Location)
+		assert.Equal(t, 1, located.Location.Paragraph)
+		assert.Equal(t, "Due {{formatDate .Due}}", located.Location.Snippet)
+		assert.Contains(t, err.Error(), "error parsing template")
+	})
+
+	t.Run("Execution errors point at the offending tag", func(t *testing.T) {
+		_, err := ReplaceTagsInXml(strings.Replace(xmlString, "%s", ".Invoice.Due", 1), map[string]any{"Name": "Tom"}, template.FuncMap{})
+
+		var located *LocatedError
+		require.True(t, errors.As(err, &located))
+		assert.Equal(t, ExecError, located.Kind)
+		assert.Equal(t, "{{.Invoice.Due}}", located.Tag)
+		assert.Contains(t, located.Message, "nil pointer evaluating")
+		require.NotNil(t, located.Location)
+		assert.Equal(t, 1, located.Location.Paragraph)
+	})
+
+	t.Run("Unexpected EOF has no tag location", func(t *testing.T) {
+		_, err := ReplaceTagsInXml(strings.Replace(xmlString, "%s", "if .Due", 1), map[string]any{}, template.FuncMap{})
+
+		var located *LocatedError
+		require.True(t, errors.As(err, &located))
+		assert.Nil(t, located.Location)
+	})
+}
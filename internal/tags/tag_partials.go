@@ -0,0 +1,33 @@
+package tags
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"text/template"
+	"text/template/parse"
+)
+
+// CheckPartialSyntax reports syntax errors in the text of a partial. Functions
+// aren't checked, as they may be registered after the partial.
+func CheckPartialSyntax(name, text string) error {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	_, err := tree.Parse(text, "", "", map[string]*parse.Tree{})
+	return err
+}
+
+// addPartials parses the partials into the template so every part can call
+// them with {{template "name" .}}. Templates the part defines itself win over
+// partials of the same name.
+func addPartials(tmpl *template.Template, partials map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(partials)) {
+		if tmpl.Lookup(name) != nil {
+			continue
+		}
+		if _, err := tmpl.New(name).Parse(partials[name]); err != nil {
+			return fmt.Errorf("error parsing partial %q: %w", name, err)
+		}
+	}
+	return nil
+}
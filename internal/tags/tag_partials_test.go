@@ -0,0 +1,61 @@
+package tags
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartials(t *testing.T) {
+	partials := map[string]string{
+		"greeting": `Hello {{upper .Name}}`,
+		"footer":   `{{.Missing}}`,
+	}
+	funcMap := template.FuncMap{"upper": func(s string) string { return s + "!" }}
+
+	t.Run("Parts can use partials", func(t *testing.T) {
+		output, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{template "greeting" .}}</w:t></w:p>`, map[string]any{"Name": "Tom"}, funcMap, Options{Partials: partials})
+		require.NoError(t, err)
+		assert.Equal(t, `<w:p><w:t>Hello Tom!</w:t></w:p>`, output)
+
+		output, err = ReplaceTagsInTextWithOptions(`{{template "greeting" .}}`, map[string]any{"Name": "Ann"}, funcMap, Options{Partials: partials})
+		require.NoError(t, err)
+		assert.Equal(t, `Hello Ann!`, output)
+	})
+
+	t.Run("Definitions in the part win over partials", func(t *testing.T) {
+		output, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{define "greeting"}}Hi{{end}}{{template "greeting" .}}</w:t></w:p>`, map[string]any{}, funcMap, Options{Partials: partials})
+		require.NoError(t, err)
+		assert.Equal(t, `<w:p><w:t>Hi</w:t></w:p>`, output)
+	})
+
+	t.Run("Unresolved placeholders in partials have no location", func(t *testing.T) {
+		_, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{template "footer" .}}</w:t></w:p>`, map[string]any{}, funcMap, Options{MissingKey: MissingKeyError, Partials: partials})
+
+		var unresolvedErr *UnresolvedTagsError
+		require.True(t, errors.As(err, &unresolvedErr))
+		assert.Equal(t, []UnresolvedTag{{Tag: "{{.Missing}}", Location: SourceLocation{Paragraph: -1, Table: -1}}}, unresolvedErr.Tags)
+	})
+
+	t.Run("Execution errors in partials name the tag", func(t *testing.T) {
+		_, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{template "greeting" .}}</w:t></w:p>`, map[string]any{"Name": 1}, funcMap, Options{Partials: partials})
+
+		var located *LocatedError
+		require.True(t, errors.As(err, &located))
+		assert.Equal(t, "{{upper .Name}}", located.Tag)
+		assert.Nil(t, located.Location)
+	})
+
+	t.Run("Partials using unknown functions fail", func(t *testing.T) {
+		_, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{.Name}}</w:t></w:p>`, map[string]any{}, template.FuncMap{}, Options{Partials: partials})
+		assert.ErrorContains(t, err, `partial "greeting"`)
+	})
+}
+
+func TestCheckPartialSyntax(t *testing.T) {
+	assert.NoError(t, CheckPartialSyntax("address", `{{unknownFunc .Street}}`))
+	assert.Error(t, CheckPartialSyntax("address", `{{if .Street}}`))
+}
@@ -2,58 +2,150 @@ package tags
 
 import (
 	"bytes"
-	"fmt"
+	"context"
+	"errors"
 	"text/template"
 
 	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
 )
 
-// Data should already be processed and have been XML escaped (aside from embedded objects like images) before being passed into this function
-func ReplaceTagsInXml(xmlString string, data map[string]any, funcMap template.FuncMap) (string, error) {
+// Template is the XML or plain text of a part parsed for tag replacement. It
+// can be executed many times, concurrently, with different data and functions.
+type Template struct {
+	tmpl     *template.Template // nil for text without tags
+	source   string             // prepared XML or text, for locating errors
+	partials map[string]string
+	tracker  *resolutionTracker
+	limits   Limits
+	text     bool // plain text, whose rich text is stripped after execution
+}
+
+// ParseXml prepares and parses XML for tag replacement. The functions only
+// need the names of the functions the template is executed with.
+func ParseXml(xmlString string, funcMap template.FuncMap, opts Options) (*Template, error) {
 	// Prepare the XML for tag replacement
 	preparedXmlString, err := xmlutils.PrepareXmlForTagReplacement(xmlString)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return parseTemplate(preparedXmlString, funcMap, opts)
+}
+
+// ParseText parses plain text, such as watermarks and document properties,
+// for tag replacement.
+func ParseText(text string, funcMap template.FuncMap, opts Options) (*Template, error) {
+	// Check if text contains any template syntax
+	if !textContainsTags(text) {
+		return &Template{source: text, text: true}, nil
+	}
+	t, err := parseTemplate(text, funcMap, opts)
+	if err != nil {
+		return nil, err
 	}
+	t.text = true
+	return t, nil
+}
 
-	// Use missingkey=zero to output empty strings for missing/nil fields instead of "<no value>"
-	// which would break XML parsing (unescaped < and > characters)
-	tmpl, err := template.New("").Option("missingkey=zero").Funcs(funcMap).Parse(preparedXmlString)
+// parseTemplate parses prepared XML or text with the partials and instruments
+// it to track unresolved placeholders and enforce the limits
+func parseTemplate(source string, funcMap template.FuncMap, opts Options) (*Template, error) {
+	tmpl, err := newTemplate(funcMap, opts).Parse(source)
 	if err != nil {
-		return "", fmt.Errorf("error parsing template: %v", err)
+		return nil, locateParseError(err, source, func() *template.Template {
+			return newTemplate(funcMap, opts)
+		})
+	}
+	if err := addPartials(tmpl, opts.Partials); err != nil {
+		return nil, err
 	}
+	if err := checkFuncs(tmpl, source, funcMap, opts.Limits); err != nil {
+		return nil, err
+	}
+	tracker := instrumentTemplate(tmpl, source, opts)
+	instrumentLimits(tmpl)
+	return &Template{tmpl: tmpl, source: source, partials: opts.Partials, tracker: tracker, limits: opts.Limits}, nil
+}
+
+// Execute replaces the tags with the data, calling the functions, which must
+// have the names of those the template was parsed with. In MissingKeyError
+// mode an *UnresolvedTagsError is returned listing every unresolved placeholder.
+func (t *Template) Execute(data map[string]any, funcMap template.FuncMap) (string, error) {
+	return t.ExecuteContext(context.Background(), data, funcMap)
+}
+
+// ExecuteContext executes the template like Execute, stopping once the
+// context is done with its cause, such as context.Canceled or a *LimitError
+// for the timeout. A *LimitError is also returned when the output or the loops
+// of the template exceed the limits it was parsed with.
+func (t *Template) ExecuteContext(ctx context.Context, data map[string]any, funcMap template.FuncMap) (string, error) {
+	if t.tmpl == nil {
+		return t.source, nil
+	}
+
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(funcMap).Funcs(xmlutils.TemplateFuncs())
+	tracker := t.tracker.fork(tmpl)
 
 	buf := &bytes.Buffer{}
-	err = tmpl.Execute(buf, data)
+	limiter := &limiter{ctx: ctx, limits: t.limits, w: buf}
+	limiter.bind(tmpl)
+	err = tmpl.Execute(limiter, data)
 	if err != nil {
+		if ctx.Err() != nil {
+			return "", context.Cause(ctx)
+		}
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) && limitErr.Kind == LimitOutput {
+			return "", limitErr
+		}
+		return "", locateExecError(err, t.source, t.partials)
+	}
+	if err := tracker.err(); err != nil {
 		return "", err
 	}
 
+	if t.text {
+		return xmlutils.StripRichText(buf.String()), nil
+	}
 	// Fix any issues in the XML
-	outputXmlString := xmlutils.FixXmlIssuesPostTagReplacement(buf.String())
+	return xmlutils.FixXmlIssuesPostTagReplacement(buf.String()), nil
+}
 
-	return outputXmlString, err
+// Data should already be processed and have been XML escaped (aside from embedded objects like images) before being passed into this function
+func ReplaceTagsInXml(xmlString string, data map[string]any, funcMap template.FuncMap) (string, error) {
+	return ReplaceTagsInXmlWithOptions(xmlString, data, funcMap, Options{})
+}
+
+// ReplaceTagsInXmlWithOptions replaces tags like ReplaceTagsInXml, handling
+// unresolved placeholders and limits according to the options.
+// In MissingKeyError mode an *UnresolvedTagsError is returned listing every unresolved placeholder.
+func ReplaceTagsInXmlWithOptions(xmlString string, data map[string]any, funcMap template.FuncMap, opts Options) (string, error) {
+	tmpl, err := ParseXml(xmlString, funcMap, opts)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := opts.Limits.Context(context.Background())
+	defer cancel()
+	return tmpl.ExecuteContext(ctx, data, funcMap)
 }
 
 // ReplaceTagsInText processes Go template syntax in plain text (not XML).
 // This is useful for watermarks and other non-XML text content.
 func ReplaceTagsInText(text string, data map[string]any, funcMap template.FuncMap) (string, error) {
-	// Check if text contains any template syntax
-	if !textContainsTags(text) {
-		return text, nil
-	}
-
-	// Use missingkey=zero for consistency with XML processing
-	tmpl, err := template.New("").Option("missingkey=zero").Funcs(funcMap).Parse(text)
-	if err != nil {
-		return "", fmt.Errorf("error parsing template: %v", err)
-	}
+	return ReplaceTagsInTextWithOptions(text, data, funcMap, Options{})
+}
 
-	buf := &bytes.Buffer{}
-	err = tmpl.Execute(buf, data)
+// ReplaceTagsInTextWithOptions processes plain text like ReplaceTagsInText,
+// handling unresolved placeholders and limits according to the options.
+func ReplaceTagsInTextWithOptions(text string, data map[string]any, funcMap template.FuncMap, opts Options) (string, error) {
+	tmpl, err := ParseText(text, funcMap, opts)
 	if err != nil {
 		return "", err
 	}
-
-	return buf.String(), nil
+	ctx, cancel := opts.Limits.Context(context.Background())
+	defer cancel()
+	return tmpl.ExecuteContext(ctx, data, funcMap)
 }
@@ -0,0 +1,215 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// MissingKeyMode controls what happens when a placeholder resolves to a missing or nil value.
+type MissingKeyMode int
+
+const (
+	// MissingKeyZero renders unresolved placeholders as empty text.
+	MissingKeyZero MissingKeyMode = iota
+	// MissingKeyError fails rendering and reports every unresolved placeholder.
+	MissingKeyError
+	// MissingKeyKeep leaves the placeholder text in the output for human review.
+	MissingKeyKeep
+)
+
+// Options configures tag replacement.
+type Options struct {
+	MissingKey MissingKeyMode
+	// Delimiters are the delimiters of the document, used for placeholders kept
+	// in MissingKeyKeep mode. The template itself uses the standard delimiters.
+	Delimiters xmlutils.Delimiters
+	// Partials are templates available to every part by name, written in
+	// WordprocessingML with the standard delimiters.
+	Partials map[string]string
+	// Limits bound the resources templates may use.
+	Limits Limits
+}
+
+// resolveFuncName is the internal function appended to every output action
+// so unresolved values can be detected at execution time.
+const resolveFuncName = "__docxtplResolve"
+
+// UnresolvedTag describes a placeholder that resolved to a missing or nil value.
+type UnresolvedTag struct {
+	Tag      string         // Placeholder text, e.g. "{{.Name}}"
+	Location SourceLocation // Position within the part; paragraph is -1 for plain text
+}
+
+// UnresolvedTagsError is returned in MissingKeyError mode when placeholders could not be resolved.
+type UnresolvedTagsError struct {
+	Tags []UnresolvedTag
+}
+
+func (e *UnresolvedTagsError) Error() string {
+	tagNames := make([]string, len(e.Tags))
+	for i, tag := range e.Tags {
+		tagNames[i] = tag.Tag
+	}
+	return fmt.Sprintf("%d unresolved placeholder(s): %s", len(e.Tags), strings.Join(tagNames, ", "))
+}
+
+// resolutionTracker records the output actions of a template and which of them
+// produced missing values during execution.
+type resolutionTracker struct {
+	mode       MissingKeyMode
+	delims     xmlutils.Delimiters
+	tags       []UnresolvedTag
+	unresolved []bool
+	offsets    []int // position of each tag in the template source, -1 in partials
+	source     string
+	root       string // name the part's own templates are parsed under
+}
+
+// newTemplate creates a template configured for the given options.
+func newTemplate(funcMap template.FuncMap, opts Options) *template.Template {
+	tmpl := template.New("").Funcs(funcMap).Funcs(xmlutils.TemplateFuncs())
+	if opts.MissingKey == MissingKeyZero {
+		// Use missingkey=zero to output empty strings for missing/nil fields instead of "<no value>"
+		// which would break XML parsing (unescaped < and > characters)
+		return tmpl.Option("missingkey=zero")
+	}
+	// Keep missing keys invalid so chained lookups like .A.B resolve to nothing instead of failing
+	return tmpl.Option("missingkey=default")
+}
+
+// instrumentTemplate rewrites every output action of the parsed template so its
+// final value passes through the resolve function. The source is used to work
+// out which paragraph each action sits in.
+func instrumentTemplate(tmpl *template.Template, source string, opts Options) *resolutionTracker {
+	tracker := &resolutionTracker{mode: opts.MissingKey, delims: opts.Delimiters, source: source, root: tmpl.Name()}
+	if opts.MissingKey == MissingKeyZero {
+		return tracker
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		tracker.instrumentList(t.Tree, t.Tree.Root)
+	}
+
+	tmpl.Funcs(template.FuncMap{resolveFuncName: tracker.resolve})
+	return tracker
+}
+
+func (r *resolutionTracker) instrumentList(tree *parse.Tree, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) > 0 {
+				continue
+			}
+			id := len(r.tags)
+			offset := int(n.Position())
+			if tree.ParseName != r.root {
+				// Tags in partials have no position in the part
+				offset = -1
+			}
+			r.tags = append(r.tags, UnresolvedTag{Tag: n.String()})
+			r.offsets = append(r.offsets, offset)
+			r.unresolved = append(r.unresolved, false)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args: []parse.Node{
+					parse.NewIdentifier(resolveFuncName).SetTree(tree).SetPos(n.Pos),
+					&parse.StringNode{NodeType: parse.NodeString, Pos: n.Pos, Quoted: strconv.Quote(strconv.Itoa(id)), Text: strconv.Itoa(id)},
+				},
+			})
+		case *parse.IfNode:
+			r.instrumentList(tree, n.List)
+			r.instrumentList(tree, n.ElseList)
+		case *parse.RangeNode:
+			r.instrumentList(tree, n.List)
+			r.instrumentList(tree, n.ElseList)
+		case *parse.WithNode:
+			r.instrumentList(tree, n.List)
+			r.instrumentList(tree, n.ElseList)
+		case *parse.ListNode:
+			r.instrumentList(tree, n)
+		}
+	}
+}
+
+// fork returns a tracker with nothing resolved yet for an execution of a
+// clone of the instrumented template
+func (r *resolutionTracker) fork(tmpl *template.Template) *resolutionTracker {
+	fork := *r
+	fork.unresolved = make([]bool, len(r.unresolved))
+	if fork.mode != MissingKeyZero {
+		tmpl.Funcs(template.FuncMap{resolveFuncName: fork.resolve})
+	}
+	return &fork
+}
+
+// resolve is called with the final value of every instrumented output action.
+func (r *resolutionTracker) resolve(id string, value any) (any, error) {
+	if !isNilValue(value) {
+		return value, nil
+	}
+
+	index, err := strconv.Atoi(id)
+	if err != nil || index < 0 || index >= len(r.tags) {
+		return nil, fmt.Errorf("invalid placeholder reference %q", id)
+	}
+	r.unresolved[index] = true
+
+	if r.mode == MissingKeyKeep {
+		var buf bytes.Buffer
+		if err := xml.EscapeText(&buf, []byte(r.delims.Restore(r.tags[index].Tag))); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	}
+	return "", nil
+}
+
+// err returns the unresolved placeholders as an error in MissingKeyError mode.
+func (r *resolutionTracker) err() error {
+	if r.mode != MissingKeyError {
+		return nil
+	}
+	var unresolved []UnresolvedTag
+	for i, tag := range r.tags {
+		if r.unresolved[i] {
+			tag.Location = SourceLocation{Paragraph: -1, Table: -1}
+			if r.offsets[i] >= 0 {
+				tag.Location = LocateOffset(r.source, r.offsets[i])
+			}
+			unresolved = append(unresolved, tag)
+		}
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+	return &UnresolvedTagsError{Tags: unresolved}
+}
+
+// isNilValue reports whether a value is nil or a nil pointer/interface.
+func isNilValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
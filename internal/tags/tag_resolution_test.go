@@ -0,0 +1,94 @@
+package tags
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceTagsInXmlWithOptions(t *testing.T) {
+	xmlString := `<w:body><w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p><w:p><w:r><w:t>{{.Missing}} {{.Nested.Field}}</w:t></w:r></w:p></w:body>`
+	data := map[string]any{"Name": "Tom"}
+
+	t.Run("Zero mode renders missing values as empty text", func(t *testing.T) {
+		output, err := ReplaceTagsInXmlWithOptions(xmlString, map[string]any{"Name": "Tom", "Nested": map[string]any{}}, template.FuncMap{}, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, `<w:body><w:p><w:r><w:t>Tom</w:t></w:r></w:p><w:p><w:r><w:t> </w:t></w:r></w:p></w:body>`, output)
+	})
+
+	t.Run("Error mode lists every unresolved placeholder", func(t *testing.T) {
+		_, err := ReplaceTagsInXmlWithOptions(xmlString, data, template.FuncMap{}, Options{MissingKey: MissingKeyError})
+		require.Error(t, err)
+
+		var unresolvedErr *UnresolvedTagsError
+		require.True(t, errors.As(err, &unresolvedErr))
+		assert.Equal(t, []UnresolvedTag{
+			{Tag: "{{.Missing}}", Location: SourceLocation{Paragraph: 1, Table: -1, Snippet: "{{.Missing}} {{.Nested.Field}}"}},
+			{Tag: "{{.Nested.Field}}", Location: SourceLocation{Paragraph: 1, Table: -1, Snippet: "{{.Missing}} {{.Nested.Field}}"}},
+		}, unresolvedErr.Tags)
+	})
+
+	t.Run("Error mode succeeds when everything resolves", func(t *testing.T) {
+		output, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{.Name}}</w:t></w:p>`, data, template.FuncMap{}, Options{MissingKey: MissingKeyError})
+		require.NoError(t, err)
+		assert.Equal(t, `<w:p><w:t>Tom</w:t></w:p>`, output)
+	})
+
+	t.Run("Keep mode leaves the placeholder text in place", func(t *testing.T) {
+		output, err := ReplaceTagsInXmlWithOptions(xmlString, data, template.FuncMap{}, Options{MissingKey: MissingKeyKeep})
+		require.NoError(t, err)
+		assert.Equal(t, `<w:body><w:p><w:r><w:t>Tom</w:t></w:r></w:p><w:p><w:r><w:t>{{.Missing}} {{.Nested.Field}}</w:t></w:r></w:p></w:body>`, output)
+	})
+
+	t.Run("Placeholders inside blocks and loops are checked", func(t *testing.T) {
+		loop := `<w:p><w:t>{{range .Items}}{{.Name}}{{.Price}}{{end}}{{if true}}{{.Other}}{{end}}</w:t></w:p>`
+		loopData := map[string]any{"Items": []map[string]any{{"Name": "A"}, {"Name": "B"}}}
+		_, err := ReplaceTagsInXmlWithOptions(loop, loopData, template.FuncMap{}, Options{MissingKey: MissingKeyError})
+
+		var unresolvedErr *UnresolvedTagsError
+		require.True(t, errors.As(err, &unresolvedErr))
+		require.Len(t, unresolvedErr.Tags, 2)
+		assert.Equal(t, "{{.Price}}", unresolvedErr.Tags[0].Tag)
+		assert.Equal(t, "{{.Other}}", unresolvedErr.Tags[1].Tag)
+		assert.Equal(t, 0, unresolvedErr.Tags[1].Location.Paragraph)
+	})
+
+	t.Run("Variable declarations are not treated as output", func(t *testing.T) {
+		output, err := ReplaceTagsInXmlWithOptions(`<w:p><w:t>{{$x := .Missing}}{{.Name}}</w:t></w:p>`, data, template.FuncMap{}, Options{MissingKey: MissingKeyError})
+		require.NoError(t, err)
+		assert.Equal(t, `<w:p><w:t>Tom</w:t></w:p>`, output)
+	})
+}
+
+func TestReplaceTagsInTextWithOptions(t *testing.T) {
+	_, err := ReplaceTagsInTextWithOptions("DRAFT {{.Version}}", map[string]any{}, template.FuncMap{}, Options{MissingKey: MissingKeyError})
+
+	var unresolvedErr *UnresolvedTagsError
+	require.True(t, errors.As(err, &unresolvedErr))
+	assert.Equal(t, []UnresolvedTag{{Tag: "{{.Version}}", Location: SourceLocation{Paragraph: -1, Table: -1}}}, unresolvedErr.Tags)
+}
+
+func TestTemplateExecute(t *testing.T) {
+	greet := func(prefix string) template.FuncMap {
+		return template.FuncMap{"greet": func(name string) string { return prefix + name }}
+	}
+	tmpl, err := ParseXml(`<w:p><w:t>{{greet .Name}} {{.Title}}</w:t></w:p>`, greet(""), Options{MissingKey: MissingKeyError})
+	require.NoError(t, err)
+
+	// Each execution has its own functions and unresolved placeholders
+	output, err := tmpl.Execute(map[string]any{"Name": "Tom", "Title": "Dr"}, greet("Hi "))
+	require.NoError(t, err)
+	assert.Equal(t, `<w:p><w:t>Hi Tom Dr</w:t></w:p>`, output)
+
+	_, err = tmpl.Execute(map[string]any{"Name": "Ann"}, greet("Hi "))
+	var unresolvedErr *UnresolvedTagsError
+	require.True(t, errors.As(err, &unresolvedErr))
+	assert.Equal(t, "{{.Title}}", unresolvedErr.Tags[0].Tag)
+
+	output, err = tmpl.Execute(map[string]any{"Name": "Ann", "Title": "Prof"}, greet("Bye "))
+	require.NoError(t, err)
+	assert.Equal(t, `<w:p><w:t>Bye Ann Prof</w:t></w:p>`, output)
+}
@@ -1,26 +1,75 @@
 package templatedata
 
 import (
+	"database/sql/driver"
+	"encoding"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
+)
+
+// TemplateValuer is implemented by types that control their own conversion
+// into template data. The returned value is converted in place of the original.
+// Returning the receiver itself passes the value through unchanged.
+type TemplateValuer interface {
+	TemplateValue() any
+}
+
+// Options controls how Go values are converted into template data.
+type Options struct {
+	// UseJSONTags makes struct fields without a docx tag fall back to their json tag.
+	UseJSONTags bool
+}
+
+var (
+	templateValuerType = reflect.TypeOf((*TemplateValuer)(nil)).Elem()
+	driverValuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType       = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	timeType           = reflect.TypeOf(time.Time{})
 )
 
 // DataToMap converts any data type to a map[string]any for template processing.
 // Supports structs, maps, pointers, slices of any type.
 func DataToMap(data any) (map[string]any, error) {
+	return DataToMapWithOptions(data, Options{})
+}
+
+// DataToMapWithOptions converts data like DataToMap using the given options.
+//
+// Struct fields are keyed by their `docx:"name"` tag, falling back to the json
+// tag when UseJSONTags is set, and to the Go field name otherwise. A tag name of
+// "-" skips the field and the omitempty option skips zero values. Embedded
+// structs without a tag name have their fields flattened into the parent. The
+// structs, slices and custom types held by maps are converted too, while their
+// values of predeclared types such as int keep their type.
+func DataToMapWithOptions(data any, opts Options) (map[string]any, error) {
 	if data == nil {
 		return nil, fmt.Errorf("data is nil")
 	}
 
-	if m, ok := data.(map[string]any); ok {
-		return deepCopyMap(m), nil
-	}
+	c := &converter{opts: opts}
+	return c.convertToMap(reflect.ValueOf(data))
+}
 
-	return convertToMap(reflect.ValueOf(data))
+// converter carries the conversion options through the recursive walk
+type converter struct {
+	opts Options
 }
 
 // convertToMap recursively converts a reflect.Value to a map[string]any
-func convertToMap(val reflect.Value) (map[string]any, error) {
+func (c *converter) convertToMap(val reflect.Value) (map[string]any, error) {
+	if custom, ok, err := c.convertCustom(val); ok {
+		if err != nil {
+			return nil, err
+		}
+		if m, isMap := custom.(map[string]any); isMap {
+			return m, nil
+		}
+		return nil, fmt.Errorf("expected a struct or map, got %T", custom)
+	}
+
 	// Dereference pointers
 	val = dereferenceValue(val)
 
@@ -30,14 +79,150 @@ func convertToMap(val reflect.Value) (map[string]any, error) {
 
 	switch val.Kind() {
 	case reflect.Struct:
-		return convertStructToMap(val)
+		return c.convertStructToMap(val)
 	case reflect.Map:
-		return convertMapToMap(val)
+		return c.convertMapToMap(val)
 	default:
 		return nil, fmt.Errorf("expected a struct or map, got %s", val.Kind())
 	}
 }
 
+// convertCustom handles values that define their own template representation:
+// TemplateValuer implementations, time.Time, driver.Valuer types such as
+// sql.Null* and decimals, and opaque structs that marshal to text.
+// The boolean result reports whether the value was handled.
+func (c *converter) convertCustom(val reflect.Value) (any, bool, error) {
+	for val.IsValid() && val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, false, nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() || !val.CanInterface() {
+		return nil, false, nil
+	}
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		return nil, false, nil
+	}
+
+	if v, ok := implementing(val, templateValuerType); ok {
+		result := v.Interface().(TemplateValuer).TemplateValue()
+		if result == nil {
+			return nil, true, nil
+		}
+		resultVal := reflect.ValueOf(result)
+		if resultVal.Type() == v.Type() || resultVal.Type() == val.Type() {
+			return result, true, nil
+		}
+		converted, err := c.convertValue(resultVal)
+		return converted, true, err
+	}
+
+	base := dereferenceValue(val)
+	if base.Kind() != reflect.Struct {
+		return nil, false, nil
+	}
+
+	if base.Type() == timeType {
+		return base.Interface(), true, nil
+	}
+
+	if v, ok := implementing(val, driverValuerType); ok {
+		result, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return nil, true, err
+		}
+		if result == nil {
+			return nil, true, nil
+		}
+		converted, err := c.convertValue(reflect.ValueOf(result))
+		return converted, true, err
+	}
+
+	// Structs with no exported fields are opaque; use their text form if they have one
+	if hasExportedFields(base.Type()) {
+		return nil, false, nil
+	}
+	if v, ok := implementing(val, textMarshalerType); ok {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, true, err
+		}
+		return string(text), true, nil
+	}
+	if v, ok := implementing(val, stringerType); ok {
+		return v.Interface().(fmt.Stringer).String(), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// implementing returns the value, its pointer or its pointee that implements the interface
+func implementing(val reflect.Value, iface reflect.Type) (reflect.Value, bool) {
+	if val.Type().Implements(iface) {
+		return val, true
+	}
+	if val.Kind() == reflect.Ptr {
+		elem := val.Elem()
+		if elem.Type().Implements(iface) {
+			return elem, true
+		}
+		return reflect.Value{}, false
+	}
+	if reflect.PointerTo(val.Type()).Implements(iface) {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		return ptr, true
+	}
+	return reflect.Value{}, false
+}
+
+// isCustomType reports whether values of the type are converted by convertCustom
+// rather than walked field by field.
+func isCustomType(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	if t.Implements(templateValuerType) || reflect.PointerTo(t).Implements(templateValuerType) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if t == timeType || t.Implements(driverValuerType) || reflect.PointerTo(t).Implements(driverValuerType) {
+		return true
+	}
+	if hasExportedFields(t) {
+		return false
+	}
+	return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) ||
+		t.Implements(stringerType) || reflect.PointerTo(t).Implements(stringerType)
+}
+
+// isFlattenable reports whether an embedded field's fields should be promoted
+func isFlattenable(t reflect.Type) bool {
+	if isCustomType(t) {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// hasExportedFields reports whether a struct type has any exported fields
+func hasExportedFields(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
 // dereferenceValue dereferences pointers and interfaces to get the underlying value
 func dereferenceValue(val reflect.Value) reflect.Value {
 	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
@@ -50,30 +235,99 @@ func dereferenceValue(val reflect.Value) reflect.Value {
 }
 
 // convertStructToMap converts a struct to map[string]any
-func convertStructToMap(val reflect.Value) (map[string]any, error) {
+func (c *converter) convertStructToMap(val reflect.Value) (map[string]any, error) {
 	result := make(map[string]any)
+	if err := c.collectStructFields(val, result, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// collectStructFields adds the fields of a struct to result. Fields declared
+// directly on the struct win over fields promoted from embedded structs, which
+// is tracked through the explicit set.
+func (c *converter) collectStructFields(val reflect.Value, result map[string]any, explicit map[string]bool) error {
+	var embedded []reflect.Value
 
 	for i := range val.NumField() {
 		field := val.Type().Field(i)
+		name, omitEmpty, skip := c.fieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldValue := val.Field(i)
+
+		// Flatten embedded structs that aren't given an explicit name
+		if field.Anonymous && name == "" && isFlattenable(field.Type) {
+			if inner := dereferenceValue(fieldValue); inner.IsValid() {
+				embedded = append(embedded, inner)
+			}
+			continue
+		}
 
 		// Skip unexported fields
 		if !field.IsExported() {
 			continue
 		}
 
-		fieldValue := val.Field(i)
-		converted, err := convertValue(fieldValue)
+		if name == "" {
+			name = field.Name
+		}
+		if omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		converted, err := c.convertValue(fieldValue)
 		if err != nil {
-			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			return fmt.Errorf("field %s: %w", field.Name, err)
 		}
-		result[field.Name] = converted
+		result[name] = converted
+		explicit[name] = true
 	}
 
-	return result, nil
+	for _, inner := range embedded {
+		promoted := make(map[string]any)
+		if err := c.collectStructFields(inner, promoted, make(map[string]bool)); err != nil {
+			return err
+		}
+		for key, value := range promoted {
+			if !explicit[key] {
+				if _, exists := result[key]; !exists {
+					result[key] = value
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldName reads the docx (or json) tag of a struct field.
+// An empty name means the Go field name should be used.
+func (c *converter) fieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag, ok := field.Tag.Lookup("docx")
+	if !ok && c.opts.UseJSONTags {
+		tag, ok = field.Tag.Lookup("json")
+	}
+	if !ok {
+		return "", false, false
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, rest, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(rest, ",") {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
 }
 
 // convertMapToMap converts a map to map[string]any
-func convertMapToMap(val reflect.Value) (map[string]any, error) {
+func (c *converter) convertMapToMap(val reflect.Value) (map[string]any, error) {
 	result := make(map[string]any)
 
 	iter := val.MapRange()
@@ -87,7 +341,13 @@ func convertMapToMap(val reflect.Value) (map[string]any, error) {
 			return nil, err
 		}
 
-		converted, err := convertValue(mapValue)
+		// Values of the predeclared types, such as the ints of map data, keep
+		// their type
+		if plain, ok := predeclaredValue(mapValue); ok {
+			result[keyStr] = plain
+			continue
+		}
+		converted, err := c.convertValue(mapValue)
 		if err != nil {
 			return nil, fmt.Errorf("key %s: %w", keyStr, err)
 		}
@@ -97,6 +357,28 @@ func convertMapToMap(val reflect.Value) (map[string]any, error) {
 	return result, nil
 }
 
+// predeclaredValue returns the value held by a map value when its type is a
+// predeclared boolean, numeric or string type
+func predeclaredValue(val reflect.Value) (any, bool) {
+	if val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, false
+		}
+		val = val.Elem()
+	}
+	if val.Type().PkgPath() != "" || !val.CanInterface() {
+		return nil, false
+	}
+	switch val.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return val.Interface(), true
+	}
+	return nil, false
+}
+
 // keyToString converts a map key to a string
 func keyToString(key reflect.Value) (string, error) {
 	key = dereferenceValue(key)
@@ -113,7 +395,11 @@ func keyToString(key reflect.Value) (string, error) {
 }
 
 // convertValue converts any value to a template-compatible type
-func convertValue(val reflect.Value) (any, error) {
+func (c *converter) convertValue(val reflect.Value) (any, error) {
+	if custom, ok, err := c.convertCustom(val); ok {
+		return custom, err
+	}
+
 	val = dereferenceValue(val)
 
 	if !val.IsValid() {
@@ -122,13 +408,13 @@ func convertValue(val reflect.Value) (any, error) {
 
 	switch val.Kind() {
 	case reflect.Struct:
-		return convertStructToMap(val)
+		return c.convertStructToMap(val)
 
 	case reflect.Map:
-		return convertMapToMap(val)
+		return c.convertMapToMap(val)
 
 	case reflect.Slice, reflect.Array:
-		return convertSlice(val)
+		return c.convertSlice(val)
 
 	case reflect.String:
 		return val.String(), nil
@@ -149,7 +435,7 @@ func convertValue(val reflect.Value) (any, error) {
 		if val.IsNil() {
 			return nil, nil
 		}
-		return convertValue(val.Elem())
+		return c.convertValue(val.Elem())
 
 	default:
 		// For other types (func, chan, etc.), return as-is
@@ -161,17 +447,17 @@ func convertValue(val reflect.Value) (any, error) {
 }
 
 // convertSlice converts a slice/array to []any or []map[string]any
-func convertSlice(val reflect.Value) (any, error) {
+func (c *converter) convertSlice(val reflect.Value) (any, error) {
 	length := val.Len()
 
 	// Check if it's a slice of structs or maps (convert to []map[string]any)
 	if length > 0 {
 		firstElem := dereferenceValue(val.Index(0))
-		if firstElem.IsValid() && (firstElem.Kind() == reflect.Struct || firstElem.Kind() == reflect.Map) {
+		if firstElem.IsValid() && (firstElem.Kind() == reflect.Struct || firstElem.Kind() == reflect.Map) && !isCustomType(firstElem.Type()) {
 			mapSlice := make([]map[string]any, length)
 			for i := range length {
 				elem := val.Index(i)
-				converted, err := convertValue(elem)
+				converted, err := c.convertValue(elem)
 				if err != nil {
 					return nil, fmt.Errorf("index %d: %w", i, err)
 				}
@@ -190,7 +476,7 @@ func convertSlice(val reflect.Value) (any, error) {
 	result := make([]any, length)
 	for i := range length {
 		elem := val.Index(i)
-		converted, err := convertValue(elem)
+		converted, err := c.convertValue(elem)
 		if err != nil {
 			return nil, fmt.Errorf("index %d: %w", i, err)
 		}
@@ -198,27 +484,3 @@ func convertSlice(val reflect.Value) (any, error) {
 	}
 	return result, nil
 }
-
-// deepCopyMap creates a deep copy of a map[string]any
-func deepCopyMap(m map[string]any) map[string]any {
-	result := make(map[string]any, len(m))
-	for k, v := range m {
-		switch val := v.(type) {
-		case map[string]any:
-			result[k] = deepCopyMap(val)
-		case []map[string]any:
-			newSlice := make([]map[string]any, len(val))
-			for i, item := range val {
-				newSlice[i] = deepCopyMap(item)
-			}
-			result[k] = newSlice
-		case []any:
-			newSlice := make([]any, len(val))
-			copy(newSlice, val)
-			result[k] = newSlice
-		default:
-			result[k] = v
-		}
-	}
-	return result
-}
@@ -1,7 +1,10 @@
 package templatedata
 
 import (
+	"database/sql"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -238,3 +241,180 @@ func TestDataToMap(t *testing.T) {
 		assert.Equal("Bob", people[1]["Name"])
 	})
 }
+
+type money struct {
+	cents int64
+}
+
+func (m money) TemplateValue() any {
+	return fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100)
+}
+
+type opaqueID struct {
+	id int
+}
+
+func (o opaqueID) String() string {
+	return fmt.Sprintf("ID-%d", o.id)
+}
+
+func TestDataToMapWithOptions(t *testing.T) {
+	t.Run("Docx tags rename, skip and omit fields", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data := struct {
+			Name     string `docx:"customer_name"`
+			Secret   string `docx:"-"`
+			Nickname string `docx:"nickname,omitempty"`
+			Email    string `docx:",omitempty"`
+			Plain    string
+		}{
+			Name:   "Tom",
+			Secret: "hidden",
+			Plain:  "plain",
+		}
+		outputMap, err := DataToMap(data)
+		assert.Nil(err)
+		assert.Equal(map[string]any{
+			"customer_name": "Tom",
+			"Plain":         "plain",
+		}, outputMap)
+	})
+
+	t.Run("JSON tags are only used when enabled", func(t *testing.T) {
+		assert := assert.New(t)
+
+		data := struct {
+			Name  string `json:"customer_name"`
+			Email string `json:"email" docx:"contact_email"`
+		}{
+			Name:  "Tom",
+			Email: "tom@example.com",
+		}
+
+		outputMap, err := DataToMap(data)
+		assert.Nil(err)
+		assert.Equal(map[string]any{
+			"Name":          "Tom",
+			"contact_email": "tom@example.com",
+		}, outputMap)
+
+		outputMap, err = DataToMapWithOptions(data, Options{UseJSONTags: true})
+		assert.Nil(err)
+		assert.Equal(map[string]any{
+			"customer_name": "Tom",
+			"contact_email": "tom@example.com",
+		}, outputMap)
+	})
+
+	t.Run("Embedded structs are flattened", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type Address struct {
+			Street string
+			City   string
+		}
+		type Audit struct {
+			CreatedBy string
+		}
+		data := struct {
+			Address
+			*Audit
+			City string
+		}{
+			Address: Address{Street: "1 Main St", City: "Ignored"},
+			City:    "London",
+		}
+		outputMap, err := DataToMap(data)
+		assert.Nil(err)
+		assert.Equal(map[string]any{
+			"Street": "1 Main St",
+			"City":   "London",
+		}, outputMap)
+	})
+
+	t.Run("Embedded struct with a tag name is nested", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type Address struct {
+			City string
+		}
+		data := struct {
+			Address `docx:"address"`
+		}{
+			Address: Address{City: "London"},
+		}
+		outputMap, err := DataToMap(data)
+		assert.Nil(err)
+		assert.Equal(map[string]any{"City": "London"}, outputMap["address"])
+	})
+
+	t.Run("Custom and opaque values", func(t *testing.T) {
+		assert := assert.New(t)
+
+		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		data := struct {
+			Date    time.Time
+			Total   money
+			ID      opaqueID
+			Note    sql.NullString
+			Missing sql.NullInt64
+			Dates   []time.Time
+		}{
+			Date:  date,
+			Total: money{cents: 12345},
+			ID:    opaqueID{id: 7},
+			Note:  sql.NullString{String: "ok", Valid: true},
+			Dates: []time.Time{date},
+		}
+		outputMap, err := DataToMap(data)
+		assert.Nil(err)
+		assert.Equal(date, outputMap["Date"])
+		assert.Equal("123.45", outputMap["Total"])
+		assert.Equal("ID-7", outputMap["ID"])
+		assert.Equal("ok", outputMap["Note"])
+		assert.Nil(outputMap["Missing"])
+		assert.Equal([]any{date}, outputMap["Dates"])
+	})
+
+	t.Run("Values of maps are converted", func(t *testing.T) {
+		assert := assert.New(t)
+
+		type customer struct {
+			Name   string `docx:"name"`
+			Secret string `docx:"-"`
+		}
+		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		value := &passthrough{Name: "kept"}
+		outputMap, err := DataToMap(map[string]any{
+			"C":     customer{Name: "A & B", Secret: "hidden"},
+			"List":  []customer{{Name: "C"}},
+			"Inner": map[string]any{"Date": date, "Note": sql.NullString{String: "ok", Valid: true}},
+			"Value": value,
+			"Count": 3,
+		})
+		assert.Nil(err)
+		assert.Equal(map[string]any{"name": "A & B"}, outputMap["C"])
+		assert.Equal([]map[string]any{{"name": "C"}}, outputMap["List"])
+		assert.Equal(map[string]any{"Date": date, "Note": "ok"}, outputMap["Inner"])
+		assert.Same(value, outputMap["Value"])
+		assert.Equal(3, outputMap["Count"])
+	})
+
+	t.Run("TemplateValuer returning itself is passed through", func(t *testing.T) {
+		assert := assert.New(t)
+
+		value := &passthrough{Name: "kept"}
+		outputMap, err := DataToMap(struct{ Value *passthrough }{value})
+		assert.Nil(err)
+		assert.Same(value, outputMap["Value"])
+	})
+}
+
+type passthrough struct {
+	Name string
+}
+
+func (p *passthrough) TemplateValue() any {
+	return p
+}
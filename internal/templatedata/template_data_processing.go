@@ -25,7 +25,7 @@ func IsFilePath(filepath string) (bool, error) {
 
 func IsImageFilePath(filepath string) (bool, error) {
 	ext := path.Ext(filepath)
-	validExts := []string{".png", ".jpg", ".jpeg"}
+	validExts := []string{".png", ".jpg", ".jpeg", ".gif", ".bmp", ".tif", ".tiff", ".webp", ".svg"}
 	isValid := slices.Contains(validExts, ext)
 	if !isValid {
 		return false, nil
@@ -0,0 +1,319 @@
+// Package xlsx reads the cell values of worksheets in Excel workbooks. It
+// reads values only: formulas give their cached result and formatting is
+// only used to tell dates from numbers.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type workbookXml struct {
+	WorkbookPr struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsXml struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// richText is a string of a cell, written whole or in formatted runs
+type richText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) text() string {
+	text := t.T
+	for _, run := range t.R {
+		text += run.T
+	}
+	return text
+}
+
+type sharedStringsXml struct {
+	Items []richText `xml:"si"`
+}
+
+type stylesXml struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type worksheetXml struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R  string   `xml:"r,attr"`
+			T  string   `xml:"t,attr"`
+			S  int      `xml:"s,attr"`
+			V  string   `xml:"v"`
+			Is richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Workbook is an Excel workbook opened for reading values
+type Workbook struct {
+	files    map[string]*zip.File
+	sheets   []string          // sheet names, in workbook order
+	targets  map[string]string // sheet name to worksheet part
+	strings  []string
+	dates    []bool // whether each cell style formats dates
+	date1904 bool
+}
+
+// Open opens the workbook in an .xlsx archive
+func Open(reader io.ReaderAt, size int64) (*Workbook, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	w := &Workbook{files: make(map[string]*zip.File), targets: make(map[string]string)}
+	for _, f := range zipReader.File {
+		w.files[f.Name] = f
+	}
+
+	var workbook workbookXml
+	if err := w.read("xl/workbook.xml", &workbook, true); err != nil {
+		return nil, err
+	}
+	var rels relationshipsXml
+	if err := w.read("xl/_rels/workbook.xml.rels", &rels, true); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string)
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+	for _, sheet := range workbook.Sheets {
+		w.sheets = append(w.sheets, sheet.Name)
+		w.targets[sheet.Name] = targets[sheet.ID]
+	}
+	w.date1904 = workbook.WorkbookPr.Date1904 == "1" || workbook.WorkbookPr.Date1904 == "true"
+
+	var shared sharedStringsXml
+	if err := w.read("xl/sharedStrings.xml", &shared, false); err != nil {
+		return nil, err
+	}
+	for _, item := range shared.Items {
+		w.strings = append(w.strings, item.text())
+	}
+
+	var styles stylesXml
+	if err := w.read("xl/styles.xml", &styles, false); err != nil {
+		return nil, err
+	}
+	customDates := make(map[int]bool)
+	for _, numFmt := range styles.NumFmts {
+		customDates[numFmt.ID] = isDateFormat(numFmt.Code)
+	}
+	for _, xf := range styles.CellXfs {
+		w.dates = append(w.dates, isBuiltinDateFormat(xf.NumFmtID) || customDates[xf.NumFmtID])
+	}
+	return w, nil
+}
+
+// Sheets returns the names of the sheets, in workbook order
+func (w *Workbook) Sheets() []string {
+	return w.sheets
+}
+
+// Date1904 reports whether the workbook counts serial dates from 1904
+func (w *Workbook) Date1904() bool {
+	return w.date1904
+}
+
+// Rows returns the values of the cells of a sheet, or of the first sheet when
+// name is empty. Values are strings, int64 or float64 numbers, bools and
+// time.Time dates, and nil for empty cells. Empty rows are kept, trailing
+// empty cells aren't.
+func (w *Workbook) Rows(name string) ([][]any, error) {
+	if name == "" {
+		if len(w.sheets) == 0 {
+			return nil, fmt.Errorf("workbook has no sheets")
+		}
+		name = w.sheets[0]
+	}
+	target, ok := w.targets[name]
+	if !ok {
+		return nil, fmt.Errorf("workbook has no sheet %q", name)
+	}
+	var sheet worksheetXml
+	if err := w.read(target, &sheet, true); err != nil {
+		return nil, err
+	}
+
+	var rows [][]any
+	for _, row := range sheet.Rows {
+		index := len(rows)
+		if row.R > 0 {
+			index = row.R - 1
+		}
+		for len(rows) <= index {
+			rows = append(rows, nil)
+		}
+
+		var values []any
+		for _, cell := range row.Cells {
+			column := len(values)
+			if cell.R != "" {
+				if column, ok = columnIndex(cell.R); !ok {
+					return nil, fmt.Errorf("invalid cell reference %q", cell.R)
+				}
+			}
+			value, err := w.cellValue(cell.T, cell.S, cell.V, cell.Is)
+			if err != nil {
+				return nil, fmt.Errorf("cell %s: %w", cell.R, err)
+			}
+			for len(values) <= column {
+				values = append(values, nil)
+			}
+			values[column] = value
+		}
+		rows[index] = values
+	}
+	return rows, nil
+}
+
+// cellValue returns the value of a cell from its type, style and content
+func (w *Workbook) cellValue(kind string, style int, value string, inline richText) (any, error) {
+	switch kind {
+	case "s":
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 || index >= len(w.strings) {
+			return nil, fmt.Errorf("invalid shared string %q", value)
+		}
+		return w.strings[index], nil
+	case "inlineStr":
+		return inline.text(), nil
+	case "str", "e":
+		return value, nil
+	case "b":
+		return value == "1", nil
+	}
+
+	if value == "" {
+		return nil, nil
+	}
+	if style >= 0 && style < len(w.dates) && w.dates[style] {
+		serial, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", value)
+		}
+		return SerialDate(serial, w.date1904), nil
+	}
+	if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return integer, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", value)
+	}
+	return number, nil
+}
+
+// SerialDate returns the date of an Excel serial date, the number of days
+// since the start of 1900, or of 1904 in workbooks using the 1904 date system
+func SerialDate(serial float64, date1904 bool) time.Time {
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(serial)
+	milliseconds := math.Round((serial - days) * 24 * 60 * 60 * 1000)
+	return epoch.AddDate(0, 0, int(days)).Add(time.Duration(milliseconds) * time.Millisecond)
+}
+
+// read unmarshals a part of the workbook, which may be missing unless required
+func (w *Workbook) read(name string, v any, required bool) error {
+	f, ok := w.files[name]
+	if !ok {
+		if required {
+			return fmt.Errorf("workbook has no %s", name)
+		}
+		return nil
+	}
+	zf, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer zf.Close()
+	if err := xml.NewDecoder(zf).Decode(v); err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the index of the column of a cell reference like "AB12"
+func columnIndex(reference string) (int, bool) {
+	column := 0
+	letters := 0
+	for _, r := range reference {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+		letters++
+	}
+	return column - 1, letters > 0
+}
+
+// isBuiltinDateFormat reports whether a built-in number format shows dates
+// or times
+func isBuiltinDateFormat(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 45 && id <= 47) || (id >= 27 && id <= 36) || (id >= 50 && id <= 58)
+}
+
+// isDateFormat reports whether a custom number format code shows dates or
+// times, ignoring literal text, escaped characters and colors
+func isDateFormat(code string) bool {
+	var b strings.Builder
+	inQuotes, inBrackets, escaped := false, false, false
+	for _, r := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes:
+			inQuotes = r != '"'
+		case inBrackets:
+			inBrackets = r != ']'
+		case r == '"':
+			inQuotes = true
+		case r == '[':
+			inBrackets = true
+		case r == '\\' || r == '_' || r == '*':
+			escaped = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	section, _, _ := strings.Cut(strings.ToLower(b.String()), ";")
+	return section != "general" && strings.ContainsAny(section, "dmyhs")
+}
@@ -0,0 +1,91 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workbook returns an .xlsx archive holding the parts
+func workbook(t *testing.T, parts map[string]string) *Workbook {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	w, err := Open(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return w
+}
+
+func TestWorkbook(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Orders" sheetId="1" r:id="rId1"/><sheet name="Notes" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>Name</t></si><si><t>Date</t></si><si><r><t>Ada </t></r><r><rPr><b/></rPr><t>Lovelace</t></r></si></sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<numFmts><numFmt numFmtId="164" formatCode="&quot;Day&quot; dd/mm/yyyy"/><numFmt numFmtId="165" formatCode="[Red]0.00"/></numFmts>` +
+			`<cellXfs><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/><xf numFmtId="165"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>Total</t></is></c><c r="D1" t="str"><v>Paid</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" s="1"><v>45356</v></c><c r="C2" s="3"><v>12.5</v></c><c r="D2" t="b"><v>1</v></c></row>` +
+			`<row r="4"><c r="B4" s="2"><v>45356.5</v></c><c r="C4"><v>7</v></c></row>` +
+			`</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row><c t="inlineStr"><is><t>Note</t></is></c></row></sheetData></worksheet>`,
+	}
+	w := workbook(t, parts)
+	assert.Equal(t, []string{"Orders", "Notes"}, w.Sheets())
+
+	rows, err := w.Rows("")
+	require.NoError(t, err)
+	march5 := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, [][]any{
+		{"Name", "Date", "Total", "Paid"},
+		{"Ada Lovelace", march5, 12.5, true},
+		nil,
+		{nil, march5.Add(12 * time.Hour), int64(7)},
+	}, rows)
+
+	rows, err = w.Rows("Notes")
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{"Note"}}, rows)
+
+	_, err = w.Rows("Missing")
+	assert.ErrorContains(t, err, `workbook has no sheet "Missing"`)
+}
+
+func TestSerialDate(t *testing.T) {
+	assert.Equal(t, time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC), SerialDate(61, false))
+	assert.Equal(t, time.Date(2024, time.March, 5, 6, 0, 0, 0, time.UTC), SerialDate(43894.25, true))
+}
+
+func TestIsDateFormat(t *testing.T) {
+	tests := map[string]bool{
+		"yyyy-mm-dd":          true,
+		"h:mm AM/PM":          true,
+		`"Day" dd/mm/yyyy`:    true,
+		"[Red]0.00":           false,
+		`0.00" days"`:         false,
+		"General":             false,
+		`#,##0\ "m²"`:         false,
+		"[$-409]mmmm d, yyyy": true,
+	}
+	for code, want := range tests {
+		assert.Equal(t, want, isDateFormat(code), code)
+	}
+}
@@ -0,0 +1,112 @@
+package xmlutils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BlockContentStart and BlockContentEnd delimit block content (paragraphs,
+// lists and tables) in rendered XML. The paragraph holding the placeholder is
+// split around the content after tag replacement.
+const (
+	BlockContentStart = "<docxtpl:blockContent>"
+	BlockContentEnd   = "</docxtpl:blockContent>"
+)
+
+// paragraphPropertiesRegex matches the properties at the start of a paragraph's content
+var paragraphPropertiesRegex = regexp.MustCompile(`(?s)^<w:pPr>.*?</w:pPr>`)
+
+// sectionPropertiesRegex matches section properties held in paragraph properties
+var sectionPropertiesRegex = regexp.MustCompile(`(?s)<w:sectPr[ >].*?</w:sectPr>`)
+
+// tabRegex matches tab elements
+var tabRegex = regexp.MustCompile(`<w:tab(?:\s[^>]*)?(?:/>|></w:tab>)`)
+
+// expandBlockContent splits the paragraphs holding block content so the content
+// sits between the text before and after the placeholder. The parts of the
+// paragraph left without text are dropped. Content that isn't in the text of a
+// run is replaced by its plain text.
+func expandBlockContent(xmlString string) string {
+	searchFrom := 0
+	for {
+		start := strings.Index(xmlString[searchFrom:], BlockContentStart)
+		if start < 0 {
+			return xmlString
+		}
+		start += searchFrom
+		end := strings.Index(xmlString[start:], BlockContentEnd)
+		if end < 0 {
+			return xmlString
+		}
+		end += start
+		contentEnd := end + len(BlockContentEnd)
+
+		before := xmlString[:start]
+		blocks := xmlString[start+len(BlockContentStart) : end]
+
+		paragraphStart := max(strings.LastIndex(before, "<w:p>"), strings.LastIndex(before, "<w:p "))
+		runStart := max(strings.LastIndex(before, "<w:r>"), strings.LastIndex(before, "<w:r "))
+		textStart := max(strings.LastIndex(before, "<w:t>"), strings.LastIndex(before, "<w:t "))
+		paragraphEnd := strings.Index(xmlString[contentEnd:], "</w:p>")
+		if paragraphStart < 0 || strings.LastIndex(before, "</w:p>") > paragraphStart || runStart < paragraphStart ||
+			textStart < runStart || strings.LastIndex(before, "</w:t>") > textStart || paragraphEnd < 0 {
+			replacement := blockContentPlainText(blocks)
+			xmlString = before + replacement + xmlString[contentEnd:]
+			searchFrom = start + len(replacement)
+			continue
+		}
+		paragraphEnd += contentEnd + len("</w:p>")
+
+		paragraphTag := before[paragraphStart : paragraphStart+strings.IndexByte(before[paragraphStart:], '>')+1]
+		paragraphProperties := paragraphPropertiesRegex.FindString(before[paragraphStart+len(paragraphTag):])
+		runTag := before[runStart : runStart+strings.IndexByte(before[runStart:], '>')+1]
+		runProperties := runPropertiesRegex.FindString(before[runStart+len(runTag):])
+
+		head := before[paragraphStart:] + "</w:t></w:r></w:p>"
+		tail := paragraphTag + paragraphProperties + runTag + runProperties + `<w:t xml:space="preserve">` + xmlString[contentEnd:paragraphEnd]
+
+		// A section break stays with the last part of the paragraph
+		sectionBreak := sectionPropertiesRegex.MatchString(paragraphProperties)
+		if sectionBreak {
+			head = strings.Replace(head, paragraphProperties, sectionPropertiesRegex.ReplaceAllString(paragraphProperties, ""), 1)
+		}
+
+		if !paragraphHasContent(head) {
+			head = ""
+		}
+		// Table cells must end with a paragraph
+		cellEnd := strings.HasPrefix(xmlString[paragraphEnd:], "</w:tc>") && strings.HasSuffix(blocks, "</w:tbl>")
+		if !paragraphHasContent(tail) && !sectionBreak && !cellEnd {
+			tail = ""
+		}
+
+		replacement := head + blocks + tail
+		xmlString = xmlString[:paragraphStart] + replacement + xmlString[paragraphEnd:]
+		searchFrom = paragraphStart + len(head) + len(blocks)
+	}
+}
+
+// paragraphHasContent reports whether a paragraph has visible text or non-text content
+func paragraphHasContent(paragraph string) bool {
+	for _, marker := range nonTextContentMarkers {
+		if strings.Contains(paragraph, marker) {
+			return true
+		}
+	}
+	for _, match := range textContentRegex.FindAllStringSubmatch(paragraph, -1) {
+		if strings.TrimSpace(match[1]) != "" {
+			return true
+		}
+	}
+	return strings.Contains(paragraph, RichTextStart)
+}
+
+// blockContentPlainText returns the text of block content with one line per paragraph
+func blockContentPlainText(blocks string) string {
+	blocks = tabRegex.ReplaceAllString(blocks, "<w:t>\t</w:t>")
+	var lines []string
+	for _, span := range elementSpans(blocks, "p") {
+		lines = append(lines, richTextPlainText(blocks[span[0]:span[1]]))
+	}
+	return strings.Join(lines, "\n")
+}
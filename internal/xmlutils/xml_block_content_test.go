@@ -0,0 +1,57 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandBlockContent(t *testing.T) {
+	const blocks = `<w:p><w:r><w:t>One</w:t></w:r></w:p><w:p><w:r><w:t>Two</w:t></w:r></w:p>`
+
+	tests := []struct {
+		name              string
+		inputXml          string
+		expectedOutputXml string
+	}{
+		{
+			name:              "Placeholder alone in its paragraph is replaced by the content",
+			inputXml:          `<w:body><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>` + BlockContentStart + blocks + BlockContentEnd + `</w:t></w:r></w:p></w:body>`,
+			expectedOutputXml: `<w:body>` + blocks + `</w:body>`,
+		},
+		{
+			name:              "Text before and after the placeholder is kept in split paragraphs",
+			inputXml:          `<w:p w:rsidR="00AB"><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Before </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>` + BlockContentStart + blocks + BlockContentEnd + ` after</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p w:rsidR="00AB"><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Before </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t></w:t></w:r></w:p>` + blocks + `<w:p w:rsidR="00AB"><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve"> after</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Section break stays with the last part of the paragraph",
+			inputXml:          `<w:p><w:pPr><w:sectPr><w:type w:val="nextPage"/></w:sectPr></w:pPr><w:r><w:t>Intro ` + BlockContentStart + blocks + BlockContentEnd + `</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:pPr></w:pPr><w:r><w:t>Intro </w:t></w:r></w:p>` + blocks + `<w:p><w:pPr><w:sectPr><w:type w:val="nextPage"/></w:sectPr></w:pPr><w:r><w:t xml:space="preserve"></w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Table cells ending with a table keep a paragraph",
+			inputXml:          `<w:tc><w:p><w:r><w:t>` + BlockContentStart + `<w:tbl></w:tbl>` + BlockContentEnd + `</w:t></w:r></w:p></w:tc>`,
+			expectedOutputXml: `<w:tc><w:tbl></w:tbl><w:p><w:r><w:t xml:space="preserve"></w:t></w:r></w:p></w:tc>`,
+		},
+		{
+			name:              "Content outside of run text becomes plain text",
+			inputXml:          `<w:p w:val="` + BlockContentStart + blocks + BlockContentEnd + `"></w:p>`,
+			expectedOutputXml: "<w:p w:val=\"One\nTwo\"></w:p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			outputXml := expandBlockContent(tt.inputXml)
+			assert.Equal(tt.expectedOutputXml, outputXml)
+		})
+	}
+}
+
+func TestStripBlockContent(t *testing.T) {
+	content := `<w:p><w:r><w:t>•</w:t><w:tab></w:tab></w:r><w:r><w:t>` + RichTextStart + `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Item</w:t></w:r>` + RichTextEnd + `</w:t></w:r></w:p><w:p><w:r><w:t>Done</w:t></w:r></w:p>`
+	assert.Equal(t, "Notes: •\tItem\nDone", StripRichText("Notes: "+BlockContentStart+content+BlockContentEnd))
+}
@@ -0,0 +1,238 @@
+package xmlutils
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// blockControlTagRegex matches a template control tag such as {{range .Items}},
+// {{if .Show}}, {{else}} or {{end}}, including the whitespace-trimming variants
+var blockControlTagRegex = regexp.MustCompile(`\{\{-?\s*(if|else|end|range|with|define|block|break|continue)\b[^{}]*?-?\}\}`)
+
+// markupRegex matches XML markup, leaving the text of the document and bare
+// tags between elements
+var markupRegex = regexp.MustCompile(`<[^>]*>`)
+
+// paragraphBoundaryRegex matches paragraph start and end tags
+var paragraphBoundaryRegex = regexp.MustCompile(`<w:p[ >]|</w:p>`)
+
+// cellStartSuffixRegex matches a table cell start tag at the end of a string
+var cellStartSuffixRegex = regexp.MustCompile(`<w:tc(?:\s[^>]*)?>$`)
+
+// textContentRegex matches the content of text elements
+var textContentRegex = regexp.MustCompile(`<w:t(?:\s[^>]*)?>([^<]*)</w:t>`)
+
+// nonTextContentMarkers are elements that make a paragraph more than a holder for a control tag.
+// Paragraphs containing them are left alone so their content isn't lost.
+var nonTextContentMarkers = []string{
+	"<w:drawing", "<w:pict", "<w:object", "<w:sectPr", "<w:fldSimple", "<w:fldChar", `w:type="page"`,
+}
+
+// replaceBlockParagraphs replaces paragraphs that contain nothing but a single
+// control tag with the bare tag. This turns {{range}}, {{if}}, {{with}} and
+// their {{else}}/{{end}} into paragraph-level control: everything between the
+// opening and closing paragraphs (paragraphs, tables, images, page breaks) is
+// repeated or dropped as a whole and no empty paragraphs are left behind.
+// Paragraphs are only replaced when every tag of their blocks is alone in its
+// paragraph, so a block ending within the text of a paragraph keeps the
+// paragraphs around its tags balanced.
+func replaceBlockParagraphs(xmlString string) string {
+	boundaries := paragraphBoundaryRegex.FindAllStringIndex(xmlString, -1)
+	if len(boundaries) == 0 {
+		return xmlString
+	}
+
+	type openParagraph struct {
+		start    int
+		hasChild bool
+	}
+
+	var blockParagraphs []blockParagraph
+	var open []openParagraph
+
+	for _, boundary := range boundaries {
+		if xmlString[boundary[0]+1] != '/' {
+			if len(open) > 0 {
+				open[len(open)-1].hasChild = true
+			}
+			open = append(open, openParagraph{start: boundary[0]})
+			continue
+		}
+		if len(open) == 0 {
+			continue
+		}
+
+		current := open[len(open)-1]
+		open = open[:len(open)-1]
+
+		// Leave paragraphs inside text boxes and the paragraphs containing them alone
+		if current.hasChild || len(open) > 0 {
+			continue
+		}
+
+		// Table cells must keep at least one paragraph
+		if isOnlyParagraphInCell(xmlString, current.start, boundary[1]) {
+			continue
+		}
+
+		if tags, ok := controlTagsOnly(xmlString[current.start:boundary[1]]); ok {
+			blockParagraphs = append(blockParagraphs, blockParagraph{start: current.start, end: boundary[1], tags: tags})
+		}
+	}
+
+	var result strings.Builder
+	lastEnd := 0
+	for _, paragraph := range balancedBlockParagraphs(xmlString, blockParagraphs) {
+		result.WriteString(xmlString[lastEnd:paragraph.start])
+		result.WriteString(paragraph.tags)
+		lastEnd = paragraph.end
+	}
+	result.WriteString(xmlString[lastEnd:])
+	return result.String()
+}
+
+// blockParagraph is a paragraph holding nothing but control tags
+type blockParagraph struct {
+	start, end int
+	tags       string
+}
+
+// balancedBlockParagraphs returns the block paragraphs, in document order,
+// whose tags belong to blocks whose every tag (opening, {{else}}, {{break}},
+// {{continue}} and {{end}}) is alone in a block paragraph
+func balancedBlockParagraphs(xmlString string, paragraphs []blockParagraph) []blockParagraph {
+	if len(paragraphs) == 0 {
+		return nil
+	}
+
+	// The text of the document and the bare tags left by table rows, with the
+	// offset of each piece and whether it is bare
+	var text strings.Builder
+	var textStarts, xmlStarts []int
+	var bare []bool
+	lastEnd := 0
+	inText := false
+	for _, markup := range append(markupRegex.FindAllStringIndex(xmlString, -1), []int{len(xmlString), len(xmlString)}) {
+		if markup[0] > lastEnd {
+			textStarts = append(textStarts, text.Len())
+			xmlStarts = append(xmlStarts, lastEnd)
+			bare = append(bare, !inText)
+			text.WriteString(xmlString[lastEnd:markup[0]])
+		}
+		element := xmlString[markup[0]:markup[1]]
+		inText = strings.HasPrefix(element, "<w:t>") || strings.HasPrefix(element, "<w:t ")
+		lastEnd = markup[1]
+	}
+
+	// Match the tags into blocks, noting the paragraph holding each tag
+	type controlTag struct {
+		paragraph int // -1 when not in a block paragraph
+		block     int // -1 when the tag belongs to no block
+		bare      bool
+	}
+	var tags []controlTag
+	var stack []int
+	blocks := 0
+	for _, match := range blockControlTagRegex.FindAllStringSubmatchIndex(text.String(), -1) {
+		piece := sort.SearchInts(textStarts, match[0]+1) - 1
+		offset := xmlStarts[piece] + match[0] - textStarts[piece]
+		paragraph, found := slices.BinarySearchFunc(paragraphs, offset, func(p blockParagraph, offset int) int {
+			if p.end <= offset {
+				return -1
+			}
+			if p.start > offset {
+				return 1
+			}
+			return 0
+		})
+		if !found {
+			paragraph = -1
+		}
+
+		tag := controlTag{paragraph: paragraph, block: -1, bare: bare[piece]}
+		switch text.String()[match[2]:match[3]] {
+		case "if", "range", "with", "define", "block":
+			tag.block = blocks
+			stack = append(stack, blocks)
+			blocks++
+		case "end":
+			if len(stack) > 0 {
+				tag.block = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			if len(stack) > 0 {
+				tag.block = stack[len(stack)-1]
+			}
+		}
+		tags = append(tags, tag)
+	}
+
+	// Blocks left open are unbalanced
+	balanced := make([]bool, blocks)
+	for i := range balanced {
+		balanced[i] = !slices.Contains(stack, i)
+	}
+	keep := make([]bool, len(paragraphs))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	// A paragraph is kept when all its blocks are balanced, and a block is
+	// balanced when all its tags are bare or in kept paragraphs
+	for changed := true; changed; {
+		changed = false
+		for _, tag := range tags {
+			inKept := tag.bare || (tag.paragraph >= 0 && keep[tag.paragraph])
+			if tag.block >= 0 && balanced[tag.block] && !inKept {
+				balanced[tag.block] = false
+				changed = true
+			}
+			if inKept && (tag.block < 0 || !balanced[tag.block]) {
+				keep[tag.paragraph] = false
+				changed = true
+			}
+		}
+	}
+
+	var kept []blockParagraph
+	for i, paragraph := range paragraphs {
+		if keep[i] {
+			kept = append(kept, paragraph)
+		}
+	}
+	return kept
+}
+
+// isOnlyParagraphInCell reports whether the paragraph spanning start:end is the sole content of a table cell
+func isOnlyParagraphInCell(xmlString string, start, end int) bool {
+	before := xmlString[:start]
+	opensCell := strings.HasSuffix(before, "</w:tcPr>") || cellStartSuffixRegex.MatchString(before)
+	return opensCell && strings.HasPrefix(xmlString[end:], "</w:tc>")
+}
+
+// controlTagsOnly returns the control tags of a paragraph or row if they are its only content
+func controlTagsOnly(element string) (string, bool) {
+	for _, marker := range nonTextContentMarkers {
+		if strings.Contains(element, marker) {
+			return "", false
+		}
+	}
+
+	var text strings.Builder
+	for _, match := range textContentRegex.FindAllStringSubmatch(element, -1) {
+		text.WriteString(match[1])
+	}
+
+	controlTags := blockControlTagRegex.FindAllString(text.String(), -1)
+	if len(controlTags) == 0 {
+		return "", false
+	}
+	remaining := blockControlTagRegex.ReplaceAllString(text.String(), "")
+	if strings.TrimSpace(remaining) != "" {
+		return "", false
+	}
+	return strings.Join(controlTags, ""), true
+}
@@ -0,0 +1,95 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceBlockParagraphs(t *testing.T) {
+	tests := []struct {
+		name              string
+		inputXml          string
+		expectedOutputXml string
+	}{
+		{
+			name:              "Range and end alone in paragraphs",
+			inputXml:          `<w:p><w:r><w:t>{{range .Items}}</w:t></w:r></w:p><w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `{{range .Items}}<w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p>{{end}}`,
+		},
+		{
+			name:              "Paragraph with attributes and properties",
+			inputXml:          `<w:p w:rsidR="00AB"><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> {{if .Show}} </w:t></w:r></w:p><w:tbl></w:tbl><w:p><w:r><w:t>{{- end -}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `{{if .Show}}<w:tbl></w:tbl>{{- end -}}`,
+		},
+		{
+			name:              "Else and multiple tags in one paragraph",
+			inputXml:          `<w:p><w:r><w:t>{{if .A}}{{with .B}}</w:t></w:r></w:p><w:p><w:r><w:t>{{else}}</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `{{if .A}}{{with .B}}{{else}}{{end}}{{end}}`,
+		},
+		{
+			name:              "Block ending within the text of a paragraph is left alone",
+			inputXml:          `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>Foo {{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>Foo {{end}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Else within the text of a paragraph leaves its block alone",
+			inputXml:          `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>Yes {{else}} No</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>Yes {{else}} No</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Blocks sharing a paragraph with an unbalanced block are left alone",
+			inputXml:          `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}{{range .B}}</w:t></w:r></w:p><w:p><w:r><w:t>{{.}} {{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}{{range .B}}</w:t></w:r></w:p><w:p><w:r><w:t>{{.}} {{end}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Balanced blocks around an unbalanced one",
+			inputXml:          `<w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p><w:p><w:r><w:t>{{range .B}}</w:t></w:r></w:p><w:p><w:r><w:t>{{.}}{{end}}</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `{{if .A}}<w:p><w:r><w:t>{{range .B}}</w:t></w:r></w:p><w:p><w:r><w:t>{{.}}{{end}}</w:t></w:r></w:p>{{end}}`,
+		},
+		{
+			name:              "Bare tags left by tables are alone",
+			inputXml:          `<w:p><w:r><w:t>{{range .Items}}</w:t></w:r></w:p>{{if .T}}<w:tbl></w:tbl>{{end}}<w:p><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `{{range .Items}}{{if .T}}<w:tbl></w:tbl>{{end}}{{end}}`,
+		},
+		{
+			name:              "Block tag with other text is left alone",
+			inputXml:          `<w:p><w:r><w:t>Items: {{range .Items}}{{.}} {{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:t>Items: {{range .Items}}{{.}} {{end}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Value placeholders are left alone",
+			inputXml:          `<w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Paragraph with a section break is left alone",
+			inputXml:          `<w:p><w:pPr><w:sectPr></w:sectPr></w:pPr><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:pPr><w:sectPr></w:sectPr></w:pPr><w:r><w:t>{{end}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Only paragraph of a table cell is left alone",
+			inputXml:          `<w:tc><w:tcPr></w:tcPr><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc>`,
+			expectedOutputXml: `<w:tc><w:tcPr></w:tcPr><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc>`,
+		},
+		{
+			name:              "Paragraphs inside a table cell",
+			inputXml:          `<w:tc><w:p><w:r><w:t>{{if .Show}}</w:t></w:r></w:p><w:p><w:r><w:t>Shown</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc>`,
+			expectedOutputXml: `<w:tc>{{if .Show}}<w:p><w:r><w:t>Shown</w:t></w:r></w:p>{{end}}</w:tc>`,
+		},
+		{
+			name:              "Text box paragraphs are left alone",
+			inputXml:          `<w:p><w:r><w:pict><w:txbxContent><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:txbxContent></w:pict></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:pict><w:txbxContent><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:txbxContent></w:pict></w:r></w:p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			outputXml := replaceBlockParagraphs(tt.inputXml)
+			assert.Equal(tt.expectedOutputXml, outputXml)
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package xmlutils
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// hrangeTagRegex matches the start of a column loop, e.g. {{hrange .Quarters}}
+var hrangeTagRegex = regexp.MustCompile(`\{\{-?\s*hrange\s+(.*?)\s*-?\}\}`)
+
+// hendTagRegex matches the end of a column loop
+var hendTagRegex = regexp.MustCompile(`\{\{-?\s*hend\s*-?\}\}`)
+
+// cellPropertiesRegex matches a cell start tag and its optional properties
+var cellPropertiesRegex = regexp.MustCompile(`(?s)^<w:tc(?:\s[^>]*)?>(<w:tcPr>.*?</w:tcPr>)?`)
+
+// Grid and width elements may be self-closing or have an explicit closing tag
+var (
+	tableGridRegex  = regexp.MustCompile(`(?s)<w:tblGrid>.*?</w:tblGrid>`)
+	gridColRegex    = regexp.MustCompile(`<w:gridCol\b[^>]*?(?:/>|>\s*</w:gridCol>)`)
+	cellWidthRegex  = regexp.MustCompile(`<w:tcW\b[^>]*?(?:/>|>\s*</w:tcW>)`)
+	widthAttrRegex  = regexp.MustCompile(`w:w="(\d+)"`)
+	gridSpanRegex   = regexp.MustCompile(`<w:gridSpan w:val="(\d+)"\s*(?:/>|>\s*</w:gridSpan>)`)
+	gridBeforeRegex = regexp.MustCompile(`<w:gridBefore w:val="(\d+)"\s*(?:/>|>\s*</w:gridBefore>)`)
+)
+
+// blockBoundaryRegex matches tags that open or close a template block
+var blockBoundaryRegex = regexp.MustCompile(`\{\{-?\s*(if|range|with|end)\b`)
+
+const (
+	columnWidthFuncName = "__docxtplColumnWidth"
+	gridSpanFuncName    = "__docxtplGridSpan"
+	columnsVarPrefix    = "$__docxtplColumns"
+)
+
+// TemplateFuncs returns the functions used by the template code generated
+// while preparing XML for tag replacement. They must be registered on every
+// template parsed from prepared XML.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		columnWidthFuncName: columnWidth,
+		gridSpanFuncName:    gridSpan,
+	}
+}
+
+// tableCell is a cell of a table row along with the grid columns it covers
+type tableCell struct {
+	start, end int
+	gridStart  int
+	gridSpan   int
+}
+
+// columnLoop is a run of cells repeated by {{hrange}} ... {{hend}}
+type columnLoop struct {
+	first, last        int // cell indexes
+	pipeline           string
+	gridStart, gridEnd int
+	variable           string // table-level variable holding the collection, set for loops that define the grid
+}
+
+type tableRow struct {
+	xml   string
+	cells []tableCell
+	loops []columnLoop
+}
+
+// replaceColumnLoops expands column loops in tables. Cells from the one containing
+// {{hrange .Items}} to the one containing {{hend}} are repeated for every item.
+// The first loop row outside any block defines the table grid: its columns are
+// repeated with their widths divided between the copies, and cells of other rows
+// spanning those columns have their gridSpan adjusted to match.
+func replaceColumnLoops(xmlString string) string {
+	if !hrangeTagRegex.MatchString(xmlString) {
+		return xmlString
+	}
+	counter := 0
+	return replaceColumnLoopsInTables(xmlString, &counter)
+}
+
+func replaceColumnLoopsInTables(xmlString string, counter *int) string {
+	return replaceElements(xmlString, "tbl", func(table string) string {
+		if !hrangeTagRegex.MatchString(table) {
+			return table
+		}
+
+		// Expand loops in nested tables first
+		table = replaceCellContents(table, func(content string) string {
+			return replaceColumnLoopsInTables(content, counter)
+		})
+
+		return expandTableColumnLoops(table, counter)
+	})
+}
+
+// expandTableColumnLoops expands the column loops in the rows of a single table
+func expandTableColumnLoops(table string, counter *int) string {
+	rowSpans := elementSpans(table, "tr")
+	if len(rowSpans) == 0 {
+		return table
+	}
+
+	rows := make([]tableRow, len(rowSpans))
+	gridRow := -1
+	for i, span := range rowSpans {
+		rows[i] = parseTableRow(table[span[0]:span[1]])
+		if gridRow < 0 && len(rows[i].loops) > 0 && !insideBlock(table[:span[0]]) {
+			gridRow = i
+		}
+	}
+
+	var gridLoops []columnLoop
+	if gridRow >= 0 {
+		for i := range rows[gridRow].loops {
+			rows[gridRow].loops[i].variable = columnsVarPrefix + strconv.Itoa(*counter)
+			*counter++
+		}
+		gridLoops = rows[gridRow].loops
+	}
+
+	var result strings.Builder
+	result.WriteString(expandTableGrid(table[:rowSpans[0][0]], gridLoops))
+	for i, row := range rows {
+		if i > 0 {
+			result.WriteString(table[rowSpans[i-1][1]:rowSpans[i][0]])
+		}
+		if len(row.loops) > 0 {
+			result.WriteString(expandRowLoops(row, gridLoops, i == gridRow))
+		} else {
+			result.WriteString(adjustRowSpans(row, gridLoops))
+		}
+	}
+	result.WriteString(table[rowSpans[len(rowSpans)-1][1]:])
+
+	return result.String()
+}
+
+// parseTableRow works out the grid columns of a row's cells and its column loops
+func parseTableRow(row string) tableRow {
+	parsed := tableRow{xml: row}
+
+	cellSpans := elementSpans(row, "tc")
+	gridColumn := 0
+	if len(cellSpans) > 0 {
+		if match := gridBeforeRegex.FindStringSubmatch(row[:cellSpans[0][0]]); match != nil {
+			gridColumn, _ = strconv.Atoi(match[1])
+		}
+	}
+
+	var current *columnLoop
+	for i, span := range cellSpans {
+		cell := row[span[0]:span[1]]
+		columns := 1
+		if properties := cellPropertiesRegex.FindStringSubmatch(cell); properties != nil {
+			if match := gridSpanRegex.FindStringSubmatch(properties[1]); match != nil {
+				columns, _ = strconv.Atoi(match[1])
+			}
+		}
+		parsed.cells = append(parsed.cells, tableCell{start: span[0], end: span[1], gridStart: gridColumn, gridSpan: columns})
+
+		if current == nil {
+			if match := hrangeTagRegex.FindStringSubmatch(cell); match != nil {
+				current = &columnLoop{first: i, pipeline: match[1], gridStart: gridColumn}
+			}
+		}
+		gridColumn += columns
+
+		if current != nil && hendTagRegex.MatchString(cell) {
+			current.last = i
+			current.gridEnd = gridColumn
+			parsed.loops = append(parsed.loops, *current)
+			current = nil
+		}
+	}
+
+	return parsed
+}
+
+// expandTableGrid declares the loop collections at table level and repeats the loop columns of the grid
+func expandTableGrid(tableStart string, gridLoops []columnLoop) string {
+	if len(gridLoops) == 0 {
+		return tableStart
+	}
+
+	tableStart = tableGridRegex.ReplaceAllStringFunc(tableStart, func(grid string) string {
+		columns := gridColRegex.FindAllStringIndex(grid, -1)
+		if gridLoops[len(gridLoops)-1].gridEnd > len(columns) {
+			// The grid doesn't match the rows, leave it for Word to fix
+			return grid
+		}
+
+		var result strings.Builder
+		lastEnd := 0
+		for _, loop := range gridLoops {
+			first, last := columns[loop.gridStart], columns[loop.gridEnd-1]
+			result.WriteString(grid[lastEnd:first[0]])
+			result.WriteString("{{range " + loop.variable + "}}")
+			result.WriteString(scaleWidths(grid[first[0]:last[1]], gridColRegex, loop.variable))
+			result.WriteString("{{end}}")
+			lastEnd = last[1]
+		}
+		result.WriteString(grid[lastEnd:])
+		return result.String()
+	})
+
+	var declarations strings.Builder
+	for _, loop := range gridLoops {
+		declarations.WriteString("{{" + loop.variable + " := " + loopCollection(loop.pipeline) + "}}")
+	}
+	openEnd := strings.IndexByte(tableStart, '>') + 1
+	return tableStart[:openEnd] + declarations.String() + tableStart[openEnd:]
+}
+
+// expandRowLoops wraps the loop cells of a row in range actions
+func expandRowLoops(row tableRow, gridLoops []columnLoop, definesGrid bool) string {
+	var result strings.Builder
+	result.WriteString(row.xml[:row.cells[0].start])
+
+	loopIndex := 0
+	for i, cell := range row.cells {
+		if i > 0 {
+			result.WriteString(row.xml[row.cells[i-1].end:cell.start])
+		}
+
+		cellXml := row.xml[cell.start:cell.end]
+		if loopIndex >= len(row.loops) || i < row.loops[loopIndex].first {
+			result.WriteString(cellXml)
+			continue
+		}
+
+		loop := row.loops[loopIndex]
+		if i == loop.first {
+			if definesGrid {
+				result.WriteString("{{range " + loopDeclaration(loop.pipeline) + loop.variable + "}}")
+			} else {
+				result.WriteString("{{range " + loop.pipeline + "}}")
+			}
+		}
+
+		cellXml = hrangeTagRegex.ReplaceAllString(cellXml, "")
+		cellXml = hendTagRegex.ReplaceAllString(cellXml, "")
+		if loopIndex < len(gridLoops) {
+			cellXml = scaleWidths(cellXml, cellWidthRegex, gridLoops[loopIndex].variable)
+		}
+		result.WriteString(cellXml)
+
+		if i == loop.last {
+			result.WriteString("{{end}}")
+			loopIndex++
+		}
+	}
+
+	result.WriteString(row.xml[row.cells[len(row.cells)-1].end:])
+	return result.String()
+}
+
+// adjustRowSpans widens cells that span loop columns so they keep covering all of their copies.
+// Cells that only cover loop columns are dropped when the loops are empty.
+func adjustRowSpans(row tableRow, gridLoops []columnLoop) string {
+	if len(gridLoops) == 0 || len(row.cells) == 0 {
+		return row.xml
+	}
+
+	var result strings.Builder
+	result.WriteString(row.xml[:row.cells[0].start])
+
+	for i, cell := range row.cells {
+		if i > 0 {
+			result.WriteString(row.xml[row.cells[i-1].end:cell.start])
+		}
+		cellXml := row.xml[cell.start:cell.end]
+
+		base := cell.gridSpan
+		var spanArgs, variables []string
+		for _, loop := range gridLoops {
+			overlap := min(cell.gridStart+cell.gridSpan, loop.gridEnd) - max(cell.gridStart, loop.gridStart)
+			if overlap <= 0 {
+				continue
+			}
+			base -= overlap
+			spanArgs = append(spanArgs, strconv.Itoa(overlap), loop.variable)
+			variables = append(variables, loop.variable)
+		}
+		if len(spanArgs) == 0 {
+			result.WriteString(cellXml)
+			continue
+		}
+
+		cellXml = setGridSpan(cellXml, fmt.Sprintf("{{%s %d %s}}", gridSpanFuncName, base, strings.Join(spanArgs, " ")))
+		if base > 0 {
+			result.WriteString(cellXml)
+			continue
+		}
+
+		condition := variables[0]
+		if len(variables) > 1 {
+			condition = "or " + strings.Join(variables, " ")
+		}
+		result.WriteString("{{if " + condition + "}}" + cellXml + "{{end}}")
+	}
+
+	result.WriteString(row.xml[row.cells[len(row.cells)-1].end:])
+	return result.String()
+}
+
+// setGridSpan sets the gridSpan of a cell, adding cell properties if needed
+func setGridSpan(cell string, value string) string {
+	gridSpan := `<w:gridSpan w:val="` + value + `"/>`
+
+	properties := cellPropertiesRegex.FindStringSubmatchIndex(cell)
+	if properties == nil {
+		return cell
+	}
+	if properties[2] < 0 {
+		return cell[:properties[1]] + "<w:tcPr>" + gridSpan + "</w:tcPr>" + cell[properties[1]:]
+	}
+
+	tcPr := cell[properties[2]:properties[3]]
+	if gridSpanRegex.MatchString(tcPr) {
+		tcPr = gridSpanRegex.ReplaceAllLiteralString(tcPr, gridSpan)
+	} else if width := cellWidthRegex.FindStringIndex(tcPr); width != nil {
+		// gridSpan follows tcW in the schema
+		tcPr = tcPr[:width[1]] + gridSpan + tcPr[width[1]:]
+	} else {
+		tcPr = "<w:tcPr>" + gridSpan + strings.TrimPrefix(tcPr, "<w:tcPr>")
+	}
+	return cell[:properties[2]] + tcPr + cell[properties[3]:]
+}
+
+// scaleWidths divides the widths of the matched elements between the items of a loop
+func scaleWidths(xmlString string, elementRegex *regexp.Regexp, variable string) string {
+	return elementRegex.ReplaceAllStringFunc(xmlString, func(element string) string {
+		return widthAttrRegex.ReplaceAllStringFunc(element, func(width string) string {
+			value := widthAttrRegex.FindStringSubmatch(width)[1]
+			return fmt.Sprintf(`w:w="{{%s %s %s}}"`, columnWidthFuncName, value, variable)
+		})
+	})
+}
+
+// loopCollection returns the collection part of a range pipeline such as "$i, $q := .Quarters"
+func loopCollection(pipeline string) string {
+	if idx := strings.Index(pipeline, ":="); idx >= 0 {
+		return strings.TrimSpace(pipeline[idx+2:])
+	}
+	return pipeline
+}
+
+// loopDeclaration returns the variable declaration of a range pipeline, including the ":=", if any
+func loopDeclaration(pipeline string) string {
+	if idx := strings.Index(pipeline, ":="); idx >= 0 {
+		return strings.TrimSpace(pipeline[:idx]) + " := "
+	}
+	return ""
+}
+
+// insideBlock reports whether the end of the XML is inside an unclosed template block
+func insideBlock(xmlString string) bool {
+	depth := 0
+	for _, match := range blockBoundaryRegex.FindAllStringSubmatch(xmlString, -1) {
+		if match[1] == "end" {
+			depth--
+		} else {
+			depth++
+		}
+	}
+	return depth > 0
+}
+
+// columnWidth divides a width between the items of a column loop
+func columnWidth(width int, items any) int {
+	if n := collectionLength(items); n > 0 {
+		return width / n
+	}
+	return width
+}
+
+// gridSpan computes the span of a cell covering loop columns. The arguments
+// after the fixed span are pairs of loop column counts and loop collections.
+func gridSpan(base int, loops ...any) (int, error) {
+	if len(loops)%2 != 0 {
+		return 0, fmt.Errorf("gridSpan expects pairs of column counts and collections")
+	}
+	span := base
+	for i := 0; i < len(loops); i += 2 {
+		columns, ok := loops[i].(int)
+		if !ok {
+			return 0, fmt.Errorf("invalid column count %v", loops[i])
+		}
+		span += columns * collectionLength(loops[i+1])
+	}
+	return max(span, 1), nil
+}
+
+// collectionLength returns the number of iterations a range over the value would make
+func collectionLength(items any) int {
+	v := reflect.ValueOf(items)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Chan:
+		return v.Len()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return max(int(v.Int()), 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint())
+	}
+	return 0
+}
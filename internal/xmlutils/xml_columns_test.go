@@ -0,0 +1,97 @@
+package xmlutils
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceColumnLoops(t *testing.T) {
+	tests := []struct {
+		name              string
+		inputXml          string
+		expectedOutputXml string
+	}{
+		{
+			name:              "No column loops",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+		},
+		{
+			name:              "Loop in a single cell",
+			inputXml:          `<w:tbl><w:tblGrid><w:gridCol w:w="1000"/><w:gridCol w:w="3000"/></w:tblGrid><w:tr><w:tc><w:p/></w:tc><w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr><w:p><w:r><w:t>{{hrange .Quarters}}{{.}}{{hend}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl>{{$__docxtplColumns0 := .Quarters}}<w:tblGrid><w:gridCol w:w="1000"/>{{range $__docxtplColumns0}}<w:gridCol w:w="{{__docxtplColumnWidth 3000 $__docxtplColumns0}}"/>{{end}}</w:tblGrid><w:tr><w:tc><w:p/></w:tc>{{range $__docxtplColumns0}}<w:tc><w:tcPr><w:tcW w:w="{{__docxtplColumnWidth 3000 $__docxtplColumns0}}" w:type="dxa"/></w:tcPr><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc>{{end}}</w:tr></w:tbl>`,
+		},
+		{
+			name:              "Loop across cells with variables",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{hrange $i, $q := .Quarters}}{{$q}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{{$i}}{{hend}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl>{{$__docxtplColumns0 := .Quarters}}<w:tr>{{range $i, $q := $__docxtplColumns0}}<w:tc><w:p><w:r><w:t>{{$q}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{{$i}}</w:t></w:r></w:p></w:tc>{{end}}</w:tr></w:tbl>`,
+		},
+		{
+			name:              "Header cells spanning the loop columns",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p/></w:tc><w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr><w:p/></w:tc></w:tr><w:tr><w:tc><w:p/></w:tc><w:tc><w:p><w:r><w:t>{{hrange .Quarters}}{{.}}{{hend}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl>{{$__docxtplColumns0 := .Quarters}}<w:tr><w:tc><w:p/></w:tc>{{if $__docxtplColumns0}}<w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/><w:gridSpan w:val="{{__docxtplGridSpan 0 1 $__docxtplColumns0}}"/></w:tcPr><w:p/></w:tc>{{end}}</w:tr><w:tr><w:tc><w:p/></w:tc>{{range $__docxtplColumns0}}<w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc>{{end}}</w:tr></w:tbl>`,
+		},
+		{
+			name:              "Loop rows inside a range use their own collection",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{hrange .Quarters}}{{.}}{{hend}}</w:t></w:r></w:p></w:tc></w:tr>{{range .Lines}}<w:tr><w:tc><w:p><w:r><w:t>{{hrange .Values}}{{.}}{{hend}}</w:t></w:r></w:p></w:tc></w:tr>{{end}}</w:tbl>`,
+			expectedOutputXml: `<w:tbl>{{$__docxtplColumns0 := .Quarters}}<w:tr>{{range $__docxtplColumns0}}<w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc>{{end}}</w:tr>{{range .Lines}}<w:tr>{{range .Values}}<w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc>{{end}}</w:tr>{{end}}</w:tbl>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			outputXml := replaceColumnLoops(tt.inputXml)
+			assert.Equal(tt.expectedOutputXml, outputXml)
+		})
+	}
+}
+
+func TestColumnLoopExecution(t *testing.T) {
+	inputXml := `<w:tbl><w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="3000"/><w:gridCol w:w="1000"/></w:tblGrid>` +
+		`<w:tr><w:tc><w:p/></w:tc><w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p/></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:p/></w:tc><w:tc><w:p><w:r><w:t>{{hrange .Quarters}}{{.}}{{hend}}</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr></w:tbl>`
+
+	render := func(data map[string]any) string {
+		tmpl, err := template.New("").Funcs(TemplateFuncs()).Parse(replaceColumnLoops(inputXml))
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+		require.NoError(t, tmpl.Execute(buf, data))
+		return buf.String()
+	}
+
+	t.Run("Should repeat cells and grid columns and widen spanning cells", func(t *testing.T) {
+		output := render(map[string]any{"Quarters": []string{"Q1", "Q2", "Q3"}})
+		assert.Contains(t, output, `<w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="1000"/><w:gridCol w:w="1000"/><w:gridCol w:w="1000"/><w:gridCol w:w="1000"/></w:tblGrid>`)
+		assert.Contains(t, output, `<w:gridSpan w:val="4"/>`)
+		assert.Equal(t, 3, bytes.Count([]byte(output), []byte(`<w:t>Q`)))
+	})
+
+	t.Run("Should drop the loop columns when the collection is empty", func(t *testing.T) {
+		output := render(map[string]any{"Quarters": []string{}})
+		assert.Contains(t, output, `<w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="1000"/></w:tblGrid>`)
+		assert.Contains(t, output, `<w:gridSpan w:val="1"/>`)
+	})
+}
+
+func TestColumnLoopFunctions(t *testing.T) {
+	assert.Equal(t, 1000, columnWidth(3000, []string{"a", "b", "c"}))
+	assert.Equal(t, 3000, columnWidth(3000, nil))
+	assert.Equal(t, 750, columnWidth(3000, 4))
+
+	span, err := gridSpan(1, 2, []any{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, span)
+
+	span, err = gridSpan(0, 1, map[string]int{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, span)
+
+	_, err = gridSpan(0, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package xmlutils
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EmptyNumbering is a numbering part without definitions, used when a document
+// without lists receives numbering definitions
+const EmptyNumbering = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+	`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"></w:numbering>`
+
+var (
+	// styleRegex matches a style definition, capturing its id
+	styleRegex = regexp.MustCompile(`(?s)<w:style\b[^>]*?w:styleId="([^"]*)"[^>]*?(?:/>|>.*?</w:style>)`)
+	// styleIDAttributeRegex matches the id attribute of a style definition
+	styleIDAttributeRegex = regexp.MustCompile(`w:styleId="([^"]*)"`)
+	// styleReferenceRegex matches the paragraph, run and table styles applied in content
+	styleReferenceRegex = regexp.MustCompile(`<w:(?:pStyle|rStyle|tblStyle) w:val="([^"]*)"`)
+	// styleDependencyRegex matches the styles a style definition refers to
+	styleDependencyRegex = regexp.MustCompile(`<w:(?:basedOn|link|next) w:val="([^"]*)"`)
+
+	// abstractNumRegex matches an abstract numbering definition, capturing its id
+	abstractNumRegex = regexp.MustCompile(`(?s)<w:abstractNum\b[^>]*?w:abstractNumId="(\d+)".*?</w:abstractNum>`)
+	// numRegex matches a numbering instance, capturing its id
+	numRegex = regexp.MustCompile(`(?s)<w:num\b[^>]*?w:numId="(\d+)".*?</w:num>`)
+	// abstractNumIDAttributeRegex matches the id attribute of an abstract numbering definition
+	abstractNumIDAttributeRegex = regexp.MustCompile(`w:abstractNumId="(\d+)"`)
+	// numIDAttributeRegex matches the id attribute of a numbering instance
+	numIDAttributeRegex = regexp.MustCompile(`w:numId="(\d+)"`)
+	// abstractNumReferenceRegex matches the abstract definition used by a numbering instance
+	abstractNumReferenceRegex = regexp.MustCompile(`<w:abstractNumId w:val="(\d+)"`)
+	// numReferenceRegex matches the numbering instance applied in content
+	numReferenceRegex = regexp.MustCompile(`<w:numId w:val="(\d+)"`)
+
+	// namespaceRegex matches a namespace declaration, capturing its prefix
+	namespaceRegex = regexp.MustCompile(`\sxmlns:(\w+)="[^"]*"`)
+	// partRootRegex matches the start tag of a styles or numbering part's root element
+	partRootRegex = regexp.MustCompile(`<w:(?:styles|numbering)\b[^>]*>`)
+)
+
+// StyleIDs returns the ids of the paragraph, run and table styles applied in
+// the XML, in order of first use
+func StyleIDs(xmlString string) []string {
+	return uniqueSubmatches(styleReferenceRegex, xmlString)
+}
+
+// NumberingIDs returns the ids of the numbering instances applied in the XML,
+// in order of first use. The id 0, which removes numbering, is left out.
+func NumberingIDs(xmlString string) []string {
+	ids := uniqueSubmatches(numReferenceRegex, xmlString)
+	return slices.DeleteFunc(ids, func(id string) bool { return id == "0" })
+}
+
+// MissingStyles returns the definitions in the source styles part of the given
+// styles, and of the styles they're based on, linked to or followed by, that
+// aren't defined in the target styles part. Styles defined in both keep the
+// target's definition.
+func MissingStyles(target, source string, styleIDs []string) []string {
+	defined := make(map[string]bool)
+	for _, match := range styleIDAttributeRegex.FindAllStringSubmatch(target, -1) {
+		defined[match[1]] = true
+	}
+
+	definitions := make(map[string]string)
+	for _, match := range styleRegex.FindAllStringSubmatch(source, -1) {
+		definitions[match[1]] = match[0]
+	}
+
+	var styles []string
+	pending := slices.Clone(styleIDs)
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
+		definition, ok := definitions[id]
+		if defined[id] || !ok {
+			continue
+		}
+		defined[id] = true
+		styles = append(styles, definition)
+		for _, match := range styleDependencyRegex.FindAllStringSubmatch(definition, -1) {
+			pending = append(pending, match[1])
+		}
+	}
+	return styles
+}
+
+// AddStyles adds style definitions at the end of the target styles part,
+// declaring the namespaces of the source part that the target lacks
+func AddStyles(target, source string, styles []string) string {
+	if len(styles) == 0 {
+		return target
+	}
+	target = mergeNamespaces(target, source)
+	return insertBefore(target, "</w:styles>", strings.Join(styles, ""))
+}
+
+// MergeNumbering adds the source definitions of the given numbering instances,
+// and of the abstract definitions they use, to the target numbering part under
+// ids that don't clash with the target's. It returns the merged part and the new
+// id of each instance, for use with RemapNumberingIDs.
+func MergeNumbering(target, source string, numIDs []string) (string, map[string]string) {
+	nextNum := maxSubmatch(numIDAttributeRegex, target) + 1
+	nextAbstract := maxSubmatch(abstractNumIDAttributeRegex, target) + 1
+
+	nums := make(map[string]string)
+	for _, match := range numRegex.FindAllStringSubmatch(source, -1) {
+		nums[match[1]] = match[0]
+	}
+	abstracts := make(map[string]string)
+	for _, match := range abstractNumRegex.FindAllStringSubmatch(source, -1) {
+		abstracts[match[1]] = match[0]
+	}
+
+	ids := make(map[string]string)
+	abstractIDs := make(map[string]string)
+	var newAbstracts, newNums strings.Builder
+	for _, id := range numIDs {
+		num, ok := nums[id]
+		if _, done := ids[id]; done || !ok {
+			continue
+		}
+		reference := abstractNumReferenceRegex.FindStringSubmatch(num)
+		if reference == nil {
+			continue
+		}
+		abstract, ok := abstracts[reference[1]]
+		if !ok {
+			continue
+		}
+
+		abstractID, done := abstractIDs[reference[1]]
+		if !done {
+			abstractID = strconv.Itoa(nextAbstract)
+			nextAbstract++
+			abstractIDs[reference[1]] = abstractID
+			newAbstracts.WriteString(replaceFirst(abstractNumIDAttributeRegex, abstract, `w:abstractNumId="`+abstractID+`"`))
+		}
+
+		ids[id] = strconv.Itoa(nextNum)
+		nextNum++
+		num = replaceFirst(numIDAttributeRegex, num, `w:numId="`+ids[id]+`"`)
+		num = replaceFirst(abstractNumReferenceRegex, num, `<w:abstractNumId w:val="`+abstractID+`"`)
+		newNums.WriteString(num)
+	}
+
+	if len(ids) == 0 {
+		return target, ids
+	}
+
+	// Abstract definitions come before all numbering instances
+	target = mergeNamespaces(target, source)
+	if location := numRegex.FindStringIndex(target); location != nil {
+		target = target[:location[0]] + newAbstracts.String() + target[location[0]:]
+	} else {
+		target = insertBefore(target, "</w:numbering>", newAbstracts.String())
+	}
+	if strings.Contains(target, "<w:numIdMacAtCleanup") {
+		target = insertBefore(target, "<w:numIdMacAtCleanup", newNums.String())
+	} else {
+		target = insertBefore(target, "</w:numbering>", newNums.String())
+	}
+	return target, ids
+}
+
+// RemapNumberingIDs replaces the numbering instances applied in the XML using
+// the ids returned by MergeNumbering
+func RemapNumberingIDs(xmlString string, ids map[string]string) string {
+	if len(ids) == 0 {
+		return xmlString
+	}
+	return numReferenceRegex.ReplaceAllStringFunc(xmlString, func(reference string) string {
+		id := numReferenceRegex.FindStringSubmatch(reference)[1]
+		if newID, ok := ids[id]; ok {
+			return `<w:numId w:val="` + newID + `"`
+		}
+		return reference
+	})
+}
+
+// mergeNamespaces declares the namespaces of the source part's root element
+// on the target part's root element when the target lacks them
+func mergeNamespaces(target, source string) string {
+	targetRoot := partRootRegex.FindStringIndex(target)
+	sourceRoot := partRootRegex.FindString(source)
+	if targetRoot == nil || sourceRoot == "" {
+		return target
+	}
+
+	rootTag := target[targetRoot[0]:targetRoot[1]]
+	var missing strings.Builder
+	for _, match := range namespaceRegex.FindAllStringSubmatch(sourceRoot, -1) {
+		if !strings.Contains(rootTag, " xmlns:"+match[1]+"=") {
+			missing.WriteString(match[0])
+		}
+	}
+	if missing.Len() == 0 {
+		return target
+	}
+
+	end := targetRoot[1] - 1
+	if strings.HasSuffix(rootTag, "/>") {
+		end--
+	}
+	return target[:end] + missing.String() + target[end:]
+}
+
+// insertBefore inserts content before the last occurrence of marker
+func insertBefore(xmlString, marker, content string) string {
+	index := strings.LastIndex(xmlString, marker)
+	if index < 0 {
+		return xmlString
+	}
+	return xmlString[:index] + content + xmlString[index:]
+}
+
+// replaceFirst replaces the first match of the regex
+func replaceFirst(regex *regexp.Regexp, xmlString, replacement string) string {
+	location := regex.FindStringIndex(xmlString)
+	if location == nil {
+		return xmlString
+	}
+	return xmlString[:location[0]] + replacement + xmlString[location[1]:]
+}
+
+// uniqueSubmatches returns the distinct first submatches of the regex in order of appearance
+func uniqueSubmatches(regex *regexp.Regexp, xmlString string) []string {
+	var values []string
+	for _, match := range regex.FindAllStringSubmatch(xmlString, -1) {
+		if !slices.Contains(values, match[1]) {
+			values = append(values, match[1])
+		}
+	}
+	return values
+}
+
+// maxSubmatch returns the largest numeric first submatch of the regex, or 0 without matches
+func maxSubmatch(regex *regexp.Regexp, xmlString string) int {
+	largest := 0
+	for _, match := range regex.FindAllStringSubmatch(xmlString, -1) {
+		if value, err := strconv.Atoi(match[1]); err == nil {
+			largest = max(largest, value)
+		}
+	}
+	return largest
+}
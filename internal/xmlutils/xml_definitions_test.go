@@ -0,0 +1,91 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStyleAndNumberingIDs(t *testing.T) {
+	content := `<w:p><w:pPr><w:pStyle w:val="Quote"/><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr></w:pPr>` +
+		`<w:r><w:rPr><w:rStyle w:val="Strong"/></w:rPr></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Quote"/><w:numPr><w:numId w:val="0"/></w:numPr></w:pPr></w:p>` +
+		`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/></w:tblPr></w:tbl>`
+
+	assert.Equal(t, []string{"Quote", "Strong", "TableGrid"}, StyleIDs(content))
+	assert.Equal(t, []string{"3"}, NumberingIDs(content))
+}
+
+func TestMissingStyles(t *testing.T) {
+	target := `<w:styles><w:style w:type="paragraph" w:styleId="Normal"><w:name w:val="Normal"/></w:style></w:styles>`
+	source := `<w:styles>` +
+		`<w:style w:type="paragraph" w:styleId="Normal"><w:name w:val="Normal"/><w:rPr><w:b/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Quote"><w:basedOn w:val="Normal"/><w:link w:val="QuoteChar"/><w:next w:val="Body"/></w:style>` +
+		`<w:style w:type="character" w:styleId="QuoteChar"><w:link w:val="Quote"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Body"/>` +
+		`<w:style w:type="paragraph" w:styleId="Unused"></w:style>` +
+		`</w:styles>`
+
+	assert.Equal(t, []string{
+		`<w:style w:type="paragraph" w:styleId="Quote"><w:basedOn w:val="Normal"/><w:link w:val="QuoteChar"/><w:next w:val="Body"/></w:style>`,
+		`<w:style w:type="character" w:styleId="QuoteChar"><w:link w:val="Quote"/></w:style>`,
+		`<w:style w:type="paragraph" w:styleId="Body"/>`,
+	}, MissingStyles(target, source, []string{"Quote", "Normal", "Undefined"}))
+}
+
+func TestAddStyles(t *testing.T) {
+	target := `<w:styles xmlns:w="main"><w:style w:styleId="Normal"/></w:styles>`
+	source := `<w:styles xmlns:w="main" xmlns:w14="wordml"></w:styles>`
+
+	assert.Equal(t,
+		`<w:styles xmlns:w="main" xmlns:w14="wordml"><w:style w:styleId="Normal"/><w:style w:styleId="Quote"/></w:styles>`,
+		AddStyles(target, source, []string{`<w:style w:styleId="Quote"/>`}))
+	assert.Equal(t, target, AddStyles(target, source, nil))
+}
+
+func TestMergeNumbering(t *testing.T) {
+	source := `<w:numbering xmlns:w="main">` +
+		`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"/></w:abstractNum>` +
+		`<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"/></w:abstractNum>` +
+		`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>` +
+		`<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>` +
+		`<w:num w:numId="3"><w:abstractNumId w:val="0"/><w:lvlOverride w:ilvl="0"/></w:num>` +
+		`</w:numbering>`
+
+	t.Run("Should add definitions under ids after the target's", func(t *testing.T) {
+		target := `<w:numbering xmlns:w="main">` +
+			`<w:abstractNum w:abstractNumId="4"><w:lvl w:ilvl="0"/></w:abstractNum>` +
+			`<w:num w:numId="7"><w:abstractNumId w:val="4"/></w:num>` +
+			`</w:numbering>`
+
+		merged, ids := MergeNumbering(target, source, []string{"3", "1", "9"})
+		assert.Equal(t, map[string]string{"3": "8", "1": "9"}, ids)
+		assert.Equal(t, `<w:numbering xmlns:w="main">`+
+			`<w:abstractNum w:abstractNumId="4"><w:lvl w:ilvl="0"/></w:abstractNum>`+
+			`<w:abstractNum w:abstractNumId="5"><w:lvl w:ilvl="0"/></w:abstractNum>`+
+			`<w:num w:numId="7"><w:abstractNumId w:val="4"/></w:num>`+
+			`<w:num w:numId="8"><w:abstractNumId w:val="5"/><w:lvlOverride w:ilvl="0"/></w:num>`+
+			`<w:num w:numId="9"><w:abstractNumId w:val="5"/></w:num>`+
+			`</w:numbering>`, merged)
+	})
+
+	t.Run("Should fill an empty numbering part", func(t *testing.T) {
+		merged, ids := MergeNumbering(EmptyNumbering, source, []string{"2"})
+		assert.Equal(t, map[string]string{"2": "1"}, ids)
+		assert.Contains(t, merged, `<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"/></w:abstractNum><w:num w:numId="1"><w:abstractNumId w:val="1"/></w:num></w:numbering>`)
+	})
+
+	t.Run("Should leave the target unchanged without definitions to add", func(t *testing.T) {
+		merged, ids := MergeNumbering(EmptyNumbering, source, []string{"5"})
+		assert.Empty(t, ids)
+		assert.Equal(t, EmptyNumbering, merged)
+	})
+}
+
+func TestRemapNumberingIDs(t *testing.T) {
+	content := `<w:numPr><w:numId w:val="1"/></w:numPr><w:numPr><w:numId w:val="2"/></w:numPr><w:numPr><w:numId w:val="0"/></w:numPr>`
+
+	assert.Equal(t,
+		`<w:numPr><w:numId w:val="5"/></w:numPr><w:numPr><w:numId w:val="2"/></w:numPr><w:numPr><w:numId w:val="0"/></w:numPr>`,
+		RemapNumberingIDs(content, map[string]string{"1": "5"}))
+}
@@ -0,0 +1,203 @@
+package xmlutils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Delimiters are the markers around template tags in a document. The zero
+// value stands for the standard {{ and }}.
+//
+// Documents with other delimiters are translated before processing: their tags
+// get the standard delimiters and literal braces in the text are swapped for
+// private use characters, so the rest of the pipeline only ever sees its own
+// tags. RestoreBraces undoes the translation in the rendered output and Restore
+// in tags reported to the user.
+type Delimiters struct {
+	Left, Right string
+}
+
+const (
+	// openBrace and closeBrace stand in for literal braces while a document
+	// with custom delimiters is processed
+	openBrace  = "\uE000"
+	closeBrace = "\uE001"
+)
+
+var (
+	// delimitedTextRegex matches the text of a run and the end of a paragraph,
+	// where tags split across runs stop
+	delimitedTextRegex = regexp.MustCompile(`(<w:t(?:\s[^>]*)?>)([^<]*)</w:t>|</w:p>`)
+	// delimitedAttributesRegex matches the elements whose attributes can hold
+	// tags: picture properties and watermark text
+	delimitedAttributesRegex = regexp.MustCompile(`<(?:wp:docPr|pic:cNvPr|v:textpath)\b[^>]*>`)
+	// attributeValueRegex matches the values of the attributes of a start tag
+	attributeValueRegex = regexp.MustCompile(`(\s[\w:]+=")([^"]*)(")`)
+	// elementTextRegex matches the text between two XML tags
+	elementTextRegex = regexp.MustCompile(`>([^<]+)<`)
+)
+
+// IsDefault reports whether the delimiters are the standard {{ and }}.
+func (d Delimiters) IsDefault() bool {
+	return (d.Left == "" || d.Left == "{{") && (d.Right == "" || d.Right == "}}")
+}
+
+// Restore converts tags and messages about tags of a translated document back
+// to the document's delimiters and literal braces.
+func (d Delimiters) Restore(text string) string {
+	if d.IsDefault() {
+		return text
+	}
+	return RestoreBraces(strings.NewReplacer("{{", d.Left, "}}", d.Right).Replace(text))
+}
+
+// RestoreBraces restores the literal braces of a translated document in its
+// rendered output.
+func RestoreBraces(text string) string {
+	if !strings.Contains(text, openBrace) && !strings.Contains(text, closeBrace) {
+		return text
+	}
+	return strings.NewReplacer(openBrace, "{", closeBrace, "}").Replace(text)
+}
+
+// TranslateDelimiters translates the tags of a WordprocessingML part to the
+// standard delimiters. Delimiters split across the runs of a paragraph are
+// found and the picture and watermark attributes that can hold tags are
+// translated too.
+func TranslateDelimiters(xmlString string, delims Delimiters) string {
+	if delims.IsDefault() {
+		return xmlString
+	}
+
+	var result strings.Builder
+	var paragraph [][]int // locations of the text of the paragraph's runs
+	last := 0
+	flush := func() {
+		texts := make([]string, len(paragraph))
+		for i, match := range paragraph {
+			texts[i] = xmlString[match[4]:match[5]]
+		}
+		for i, text := range delims.translate(texts, escapeText) {
+			result.WriteString(xmlString[last:paragraph[i][4]])
+			result.WriteString(text)
+			last = paragraph[i][5]
+		}
+		paragraph = paragraph[:0]
+	}
+
+	for _, match := range delimitedTextRegex.FindAllStringSubmatchIndex(xmlString, -1) {
+		if match[2] < 0 {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, match)
+	}
+	flush()
+	result.WriteString(xmlString[last:])
+
+	return delimitedAttributesRegex.ReplaceAllStringFunc(result.String(), func(tag string) string {
+		return attributeValueRegex.ReplaceAllStringFunc(tag, func(attribute string) string {
+			parts := attributeValueRegex.FindStringSubmatch(attribute)
+			return parts[1] + delims.translate([]string{parts[2]}, escapeAttribute)[0] + parts[3]
+		})
+	})
+}
+
+// TranslatePropertiesDelimiters translates the tags in the text of the
+// elements of a document properties part to the standard delimiters.
+func TranslatePropertiesDelimiters(xmlString string, delims Delimiters) string {
+	if delims.IsDefault() {
+		return xmlString
+	}
+	return elementTextRegex.ReplaceAllStringFunc(xmlString, func(element string) string {
+		text := element[1 : len(element)-1]
+		return ">" + delims.translate([]string{text}, escapeText)[0] + "<"
+	})
+}
+
+// TranslateTextDelimiters translates the tags of plain text to the standard
+// delimiters.
+func TranslateTextDelimiters(text string, delims Delimiters) string {
+	if delims.IsDefault() {
+		return text
+	}
+	return delims.translateDecoded([]string{text})[0]
+}
+
+// translate translates the XML escaped texts, which are read as one text so
+// tags can span them, escaping the texts it changes with escape
+func (d Delimiters) translate(texts []string, escape func(string) string) []string {
+	decoded := make([]string, len(texts))
+	for i, text := range texts {
+		decoded[i] = html.UnescapeString(text)
+	}
+
+	translated := d.translateDecoded(decoded)
+	for i := range translated {
+		if translated[i] == decoded[i] {
+			translated[i] = texts[i]
+		} else {
+			translated[i] = escape(translated[i])
+		}
+	}
+	return translated
+}
+
+// translateDecoded translates texts that are read as one text. A delimiter
+// split across texts is written in the text where it starts.
+func (d Delimiters) translateDecoded(texts []string) []string {
+	left, right := d.Left, d.Right
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+
+	text := strings.Join(texts, "")
+	results := make([]strings.Builder, len(texts))
+	segment, segmentEnd := 0, 0
+	if len(texts) > 0 {
+		segmentEnd = len(texts[0])
+	}
+
+	inTag := false
+	for i := 0; i < len(text); {
+		for i >= segmentEnd && segment < len(texts)-1 {
+			segment++
+			segmentEnd += len(texts[segment])
+		}
+
+		switch {
+		case !inTag && strings.HasPrefix(text[i:], left):
+			results[segment].WriteString("{{")
+			i += len(left)
+			inTag = true
+		case inTag && strings.HasPrefix(text[i:], right):
+			results[segment].WriteString("}}")
+			i += len(right)
+			inTag = false
+		case text[i] == '{':
+			results[segment].WriteString(openBrace)
+			i++
+		case text[i] == '}':
+			results[segment].WriteString(closeBrace)
+			i++
+		default:
+			results[segment].WriteByte(text[i])
+			i++
+		}
+	}
+
+	translated := make([]string, len(texts))
+	for i := range results {
+		translated[i] = results[i].String()
+	}
+	return translated
+}
+
+// escapeText escapes the characters that can't appear in XML text
+func escapeText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,76 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateDelimiters(t *testing.T) {
+	brackets := Delimiters{Left: "[[", Right: "]]"}
+
+	tests := []struct {
+		name     string
+		delims   Delimiters
+		input    string
+		expected string
+	}{
+		{
+			name:     "Default delimiters",
+			delims:   Delimiters{},
+			input:    `<w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p>`,
+			expected: `<w:p><w:r><w:t>{{.Name}}</w:t></w:r></w:p>`,
+		},
+		{
+			name:     "Tags and literal braces",
+			delims:   brackets,
+			input:    `<w:p><w:r><w:t xml:space="preserve">[[.Name]] uses {{ and }}]]</w:t></w:r></w:p>`,
+			expected: `<w:p><w:r><w:t xml:space="preserve">{{.Name}} uses ` + openBrace + openBrace + ` and ` + closeBrace + closeBrace + `]]</w:t></w:r></w:p>`,
+		},
+		{
+			name:     "Delimiters split across runs",
+			delims:   brackets,
+			input:    `<w:p><w:r><w:t>[</w:t></w:r><w:r><w:t>[.Na</w:t></w:r><w:r><w:t>me]</w:t></w:r><w:r><w:t>]!</w:t></w:r></w:p>`,
+			expected: `<w:p><w:r><w:t>{{</w:t></w:r><w:r><w:t>.Na</w:t></w:r><w:r><w:t>me}}</w:t></w:r><w:r><w:t>!</w:t></w:r></w:p>`,
+		},
+		{
+			name:     "Tags stop at the end of a paragraph",
+			delims:   brackets,
+			input:    `<w:p><w:r><w:t>[</w:t></w:r></w:p><w:p><w:r><w:t>[x]]</w:t></w:r></w:p>`,
+			expected: `<w:p><w:r><w:t>[</w:t></w:r></w:p><w:p><w:r><w:t>[x]]</w:t></w:r></w:p>`,
+		},
+		{
+			name:     "Escaped delimiters",
+			delims:   Delimiters{Left: "<<", Right: ">>"},
+			input:    `<w:p><w:r><w:t>&lt;&lt;if gt .Total 5&gt;&gt;a &amp; b&lt;&lt;end&gt;&gt; &lt;b&gt;</w:t></w:r></w:p>`,
+			expected: `<w:p><w:r><w:t>{{if gt .Total 5}}a &amp; b{{end}} &lt;b&gt;</w:t></w:r></w:p>`,
+		},
+		{
+			name:     "Picture and watermark attributes",
+			delims:   brackets,
+			input:    `<wp:docPr id="1" name="Logo" descr="[[.Logo]]"/><v:textpath style="x" string="[[ .Mark ]]"/><w:p/>`,
+			expected: `<wp:docPr id="1" name="Logo" descr="{{.Logo}}"/><v:textpath style="x" string="{{ .Mark }}"/><w:p/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TranslateDelimiters(tt.input, tt.delims))
+		})
+	}
+}
+
+func TestTranslatePropertiesDelimiters(t *testing.T) {
+	input := `<cp:coreProperties><dc:title>[[.Title]] {x}</dc:title><dc:creator>Me</dc:creator></cp:coreProperties>`
+	assert.Equal(t, `<cp:coreProperties><dc:title>{{.Title}} `+openBrace+`x`+closeBrace+`</dc:title><dc:creator>Me</dc:creator></cp:coreProperties>`,
+		TranslatePropertiesDelimiters(input, Delimiters{Left: "[[", Right: "]]"}))
+}
+
+func TestRestoreDelimiters(t *testing.T) {
+	delims := Delimiters{Left: "<%", Right: "%>"}
+	translated := TranslateTextDelimiters("<% .A %> {{ literal }} <% printf \"{x}\" %>", delims)
+	assert.Equal(t, "{{ .A }} "+openBrace+openBrace+" literal "+closeBrace+closeBrace+" {{ printf \""+openBrace+"x"+closeBrace+"\" }}", translated)
+
+	assert.Equal(t, "<% .A %> {{ literal }} <% printf \"{x}\" %>", delims.Restore(translated))
+	assert.Equal(t, "{{ .A }} {{ literal }} {{ printf \"{x}\" }}", RestoreBraces(translated))
+}
@@ -3,21 +3,38 @@ package xmlutils
 import (
 	"bytes"
 	"encoding/xml"
+	"html"
 	"strings"
 )
 
-// Escape special XML characters in a string and convert newlines to Word line breaks.
-// Newline characters (\n) are converted to </w:t><w:br/><w:t> for proper display in Word.
+// Escape special XML characters in a string and convert newlines and tabs to Word line breaks and tabs.
+// Newline characters (\n, \r\n or \r) are converted to </w:t><w:br/><w:t> for proper display in Word.
 func EscapeXmlString(xmlString string) (string, error) {
 	var buf bytes.Buffer
-	err := xml.EscapeText(&buf, []byte(xmlString))
+	err := xml.EscapeText(&buf, []byte(newlineReplacer.Replace(xmlString)))
 	if err != nil {
 		return "", err
 	}
 
-	// Convert newlines to Word line breaks
+	// Convert newlines to Word line breaks and tabs to Word tabs
 	// The pattern </w:t><w:br/><w:t> closes the current text run, inserts a break, and opens a new text run
-	result := strings.ReplaceAll(buf.String(), "&#xA;", "</w:t><w:br/><w:t>")
+	result := wordBreaksEscaper.Replace(buf.String())
 
 	return result, nil
 }
+
+// newlineReplacer normalizes Windows and classic Mac line endings to newlines
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// wordBreaksEscaper turns escaped newlines and tabs into Word line breaks and tabs
+var wordBreaksEscaper = strings.NewReplacer("&#xA;", "</w:t><w:br/><w:t>", "&#x9;", "</w:t><w:tab/><w:t>")
+
+// UnescapeXmlString reverses EscapeXmlString, turning Word line breaks and tabs
+// back into newlines and tabs and decoding character references. It is used by
+// template functions that need the original text of an escaped data value.
+func UnescapeXmlString(xmlString string) string {
+	return html.UnescapeString(wordBreaksReplacer.Replace(xmlString))
+}
+
+// wordBreaksReplacer turns the line breaks and tabs in escaped text into characters
+var wordBreaksReplacer = strings.NewReplacer("</w:t><w:br/><w:t>", "\n", "</w:t><w:tab/><w:t>", "\t")
@@ -37,6 +37,16 @@ func TestEscapeXmlString(t *testing.T) {
 			input:    "Line 1\nLine 2\nLine 3",
 			expected: "Line 1</w:t><w:br/><w:t>Line 2</w:t><w:br/><w:t>Line 3",
 		},
+		{
+			name:     "Normalize Windows and classic Mac line endings",
+			input:    "Line 1\r\nLine 2\rLine 3",
+			expected: "Line 1</w:t><w:br/><w:t>Line 2</w:t><w:br/><w:t>Line 3",
+		},
+		{
+			name:     "Convert tabs to Word tabs",
+			input:    "Name:\tTom",
+			expected: "Name:</w:t><w:tab/><w:t>Tom",
+		},
 		{
 			name:     "Handle text without newlines",
 			input:    "Single line text",
@@ -52,3 +62,35 @@ func TestEscapeXmlString(t *testing.T) {
 		})
 	}
 }
+
+func TestUnescapeXmlString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Unescape XML characters",
+			input:    "&lt;b&gt;Fish &amp; Chips&lt;/b&gt; &#34;quoted&#34; &#39;single&#39;",
+			expected: "<b>Fish & Chips</b> \"quoted\" 'single'",
+		},
+		{
+			name:     "Convert Word line breaks to newlines",
+			input:    "Line 1</w:t><w:br/><w:t>Line 2",
+			expected: "Line 1\nLine 2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, UnescapeXmlString(test.input))
+		})
+	}
+
+	t.Run("Reverse EscapeXmlString", func(t *testing.T) {
+		text := "# Title\n\n* a & b\n* <c>\t\"d\""
+		escaped, err := EscapeXmlString(text)
+		assert.NoError(t, err)
+		assert.Equal(t, text, UnescapeXmlString(escaped))
+	})
+}
@@ -0,0 +1,24 @@
+package xmlutils
+
+import "regexp"
+
+var (
+	// defaultLanguageRegex captures the language of the default run properties
+	// in styles.xml
+	defaultLanguageRegex = regexp.MustCompile(`(?s)<w:docDefaults>.*?<w:rPrDefault>.*?<w:lang\b[^>]*?\bw:val="([^"]+)".*?</w:docDefaults>`)
+	// themeLanguageRegex captures the language of the theme fonts in settings.xml
+	themeLanguageRegex = regexp.MustCompile(`<w:themeFontLang\b[^>]*?\bw:val="([^"]+)"`)
+)
+
+// DocumentLanguage returns the language of a document's text, from the
+// default run properties in styles.xml or else the theme font language in
+// settings.xml. It's empty when neither sets one.
+func DocumentLanguage(stylesXml, settingsXml string) string {
+	if match := defaultLanguageRegex.FindStringSubmatch(stylesXml); match != nil {
+		return match[1]
+	}
+	if match := themeLanguageRegex.FindStringSubmatch(settingsXml); match != nil {
+		return match[1]
+	}
+	return ""
+}
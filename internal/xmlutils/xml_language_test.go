@@ -0,0 +1,16 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentLanguage(t *testing.T) {
+	styles := `<w:styles><w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="22"/><w:lang w:val="de-DE" w:eastAsia="en-US" w:bidi="ar-SA"/></w:rPr></w:rPrDefault></w:docDefaults></w:styles>`
+	settings := `<w:settings><w:themeFontLang w:val="fr-FR" w:bidi="ar-AE"/></w:settings>`
+
+	assert.Equal(t, "de-DE", DocumentLanguage(styles, settings))
+	assert.Equal(t, "fr-FR", DocumentLanguage(`<w:styles><w:docDefaults><w:rPrDefault><w:rPr/></w:rPrDefault></w:docDefaults><w:style><w:rPr><w:lang w:val="it-IT"/></w:rPr></w:style></w:styles>`, settings))
+	assert.Equal(t, "", DocumentLanguage("", ""))
+}
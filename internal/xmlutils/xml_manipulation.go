@@ -13,9 +13,21 @@ func PrepareXmlForTagReplacement(xmlString string) (string, error) {
 	// Word often encodes " as &#34; or &quot; which breaks template parsing
 	xmlString = decodeEntitiesInTemplateTags(xmlString)
 
+	// Block tags alone in a table row control whole rows and tables
+	xmlString = replaceBlockRows(xmlString)
+
 	newXmlString, err := replaceTableRangeRows(xmlString)
+	if err != nil {
+		return "", err
+	}
+
+	// Repeat table cells in {{hrange}} column loops
+	newXmlString = replaceColumnLoops(newXmlString)
+
+	// Block tags alone in a paragraph control whole paragraphs
+	newXmlString = replaceBlockParagraphs(newXmlString)
 
-	return newXmlString, err
+	return newXmlString, nil
 }
 
 // decodeEntitiesInTemplateTags decodes XML/HTML entities within template tags.
@@ -233,8 +245,11 @@ func replaceTableRangeRows(xmlString string) (string, error) {
 		return "", err
 	}
 	for m != nil {
-		gps := m.Groups()
-		newXmlString = strings.Replace(newXmlString, m.String(), gps[1].Captures[0].String(), 1)
+		// Rows holding complete inline blocks such as {{if .X}}...{{end}} are kept
+		if !blocksBalanced(m.String()) {
+			gps := m.Groups()
+			newXmlString = strings.Replace(newXmlString, m.String(), gps[1].Captures[0].String(), 1)
+		}
 		m, _ = tableRangeRowRegex.FindNextMatch(m)
 	}
 
@@ -252,6 +267,15 @@ func FixXmlIssuesPostTagReplacement(xmlString string) string {
 	// or when a value is explicitly nil in the data
 	xmlString = strings.ReplaceAll(xmlString, "<nil>", "")
 
+	// Split the paragraphs holding paragraph breaks from the paragraphs function
+	xmlString = expandParagraphBreaks(xmlString)
+
+	// Split the paragraphs holding block content such as rendered Markdown
+	xmlString = expandBlockContent(xmlString)
+
+	// Splice the runs of rich text values into the runs holding their placeholders
+	xmlString = expandRichText(xmlString)
+
 	// Fix issues with drawings in text nodes
 	xmlString = strings.ReplaceAll(xmlString, "<w:t><w:drawing>", "<w:drawing>")
 	xmlString = strings.ReplaceAll(xmlString, "</w:drawing></w:t>", "</w:drawing>")
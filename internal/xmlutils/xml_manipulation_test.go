@@ -47,6 +47,11 @@ func TestReplaceTableRangeRows(t *testing.T) {
 			inputXml:          "<w:tbl><w:tr>{{range . }}</w:tr><w:tr></w:tr><w:tr>{{end}}</w:tr></w:tbl>",
 			expectedOutputXml: "<w:tbl>{{range . }}<w:tr></w:tr>{{end}}</w:tbl>",
 		},
+		{
+			name:              "Inline blocks within a row",
+			inputXml:          "<w:tbl><w:tr>{{if .Show}}Discount{{end}}</w:tr></w:tbl>",
+			expectedOutputXml: "<w:tbl><w:tr>{{if .Show}}Discount{{end}}</w:tr></w:tbl>",
+		},
 	}
 
 	for _, tt := range tests {
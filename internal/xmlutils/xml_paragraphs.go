@@ -0,0 +1,71 @@
+package xmlutils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ParagraphBreak marks where the paragraph holding a placeholder is split in
+// rendered XML. The paragraphs after the break copy the properties of the
+// paragraph and of the run holding it.
+const ParagraphBreak = "<docxtpl:paragraphBreak/>"
+
+// blankLinesRegex matches a line break followed by lines holding only spaces and tabs
+var blankLinesRegex = regexp.MustCompile(`\n(?:[ \t]*\n)+`)
+
+// EscapeParagraphs escapes text like EscapeXmlString, splitting it into
+// paragraphs at blank lines. Single newlines stay line breaks.
+func EscapeParagraphs(text string) (string, error) {
+	text = strings.Trim(newlineReplacer.Replace(text), "\n")
+	paragraphs := blankLinesRegex.Split(text, -1)
+	for i := range paragraphs {
+		escaped, err := EscapeXmlString(paragraphs[i])
+		if err != nil {
+			return "", err
+		}
+		paragraphs[i] = escaped
+	}
+	return strings.Join(paragraphs, ParagraphBreak), nil
+}
+
+// expandParagraphBreaks splits the paragraphs holding paragraph breaks. Each
+// new paragraph copies the properties of the paragraph and run holding the
+// break, except that a section break stays with the last paragraph. Breaks
+// that aren't in the text of a run become newlines.
+func expandParagraphBreaks(xmlString string) string {
+	searchFrom := 0
+	for {
+		start := strings.Index(xmlString[searchFrom:], ParagraphBreak)
+		if start < 0 {
+			return xmlString
+		}
+		start += searchFrom
+		before := xmlString[:start]
+		after := xmlString[start+len(ParagraphBreak):]
+
+		paragraphStart := max(strings.LastIndex(before, "<w:p>"), strings.LastIndex(before, "<w:p "))
+		runStart := max(strings.LastIndex(before, "<w:r>"), strings.LastIndex(before, "<w:r "))
+		textStart := max(strings.LastIndex(before, "<w:t>"), strings.LastIndex(before, "<w:t "))
+		if paragraphStart < 0 || strings.LastIndex(before, "</w:p>") > paragraphStart || runStart < paragraphStart ||
+			textStart < runStart || strings.LastIndex(before, "</w:t>") > textStart {
+			xmlString = before + "\n" + after
+			searchFrom = start + 1
+			continue
+		}
+
+		paragraphTag := before[paragraphStart : paragraphStart+strings.IndexByte(before[paragraphStart:], '>')+1]
+		paragraphProperties := paragraphPropertiesRegex.FindString(before[paragraphStart+len(paragraphTag):])
+		runTag := before[runStart : runStart+strings.IndexByte(before[runStart:], '>')+1]
+		runProperties := runPropertiesRegex.FindString(before[runStart+len(runTag):])
+
+		// A section break stays with the last paragraph
+		if sectionPropertiesRegex.MatchString(paragraphProperties) {
+			head := strings.Replace(before[paragraphStart:], paragraphProperties, sectionPropertiesRegex.ReplaceAllString(paragraphProperties, ""), 1)
+			before = before[:paragraphStart] + head
+		}
+
+		reopened := "</w:t></w:r></w:p>" + paragraphTag + paragraphProperties + runTag + runProperties + `<w:t xml:space="preserve">`
+		xmlString = before + reopened + after
+		searchFrom = len(before) + len(reopened)
+	}
+}
@@ -0,0 +1,47 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeParagraphs(t *testing.T) {
+	escaped, err := EscapeParagraphs("\r\nOne\r\nline & more\r\n \t\r\n\r\nTwo\n\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "One</w:t><w:br/><w:t>line &amp; more"+ParagraphBreak+"Two", escaped)
+}
+
+func TestExpandParagraphBreaks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "Split the paragraph copying its properties",
+			input: `<w:p w14:paraId="1"><w:pPr><w:numPr><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>A` + ParagraphBreak + `B</w:t></w:r><w:r><w:t>C</w:t></w:r></w:p>`,
+			expected: `<w:p w14:paraId="1"><w:pPr><w:numPr><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>A</w:t></w:r></w:p>` +
+				`<w:p w14:paraId="1"><w:pPr><w:numPr><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">B</w:t></w:r><w:r><w:t>C</w:t></w:r></w:p>`,
+		},
+		{
+			name:  "Keep the section break with the last paragraph",
+			input: `<w:p><w:pPr><w:jc w:val="center"/><w:sectPr><w:pgSz/></w:sectPr></w:pPr><w:r><w:t>A` + ParagraphBreak + `B` + ParagraphBreak + `C</w:t></w:r></w:p>`,
+			expected: `<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>A</w:t></w:r></w:p>` +
+				`<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">B</w:t></w:r></w:p>` +
+				`<w:p><w:pPr><w:jc w:val="center"/><w:sectPr><w:pgSz/></w:sectPr></w:pPr><w:r><w:t xml:space="preserve">C</w:t></w:r></w:p>`,
+		},
+		{
+			name:     "Breaks outside run text become newlines",
+			input:    `<wp:docPr descr="A` + ParagraphBreak + `B"/>`,
+			expected: "<wp:docPr descr=\"A\nB\"/>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expandParagraphBreaks(tt.input))
+		})
+	}
+}
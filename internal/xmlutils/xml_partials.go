@@ -0,0 +1,100 @@
+package xmlutils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// templateTagRegex matches a template tag
+	templateTagRegex = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+	// definitionTagRegex matches the tags that open and close templates and
+	// blocks, capturing the name of {{define}} tags
+	definitionTagRegex = regexp.MustCompile(`\{\{-?\s*(?:define\s+"([^"]*)"|(if|range|with|block)\b|(end)\b)[^{}]*?-?\}\}`)
+)
+
+// EscapeTemplateText escapes the text of a template written as plain text for
+// use in the text of a run, leaving its tags as they are.
+func EscapeTemplateText(text string) (string, error) {
+	var result strings.Builder
+	last := 0
+	for _, tag := range templateTagRegex.FindAllStringIndex(text, -1) {
+		escaped, err := EscapeXmlString(text[last:tag[0]])
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(escaped)
+		result.WriteString(text[tag[0]:tag[1]])
+		last = tag[1]
+	}
+	escaped, err := EscapeXmlString(text[last:])
+	if err != nil {
+		return "", err
+	}
+	result.WriteString(escaped)
+	return result.String(), nil
+}
+
+// ExtractDefinitions returns the body of every {{define}} block in XML prepared
+// for tag replacement, keyed by name. Definitions whose tags sit alone in their
+// own paragraphs are block content; definitions within the text of a paragraph
+// are rich text runs that take on the formatting of the tag that uses them.
+func ExtractDefinitions(xmlString string) (map[string]string, error) {
+	definitions := make(map[string]string)
+
+	depth := 0
+	var name string
+	var defineStart, bodyStart int
+	for _, match := range definitionTagRegex.FindAllStringSubmatchIndex(xmlString, -1) {
+		switch {
+		case match[2] >= 0:
+			if depth == 0 {
+				name = xmlString[match[2]:match[3]]
+				defineStart, bodyStart = match[0], match[1]
+			}
+			depth++
+		case match[4] >= 0:
+			depth++
+		case match[6] >= 0 && depth > 0:
+			depth--
+			if depth > 0 || name == "" {
+				continue
+			}
+
+			body := xmlString[bodyStart:match[0]]
+			startsInText, endsInText := inRunText(xmlString, defineStart), inRunText(xmlString, match[0])
+			switch {
+			case !startsInText && !endsInText:
+				definitions[name] = BlockContentStart + body + BlockContentEnd
+			case startsInText && endsInText && !strings.Contains(body, "</w:p>"):
+				definitions[name] = RichTextStart + definitionRun(xmlString[:defineStart]) + body + "</w:t></w:r>" + RichTextEnd
+			default:
+				return nil, fmt.Errorf("the tags of template %q must be in the text of one paragraph or alone in their own paragraphs", name)
+			}
+			name = ""
+		}
+	}
+	return definitions, nil
+}
+
+// inRunText reports whether an offset in XML is within the text of a run
+func inRunText(xmlString string, offset int) bool {
+	before := xmlString[:offset]
+	return max(strings.LastIndex(before, "<w:t>"), strings.LastIndex(before, "<w:t ")) > strings.LastIndex(before, "</w:t>")
+}
+
+// definitionRun reopens the run holding a {{define}} tag, with its formatting,
+// for the text that follows the tag
+func definitionRun(before string) string {
+	runStart := max(strings.LastIndex(before, "<w:r>"), strings.LastIndex(before, "<w:r "))
+	if runStart < 0 {
+		return `<w:r><w:rPr></w:rPr><w:t xml:space="preserve">`
+	}
+	runTag := before[runStart : runStart+strings.IndexByte(before[runStart:], '>')+1]
+	properties := ""
+	if match := runPropertiesRegex.FindStringSubmatch(before[runStart+len(runTag):]); match != nil {
+		properties = match[1]
+	}
+	return runTag + "<w:rPr>" + properties + `</w:rPr><w:t xml:space="preserve">`
+}
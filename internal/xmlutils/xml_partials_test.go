@@ -0,0 +1,33 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeTemplateText(t *testing.T) {
+	escaped, err := EscapeTemplateText("A & B {{if lt .X 3}}<{{.X}}>{{end}}\nEnd")
+	require.NoError(t, err)
+	assert.Equal(t, "A &amp; B {{if lt .X 3}}&lt;{{.X}}&gt;{{end}}</w:t><w:br/><w:t>End", escaped)
+}
+
+func TestExtractDefinitions(t *testing.T) {
+	t.Run("Block and inline definitions", func(t *testing.T) {
+		xmlString := `<w:body>{{define "signature"}}<w:p><w:r><w:t>Regards</w:t></w:r></w:p>{{if .Title}}<w:p><w:r><w:t>{{.Title}}</w:t></w:r></w:p>{{end}}{{end}}` +
+			`<w:p><w:r><w:rPr><w:i/></w:rPr><w:t>Intro {{define "greeting"}}Dear </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>{{.Name}}{{end}}</w:t></w:r></w:p></w:body>`
+
+		definitions, err := ExtractDefinitions(xmlString)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"signature": BlockContentStart + `<w:p><w:r><w:t>Regards</w:t></w:r></w:p>{{if .Title}}<w:p><w:r><w:t>{{.Title}}</w:t></w:r></w:p>{{end}}` + BlockContentEnd,
+			"greeting":  RichTextStart + `<w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">Dear </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>{{.Name}}</w:t></w:r>` + RichTextEnd,
+		}, definitions)
+	})
+
+	t.Run("Definitions spanning paragraphs from within their text", func(t *testing.T) {
+		_, err := ExtractDefinitions(`<w:p><w:r><w:t>{{define "x"}}A</w:t></w:r></w:p><w:p><w:r><w:t>B{{end}}</w:t></w:r></w:p>`)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,161 @@
+package xmlutils
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Picture describes a picture drawing, an inline (wp:inline) or floating
+// (wp:anchor) drawing holding an image
+type Picture struct {
+	Name  string // name of the drawing
+	Descr string // alternative text
+	Title string
+	Embed string // relationship id of the image
+
+	// SVGEmbed is the relationship id of an SVG image shown instead of the
+	// image by readers that support it, which is then a fallback
+	SVGEmbed string
+
+	Width, Height int64 // extent in EMUs
+}
+
+var (
+	// pictureRegex matches an inline or floating drawing
+	pictureRegex = regexp.MustCompile(`(?s)<wp:inline\b.*?</wp:inline>|<wp:anchor\b.*?</wp:anchor>`)
+	// pictureContentRegex matches the picture inside a drawing, which shapes and charts lack
+	pictureContentRegex = regexp.MustCompile(`<pic:pic\b`)
+	// drawingPropertiesRegex matches the properties of the drawing and of the picture inside it
+	drawingPropertiesRegex = regexp.MustCompile(`<(?:wp:docPr|pic:cNvPr)\b[^>]*>`)
+	// docPrRegex matches the properties of the drawing
+	docPrRegex = regexp.MustCompile(`<wp:docPr\b[^>]*>`)
+	// extentRegex matches the displayed size of the drawing
+	extentRegex = regexp.MustCompile(`<wp:extent\b[^>]*>`)
+	// transformExtentRegex matches the size of the picture's shape
+	transformExtentRegex = regexp.MustCompile(`(<a:xfrm\b[^>]*>(?:<a:off\b[^>]*?(?:/>|></a:off>))?)<a:ext\b[^>]*?(/>|></a:ext>)`)
+	// embedRegex matches the image relationship of the picture
+	embedRegex = regexp.MustCompile(`\br:embed="([^"]*)"`)
+	// blipRegex matches the image of the picture with its extensions
+	blipRegex = regexp.MustCompile(`(?s)<a:blip\b[^>]*?(?:/>|>.*?</a:blip>)`)
+	// svgExtensionRegex matches the extension of the image holding an SVG image
+	svgExtensionRegex = regexp.MustCompile(`(?s)<a:ext\s+uri="\{96DAC541-7B7A-43D3-8B79-37D633B846F1\}"[^>]*>.*?</a:ext>`)
+	// svgBlipRegex matches the SVG image in the extension
+	svgBlipRegex = regexp.MustCompile(`<\w+:svgBlip\b[^>]*>`)
+	// sourceRectRegex matches the cropping of the picture's image
+	sourceRectRegex = regexp.MustCompile(`(?s)<a:srcRect\b[^>]*?(?:/>|>.*?</a:srcRect>)`)
+)
+
+// ReplacePictures calls replace with each picture drawing in the XML. When it
+// returns true the drawing is updated with the picture's image, size and
+// properties. The cropping of the previous image is removed with it.
+func ReplacePictures(xmlString string, replace func(picture *Picture) (bool, error)) (string, error) {
+	var err error
+	result := pictureRegex.ReplaceAllStringFunc(xmlString, func(drawing string) string {
+		if err != nil || !pictureContentRegex.MatchString(drawing) {
+			return drawing
+		}
+
+		docPr := docPrRegex.FindString(drawing)
+		original := Picture{
+			Name:  attributeValue(docPr, "name"),
+			Descr: attributeValue(docPr, "descr"),
+			Title: attributeValue(docPr, "title"),
+		}
+		if match := embedRegex.FindStringSubmatch(drawing); match != nil {
+			original.Embed = match[1]
+		}
+		if svgBlip := svgBlipRegex.FindString(drawing); svgBlip != "" {
+			original.SVGEmbed = attributeValue(svgBlip, "r:embed")
+		}
+		extent := extentRegex.FindString(drawing)
+		original.Width, _ = strconv.ParseInt(attributeValue(extent, "cx"), 10, 64)
+		original.Height, _ = strconv.ParseInt(attributeValue(extent, "cy"), 10, 64)
+
+		picture := original
+		var replaced bool
+		replaced, err = replace(&picture)
+		if err != nil || !replaced {
+			return drawing
+		}
+
+		width, height := strconv.FormatInt(picture.Width, 10), strconv.FormatInt(picture.Height, 10)
+		drawing = extentRegex.ReplaceAllStringFunc(drawing, func(tag string) string {
+			return setAttribute(setAttribute(tag, "cx", width), "cy", height)
+		})
+		drawing = transformExtentRegex.ReplaceAllString(drawing, `${1}<a:ext cx="`+width+`" cy="`+height+`"${2}`)
+		drawing = svgExtensionRegex.ReplaceAllLiteralString(drawing, "")
+		drawing = embedRegex.ReplaceAllLiteralString(drawing, `r:embed="`+escapeAttribute(picture.Embed)+`"`)
+		if picture.SVGEmbed != "" {
+			drawing = blipRegex.ReplaceAllStringFunc(drawing, func(blip string) string {
+				return addSVGExtension(blip, picture.SVGEmbed)
+			})
+		}
+		drawing = sourceRectRegex.ReplaceAllLiteralString(drawing, "")
+
+		// The picture inside the drawing repeats the drawing's name and alternative text
+		return drawingPropertiesRegex.ReplaceAllStringFunc(drawing, func(tag string) string {
+			for _, attribute := range []struct{ name, from, to string }{
+				{"name", original.Name, picture.Name},
+				{"descr", original.Descr, picture.Descr},
+				{"title", original.Title, picture.Title},
+			} {
+				if attribute.from != attribute.to && (attribute.from == "" || attributeValue(tag, attribute.name) == attribute.from) {
+					tag = setAttribute(tag, attribute.name, attribute.to)
+				}
+			}
+			return tag
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// addSVGExtension adds the extension showing the SVG image with the
+// relationship id embed to a blip
+func addSVGExtension(blip, embed string) string {
+	extension := `<a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}">` +
+		`<asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="` + escapeAttribute(embed) + `"/></a:ext>`
+	switch {
+	case strings.HasSuffix(blip, "/>"):
+		return blip[:len(blip)-2] + `><a:extLst>` + extension + `</a:extLst></a:blip>`
+	case strings.Contains(blip, "</a:extLst>"):
+		return strings.Replace(blip, "</a:extLst>", extension+"</a:extLst>", 1)
+	}
+	return strings.TrimSuffix(blip, "</a:blip>") + `<a:extLst>` + extension + `</a:extLst></a:blip>`
+}
+
+// attributeValue returns the unescaped value of an attribute of a start tag
+func attributeValue(tag, name string) string {
+	match := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `="([^"]*)"`).FindStringSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+	return html.UnescapeString(match[1])
+}
+
+// setAttribute sets an attribute of a start tag, removing it when the value is empty
+func setAttribute(tag, name, value string) string {
+	attributeRegex := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `="[^"]*"`)
+	if value == "" {
+		return attributeRegex.ReplaceAllLiteralString(tag, "")
+	}
+	attribute := ` ` + name + `="` + escapeAttribute(value) + `"`
+	if attributeRegex.MatchString(tag) {
+		return attributeRegex.ReplaceAllLiteralString(tag, attribute)
+	}
+	end := len(tag) - 1
+	if strings.HasSuffix(tag, "/>") {
+		end--
+	}
+	return tag[:end] + attribute + tag[end:]
+}
+
+// escapeAttribute escapes a value for use in a double quoted attribute
+func escapeAttribute(value string) string {
+	escaped, _ := EscapeXmlString(value)
+	return strings.NewReplacer("</w:t><w:br/><w:t>", "&#xA;", "</w:t><w:tab/><w:t>", "&#x9;").Replace(escaped)
+}
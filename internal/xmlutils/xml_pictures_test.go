@@ -0,0 +1,96 @@
+package xmlutils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplacePictures(t *testing.T) {
+	picture := `<wp:inline><wp:extent cx="100" cy="50"/><wp:docPr id="1" name="Logo" descr="{{.Logo}}"/>` +
+		`<a:graphic><a:graphicData><pic:pic><pic:nvPicPr><pic:cNvPr id="0" name="logo.png" descr="{{.Logo}}"/></pic:nvPicPr>` +
+		`<pic:blipFill><a:blip r:embed="rId3"/><a:srcRect l="5"/><a:stretch/></pic:blipFill>` +
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="100" cy="50"/></a:xfrm></pic:spPr></pic:pic></a:graphicData></a:graphic></wp:inline>`
+	shape := `<wp:anchor><wp:extent cx="10" cy="10"/><wp:docPr id="2" name="Box"/><a:graphic><wps:wsp/></a:graphic></wp:anchor>`
+
+	t.Run("Should update the image, size and properties of a picture", func(t *testing.T) {
+		var seen []Picture
+		result, err := ReplacePictures(`<w:p>`+picture+shape+`</w:p>`, func(p *Picture) (bool, error) {
+			seen = append(seen, *p)
+			p.Embed = "rId9"
+			p.Width, p.Height = 80, 40
+			p.Descr = `Logo "new"`
+			p.Title = "Logo"
+			return true, nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []Picture{{Name: "Logo", Descr: "{{.Logo}}", Embed: "rId3", Width: 100, Height: 50}}, seen)
+		assert.Equal(t, `<w:p><wp:inline><wp:extent cx="80" cy="40"/><wp:docPr id="1" name="Logo" descr="Logo &#34;new&#34;" title="Logo"/>`+
+			`<a:graphic><a:graphicData><pic:pic><pic:nvPicPr><pic:cNvPr id="0" name="logo.png" descr="Logo &#34;new&#34;" title="Logo"/></pic:nvPicPr>`+
+			`<pic:blipFill><a:blip r:embed="rId9"/><a:stretch/></pic:blipFill>`+
+			`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="80" cy="40"/></a:xfrm></pic:spPr></pic:pic></a:graphicData></a:graphic></wp:inline>`+
+			shape+`</w:p>`, result)
+	})
+
+	t.Run("Should replace the SVG image of a picture", func(t *testing.T) {
+		svgPicture := strings.Replace(picture, `<a:blip r:embed="rId3"/>`,
+			`<a:blip r:embed="rId3"><a:extLst><a:ext uri="{28A0092B-C50C-407E-A947-70E740481C1C}"><a14:useLocalDpi val="0"/></a:ext>`+
+				`<a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}"><asvg:svgBlip r:embed="rId4"/></a:ext></a:extLst></a:blip>`, 1)
+
+		var seen Picture
+		result, err := ReplacePictures(svgPicture, func(p *Picture) (bool, error) {
+			seen = *p
+			p.Embed = "rId9"
+			p.SVGEmbed = "rId10"
+			return true, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "rId4", seen.SVGEmbed)
+		assert.Contains(t, result, `<a:blip r:embed="rId9"><a:extLst><a:ext uri="{28A0092B-C50C-407E-A947-70E740481C1C}"><a14:useLocalDpi val="0"/></a:ext>`+
+			`<a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}"><asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="rId10"/></a:ext></a:extLst></a:blip>`)
+
+		// A raster image removes the SVG image
+		result, err = ReplacePictures(svgPicture, func(p *Picture) (bool, error) {
+			p.Embed, p.SVGEmbed = "rId9", ""
+			return true, nil
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, result, "svgBlip")
+
+		result, err = ReplacePictures(picture, func(p *Picture) (bool, error) {
+			p.SVGEmbed = "rId10"
+			return true, nil
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result, `<a:blip r:embed="rId3"><a:extLst><a:ext uri=`)
+	})
+
+	t.Run("Should remove emptied properties", func(t *testing.T) {
+		result, err := ReplacePictures(picture, func(p *Picture) (bool, error) {
+			p.Descr = ""
+			return true, nil
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, result, "descr=")
+	})
+
+	t.Run("Should leave pictures that aren't replaced unchanged", func(t *testing.T) {
+		result, err := ReplacePictures(picture, func(p *Picture) (bool, error) {
+			p.Embed = "rId9"
+			return false, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, picture, result)
+	})
+
+	t.Run("Should return the error of the replacement", func(t *testing.T) {
+		_, err := ReplacePictures(picture, func(*Picture) (bool, error) {
+			return false, errors.New("failed")
+		})
+		assert.EqualError(t, err, "failed")
+	})
+}
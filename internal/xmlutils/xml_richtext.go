@@ -0,0 +1,184 @@
+package xmlutils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RichTextStart and RichTextEnd delimit the runs of a rich text value in rendered XML.
+// The runs are spliced into the run holding the placeholder after tag replacement,
+// inheriting its formatting. Escaped template data can never contain the markers.
+const (
+	RichTextStart = "<docxtpl:richText>"
+	RichTextEnd   = "</docxtpl:richText>"
+)
+
+// runPropertiesRegex matches the properties at the start of a run's content
+var runPropertiesRegex = regexp.MustCompile(`(?s)^<w:rPr>(.*?)</w:rPr>`)
+
+// nestedRunPropertiesRegex matches the properties of the runs inside a rich text value
+var nestedRunPropertiesRegex = regexp.MustCompile(`(?s)<w:rPr>(.*?)</w:rPr>`)
+
+// runPropertyOrder is the order of run property elements required by the schema
+var runPropertyOrder = []string{
+	"rStyle", "rFonts", "b", "bCs", "i", "iCs", "caps", "smallCaps", "strike", "dstrike",
+	"outline", "shadow", "emboss", "imprint", "noProof", "snapToGrid", "vanish", "webHidden",
+	"color", "spacing", "w", "kern", "position", "sz", "szCs", "highlight", "u", "effect",
+	"bdr", "shd", "fitText", "vertAlign", "rtl", "cs", "em", "lang", "eastAsianLayout",
+	"specVanish", "oMath", "rPrChange",
+}
+
+// expandRichText splits the runs holding rich text values so the value's runs
+// sit between the text before and after the placeholder. Values that aren't in
+// the text of a run are replaced by their plain text.
+func expandRichText(xmlString string) string {
+	searchFrom := 0
+	for {
+		start := strings.Index(xmlString[searchFrom:], RichTextStart)
+		if start < 0 {
+			return xmlString
+		}
+		start += searchFrom
+		end := strings.Index(xmlString[start:], RichTextEnd)
+		if end < 0 {
+			return xmlString
+		}
+		end += start
+
+		before := xmlString[:start]
+		runs := xmlString[start+len(RichTextStart) : end]
+
+		var replacement string
+		runStart := max(strings.LastIndex(before, "<w:r>"), strings.LastIndex(before, "<w:r "))
+		textStart := max(strings.LastIndex(before, "<w:t>"), strings.LastIndex(before, "<w:t "))
+		if runStart < 0 || textStart < runStart || strings.LastIndex(before, "</w:t>") > textStart {
+			replacement = richTextPlainText(runs)
+		} else {
+			runTag := before[runStart : runStart+strings.IndexByte(before[runStart:], '>')+1]
+			properties := ""
+			if match := runPropertiesRegex.FindStringSubmatch(before[runStart+len(runTag):]); match != nil {
+				properties = match[1]
+			}
+
+			var reopened strings.Builder
+			reopened.WriteString(runTag)
+			if properties != "" {
+				reopened.WriteString("<w:rPr>" + properties + "</w:rPr>")
+			}
+			reopened.WriteString(`<w:t xml:space="preserve">`)
+
+			replacement = "</w:t></w:r>" + inheritRunProperties(runs, properties) + reopened.String()
+		}
+
+		xmlString = before + replacement + xmlString[end+len(RichTextEnd):]
+		searchFrom = start + len(replacement)
+	}
+}
+
+// StripRichText replaces rich text values, block content and paragraph breaks with their plain text, for content that isn't XML runs
+func StripRichText(text string) string {
+	text = strings.ReplaceAll(text, ParagraphBreak, "\n")
+	text = replaceMarked(text, BlockContentStart, BlockContentEnd, blockContentPlainText)
+	return replaceMarked(text, RichTextStart, RichTextEnd, richTextPlainText)
+}
+
+// replaceMarked replaces the content between each pair of markers, markers included
+func replaceMarked(text string, startMarker string, endMarker string, replace func(string) string) string {
+	for {
+		start := strings.Index(text, startMarker)
+		if start < 0 {
+			return text
+		}
+		end := strings.Index(text[start:], endMarker)
+		if end < 0 {
+			return text
+		}
+		end += start
+		text = text[:start] + replace(text[start+len(startMarker):end]) + text[end+len(endMarker):]
+	}
+}
+
+// richTextPlainText returns the text content of rich text runs
+func richTextPlainText(runs string) string {
+	var text strings.Builder
+	for _, match := range textContentRegex.FindAllStringSubmatch(runs, -1) {
+		text.WriteString(match[1])
+	}
+	return text.String()
+}
+
+// inheritRunProperties merges the properties of the placeholder run into every run of a rich text value
+// and puts them in schema order
+func inheritRunProperties(runs string, properties string) string {
+	return nestedRunPropertiesRegex.ReplaceAllStringFunc(runs, func(runProperties string) string {
+		own := nestedRunPropertiesRegex.FindStringSubmatch(runProperties)[1]
+		return "<w:rPr>" + mergeRunProperties(properties, own) + "</w:rPr>"
+	})
+}
+
+// runProperty is a single child element of a run's properties
+type runProperty struct {
+	name string
+	xml  string
+}
+
+// mergeRunProperties combines two sets of run properties, with overrides replacing
+// base properties of the same name, in schema order
+func mergeRunProperties(base, overrides string) string {
+	overrideProperties := splitRunProperties(overrides)
+	merged := slices.DeleteFunc(splitRunProperties(base), func(p runProperty) bool {
+		return slices.ContainsFunc(overrideProperties, func(o runProperty) bool { return o.name == p.name })
+	})
+	merged = append(merged, overrideProperties...)
+
+	slices.SortStableFunc(merged, func(a, b runProperty) int {
+		return runPropertyPosition(a.name) - runPropertyPosition(b.name)
+	})
+
+	var result strings.Builder
+	for _, property := range merged {
+		result.WriteString(property.xml)
+	}
+	return result.String()
+}
+
+// runPropertyPosition returns the position of a property in the schema order, unknown properties go last
+func runPropertyPosition(name string) int {
+	if position := slices.Index(runPropertyOrder, name); position >= 0 {
+		return position
+	}
+	return len(runPropertyOrder)
+}
+
+// splitRunProperties splits run properties into their child elements
+func splitRunProperties(properties string) []runProperty {
+	var result []runProperty
+	for i := 0; i < len(properties); {
+		if !strings.HasPrefix(properties[i:], "<w:") {
+			i++
+			continue
+		}
+		nameEnd := i + 3
+		for nameEnd < len(properties) && properties[nameEnd] != ' ' && properties[nameEnd] != '>' && properties[nameEnd] != '/' {
+			nameEnd++
+		}
+		name := properties[i+3 : nameEnd]
+
+		tagEnd := strings.IndexByte(properties[i:], '>')
+		if tagEnd < 0 {
+			break
+		}
+		end := i + tagEnd + 1
+		if properties[end-2] != '/' {
+			closing := "</w:" + name + ">"
+			if closeIndex := strings.Index(properties[end:], closing); closeIndex >= 0 {
+				end += closeIndex + len(closing)
+			}
+		}
+
+		result = append(result, runProperty{name: name, xml: properties[i:end]})
+		i = end
+	}
+	return result
+}
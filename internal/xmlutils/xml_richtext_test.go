@@ -0,0 +1,56 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandRichText(t *testing.T) {
+	tests := []struct {
+		name              string
+		inputXml          string
+		expectedOutputXml string
+	}{
+		{
+			name:              "Runs inherit the placeholder run properties",
+			inputXml:          `<w:p><w:r><w:rPr><w:i/><w:sz w:val="28"/></w:rPr><w:t>Due: ` + RichTextStart + `<w:r><w:rPr><w:color w:val="FF0000"/><w:b/></w:rPr><w:t xml:space="preserve">$120</w:t></w:r>` + RichTextEnd + ` now</w:t></w:r></w:p>`,
+			expectedOutputXml: `<w:p><w:r><w:rPr><w:i/><w:sz w:val="28"/></w:rPr><w:t>Due: </w:t></w:r><w:r><w:rPr><w:b/><w:i/><w:color w:val="FF0000"/><w:sz w:val="28"/></w:rPr><w:t xml:space="preserve">$120</w:t></w:r><w:r><w:rPr><w:i/><w:sz w:val="28"/></w:rPr><w:t xml:space="preserve"> now</w:t></w:r></w:p>`,
+		},
+		{
+			name:              "Run properties override placeholder properties",
+			inputXml:          `<w:r w:rsidR="00AB"><w:rPr><w:color w:val="000000"/></w:rPr><w:t>` + RichTextStart + `<w:r><w:rPr><w:color w:val="FF0000"/></w:rPr><w:t xml:space="preserve">red</w:t></w:r>` + RichTextEnd + `</w:t></w:r>`,
+			expectedOutputXml: `<w:r w:rsidR="00AB"><w:rPr><w:color w:val="000000"/></w:rPr><w:t></w:t></w:r><w:r><w:rPr><w:color w:val="FF0000"/></w:rPr><w:t xml:space="preserve">red</w:t></w:r><w:r w:rsidR="00AB"><w:rPr><w:color w:val="000000"/></w:rPr><w:t xml:space="preserve"></w:t></w:r>`,
+		},
+		{
+			name:              "Multiple values in one run",
+			inputXml:          `<w:r><w:t>` + RichTextStart + `<w:r><w:rPr></w:rPr><w:t>a</w:t></w:r>` + RichTextEnd + `-` + RichTextStart + `<w:r><w:rPr></w:rPr><w:t>b</w:t></w:r>` + RichTextEnd + `</w:t></w:r>`,
+			expectedOutputXml: `<w:r><w:t></w:t></w:r><w:r><w:rPr></w:rPr><w:t>a</w:t></w:r><w:r><w:t xml:space="preserve">-</w:t></w:r><w:r><w:rPr></w:rPr><w:t>b</w:t></w:r><w:r><w:t xml:space="preserve"></w:t></w:r>`,
+		},
+		{
+			name:              "Values outside of run text become plain text",
+			inputXml:          `<w:p w:val="` + RichTextStart + `<w:r><w:rPr><w:b/></w:rPr><w:t>bold</w:t></w:r>` + RichTextEnd + `"></w:p>`,
+			expectedOutputXml: `<w:p w:val="bold"></w:p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			outputXml := expandRichText(tt.inputXml)
+			assert.Equal(tt.expectedOutputXml, outputXml)
+		})
+	}
+}
+
+func TestMergeRunProperties(t *testing.T) {
+	assert.Equal(t,
+		`<w:rStyle w:val="Hyperlink"/><w:rFonts w:ascii="Arial"></w:rFonts><w:b/><w:color w:val="0563C1"/><w:u w:val="single"/>`,
+		mergeRunProperties(`<w:rFonts w:ascii="Arial"></w:rFonts><w:color w:val="000000"/><w:u w:val="none"/>`, `<w:u w:val="single"/><w:b/><w:color w:val="0563C1"/><w:rStyle w:val="Hyperlink"/>`),
+	)
+}
+
+func TestStripRichText(t *testing.T) {
+	assert.Equal(t, "Hello World!", StripRichText(`Hello `+RichTextStart+`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">World</w:t></w:r>`+RichTextEnd+`!`))
+}
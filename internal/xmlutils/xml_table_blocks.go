@@ -0,0 +1,167 @@
+package xmlutils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// conditionalTagRegex matches a single tag opening an if or with block
+var conditionalTagRegex = regexp.MustCompile(`^\{\{-?\s*(?:if|with)\b[^{}]*\}\}$`)
+
+// endTagRegex matches a single end tag
+var endTagRegex = regexp.MustCompile(`^\{\{-?\s*end\s*-?\}\}$`)
+
+// paragraphStartRegex matches the start of a paragraph, including empty self-closing ones
+var paragraphStartRegex = regexp.MustCompile(`<w:p[ >/]`)
+
+// replaceBlockRows replaces table rows whose only content is control tags with
+// the bare tags, so {{if}}, {{with}} and {{range}} rows control whole rows and
+// rows in false blocks are removed instead of being left empty. When an {{if}}
+// or {{with}} block wraps a whole table, either as its first and last rows or as
+// the first paragraph of its first cell and the last paragraph of its last cell,
+// the block is moved outside the table so the table is removed as a whole.
+func replaceBlockRows(xmlString string) string {
+	if !blockControlTagRegex.MatchString(xmlString) {
+		return xmlString
+	}
+	return replaceElements(xmlString, "tbl", replaceBlockRowsInTable)
+}
+
+func replaceBlockRowsInTable(table string) string {
+	if !blockControlTagRegex.MatchString(table) {
+		return table
+	}
+
+	// Handle nested tables first
+	table = replaceCellContents(table, replaceBlockRows)
+
+	table = replaceElements(table, "tr", func(row string) string {
+		if tags, ok := controlTagsOnly(row); ok {
+			return tags
+		}
+		return row
+	})
+
+	var openTags, endTags []string
+	for {
+		open, end, ok := tableBlockTags(table)
+		if !ok {
+			break
+		}
+		table = table[:open.start] + open.replacement + table[open.end:end.start] + end.replacement + table[end.end:]
+		openTags = append(openTags, open.tag)
+		endTags = append([]string{end.tag}, endTags...)
+	}
+
+	return strings.Join(openTags, "") + table + strings.Join(endTags, "")
+}
+
+// tableBlockTag is a block tag wrapping a table, the span it is moved out of and the text to leave in its place
+type tableBlockTag struct {
+	tag         string
+	start, end  int
+	replacement string
+}
+
+// tableBlockTags finds an {{if}} or {{with}} block wrapping all the rows of a table
+func tableBlockTags(table string) (tableBlockTag, tableBlockTag, bool) {
+	rows := elementSpans(table, "tr")
+	if len(rows) == 0 {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+
+	// Bare tags left by rows that only held the tags
+	if open, ok := blockTagIn(table, 0, rows[0][0], conditionalTagRegex, false); ok {
+		if end, ok := blockTagIn(table, rows[len(rows)-1][1], len(table), endTagRegex, true); ok && wrapsBlock(table, open, end) {
+			return open, end, true
+		}
+	}
+
+	// Tags alone in the first paragraph of the first cell and the last paragraph of the last cell
+	firstRow := table[rows[0][0]:rows[0][1]]
+	lastRow := table[rows[len(rows)-1][0]:rows[len(rows)-1][1]]
+	firstCells := elementSpans(firstRow, "tc")
+	lastCells := elementSpans(lastRow, "tc")
+	if len(firstCells) == 0 || len(lastCells) == 0 {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+	firstCellStart := rows[0][0] + firstCells[0][0]
+	lastCellStart := rows[len(rows)-1][0] + lastCells[len(lastCells)-1][0]
+	firstCell := table[firstCellStart : rows[0][0]+firstCells[0][1]]
+	lastCell := table[lastCellStart : rows[len(rows)-1][0]+lastCells[len(lastCells)-1][1]]
+
+	firstParagraphs := elementSpans(firstCell, "p")
+	lastParagraphs := elementSpans(lastCell, "p")
+	if len(firstParagraphs) == 0 || len(lastParagraphs) == 0 {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+	open := tableBlockTag{start: firstCellStart + firstParagraphs[0][0], end: firstCellStart + firstParagraphs[0][1]}
+	end := tableBlockTag{start: lastCellStart + lastParagraphs[len(lastParagraphs)-1][0], end: lastCellStart + lastParagraphs[len(lastParagraphs)-1][1]}
+	if open.start == end.start {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+
+	openTag, ok := controlTagsOnly(table[open.start:open.end])
+	if !ok || !conditionalTagRegex.MatchString(openTag) {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+	endTag, ok := controlTagsOnly(table[end.start:end.end])
+	if !ok || !endTagRegex.MatchString(endTag) {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+
+	// Cells must keep at least one paragraph
+	if !paragraphStartRegex.MatchString(strings.Replace(firstCell, table[open.start:open.end], "", 1)) {
+		open.replacement = "<w:p/>"
+	}
+	if !paragraphStartRegex.MatchString(strings.Replace(lastCell, table[end.start:end.end], "", 1)) {
+		end.replacement = "<w:p/>"
+	}
+
+	if !wrapsBlock(table, open, end) {
+		return tableBlockTag{}, tableBlockTag{}, false
+	}
+	open.tag = openTag
+	end.tag = endTag
+	return open, end, true
+}
+
+// blockTagIn returns the first block tag between start and end, or the last one
+// with last set, if it matches tagRegex
+func blockTagIn(table string, start, end int, tagRegex *regexp.Regexp, last bool) (tableBlockTag, bool) {
+	matches := blockControlTagRegex.FindAllStringIndex(table[start:end], -1)
+	if len(matches) == 0 {
+		return tableBlockTag{}, false
+	}
+	match := matches[0]
+	if last {
+		match = matches[len(matches)-1]
+	}
+	tag := tableBlockTag{tag: table[start+match[0] : start+match[1]], start: start + match[0], end: start + match[1]}
+	if !tagRegex.MatchString(tag.tag) {
+		return tableBlockTag{}, false
+	}
+	return tag, true
+}
+
+// wrapsBlock reports whether the open and end tags enclose a complete block with
+// balanced blocks before, inside and after it
+func wrapsBlock(table string, open, end tableBlockTag) bool {
+	return blocksBalanced(table[:open.start]) && blocksBalanced(table[open.end:end.start]) && blocksBalanced(table[end.end:])
+}
+
+// blocksBalanced reports whether every block opened in the XML is closed in it
+func blocksBalanced(xmlString string) bool {
+	depth := 0
+	for _, match := range blockBoundaryRegex.FindAllStringSubmatch(xmlString, -1) {
+		if match[1] == "end" {
+			depth--
+			if depth < 0 {
+				return false
+			}
+		} else {
+			depth++
+		}
+	}
+	return depth == 0
+}
@@ -0,0 +1,75 @@
+package xmlutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceBlockRows(t *testing.T) {
+	tests := []struct {
+		name              string
+		inputXml          string
+		expectedOutputXml string
+	}{
+		{
+			name:              "If rows",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{if .ShowDiscount}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{.Discount}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc></w:tr>{{if .ShowDiscount}}<w:tr><w:tc><w:p><w:r><w:t>{{.Discount}}</w:t></w:r></w:p></w:tc></w:tr>{{end}}</w:tbl>`,
+		},
+		{
+			name:              "Tags spread over the cells of a row",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc></w:tr><w:tr w:rsidR="00AB"><w:tc><w:p><w:r><w:t>{{with .Tax}}</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr><w:tr><w:tc><w:p/></w:tc><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc></w:tr>{{with .Tax}}<w:tr><w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr>{{end}}</w:tbl>`,
+		},
+		{
+			name:              "Rows with other text are left alone",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .Show}}Discount{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .Show}}Discount{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+		},
+		{
+			name:              "If rows wrapping the whole table",
+			inputXml:          `<w:tbl><w:tblPr/><w:tr><w:tc><w:p><w:r><w:t>{{if .ShowTable}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>A</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `{{if .ShowTable}}<w:tbl><w:tblPr/><w:tr><w:tc><w:p><w:r><w:t>A</w:t></w:r></w:p></w:tc></w:tr></w:tbl>{{end}}`,
+		},
+		{
+			name:              "Range rows wrapping the whole table stay inside",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{range .Items}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl>{{range .Items}}<w:tr><w:tc><w:p><w:r><w:t>{{.}}</w:t></w:r></w:p></w:tc></w:tr>{{end}}</w:tbl>`,
+		},
+		{
+			name:              "Tags in the first and last cell of the table",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .ShowNote}}</w:t></w:r></w:p><w:p><w:r><w:t>Note</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>More</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .ShowNote}}</w:t></w:r></w:p><w:p><w:r><w:t>Note</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>More</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+		},
+		{
+			name:              "Tags in the only cell of a table",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:tcPr/><w:p><w:r><w:t>{{if .ShowNote}}</w:t></w:r></w:p><w:p><w:r><w:t>Note</w:t></w:r></w:p><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `{{if .ShowNote}}<w:tbl><w:tr><w:tc><w:tcPr/><w:p><w:r><w:t>Note</w:t></w:r></w:p></w:tc></w:tr></w:tbl>{{end}}`,
+		},
+		{
+			name:              "Tags in cells keep a paragraph in the cell",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .Show}}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>A</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>B</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `{{if .Show}}<w:tbl><w:tr><w:tc><w:p/></w:tc><w:tc><w:p><w:r><w:t>A</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>B</w:t></w:r></w:p></w:tc><w:tc><w:p/></w:tc></w:tr></w:tbl>{{end}}`,
+		},
+		{
+			name:              "Unbalanced tags are left alone",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .A}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}{{if .B}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl>{{if .A}}{{end}}{{if .B}}{{end}}</w:tbl>`,
+		},
+		{
+			name:              "Nested tables",
+			inputXml:          `<w:tbl><w:tr><w:tc><w:tbl><w:tr><w:tc><w:p><w:r><w:t>{{if .Inner}}</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>X</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:p><w:r><w:t>{{end}}</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p/></w:tc></w:tr></w:tbl>`,
+			expectedOutputXml: `<w:tbl><w:tr><w:tc>{{if .Inner}}<w:tbl><w:tr><w:tc><w:p><w:r><w:t>X</w:t></w:r></w:p></w:tc></w:tr></w:tbl>{{end}}<w:p/></w:tc></w:tr></w:tbl>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			outputXml := replaceBlockRows(tt.inputXml)
+			assert.Equal(tt.expectedOutputXml, outputXml)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package xmlutils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// structureElementRegex matches the opening and closing tags of tables, rows, cells and paragraphs
+var structureElementRegex = regexp.MustCompile(`<(/?)w:(tbl|tr|tc|p)[ >]`)
+
+// elementSpans returns the start and end offsets of the outermost tbl, tr, tc or p elements with the given name
+func elementSpans(xmlString string, name string) [][2]int {
+	var spans [][2]int
+	depth := 0
+	start := 0
+	for _, match := range structureElementRegex.FindAllStringSubmatchIndex(xmlString, -1) {
+		if xmlString[match[4]:match[5]] != name {
+			continue
+		}
+		if match[3] > match[2] {
+			depth--
+			if depth == 0 {
+				spans = append(spans, [2]int{start, match[1]})
+			}
+			if depth < 0 {
+				depth = 0
+			}
+			continue
+		}
+		if depth == 0 {
+			start = match[0]
+		}
+		depth++
+	}
+	return spans
+}
+
+// replaceElements replaces the outermost elements with the given name
+func replaceElements(xmlString string, name string, replace func(string) string) string {
+	spans := elementSpans(xmlString, name)
+	if len(spans) == 0 {
+		return xmlString
+	}
+
+	var result strings.Builder
+	lastEnd := 0
+	for _, span := range spans {
+		result.WriteString(xmlString[lastEnd:span[0]])
+		result.WriteString(replace(xmlString[span[0]:span[1]]))
+		lastEnd = span[1]
+	}
+	result.WriteString(xmlString[lastEnd:])
+	return result.String()
+}
+
+// replaceCellContents replaces the content of the cells of a table, excluding the cell start and end tags
+func replaceCellContents(table string, replace func(string) string) string {
+	return replaceElements(table, "tc", func(cell string) string {
+		contentStart := strings.IndexByte(cell, '>') + 1
+		contentEnd := strings.LastIndex(cell, "</w:tc>")
+		if contentEnd < contentStart {
+			return cell
+		}
+		return cell[:contentStart] + replace(cell[contentStart:contentEnd]) + cell[contentEnd:]
+	})
+}
@@ -0,0 +1,76 @@
+package docxtpl
+
+import (
+	"context"
+
+	"github.com/abdokhaire/go-docxgen/internal/tags"
+)
+
+// =============================================================================
+// Render Limits
+// =============================================================================
+
+// Limits bound the time, output, loop iterations and functions templates may
+// use, for rendering templates written by users. Zero values don't limit.
+type Limits = tags.Limits
+
+// LimitError is returned, possibly wrapped in a *TemplateError, when rendering
+// exceeds a limit.
+type LimitError = tags.LimitError
+
+// LimitKind is the limit rendering exceeded.
+type LimitKind = tags.LimitKind
+
+const (
+	// LimitTimeout is exceeded when rendering takes longer than Limits.Timeout.
+	LimitTimeout = tags.LimitTimeout
+	// LimitOutput is exceeded when a part renders to more than
+	// Limits.MaxOutputBytes.
+	LimitOutput = tags.LimitOutput
+	// LimitIterations is exceeded when the loops of a part run more than
+	// Limits.MaxIterations times.
+	LimitIterations = tags.LimitIterations
+	// LimitFunc is exceeded when a template calls a function missing from
+	// Limits.AllowedFuncs.
+	LimitFunc = tags.LimitFunc
+)
+
+// SetLimits sets the limits of every render of the document, and of the
+// templates compiled from it afterwards.
+//
+//	doc.SetLimits(docxtpl.Limits{
+//		Timeout:        5 * time.Second,
+//		MaxOutputBytes: 10 << 20,
+//		MaxIterations:  100000,
+//		AllowedFuncs:   []string{"upper", "lower", "formatDate"},
+//	})
+func (d *DocxTmpl) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// RenderContext replaces the placeholders in the document like Render,
+// stopping once the context is done. The error is then the context's cause,
+// such as context.Canceled, or a *LimitError when the time limit has passed.
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+//	defer cancel()
+//	err := doc.RenderContext(ctx, data)
+//	var limitErr *docxtpl.LimitError
+//	if errors.As(err, &limitErr) {
+//		// the template exceeded the limits
+//	}
+func (d *DocxTmpl) RenderContext(ctx context.Context, data any) error {
+	return d.RenderContextWithOptions(ctx, data, RenderOptions{})
+}
+
+// RenderContextWithOptions replaces the placeholders in the document like
+// RenderWithOptions, stopping once the context is done like RenderContext.
+//
+//	err := doc.RenderContextWithOptions(ctx, data, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+func (d *DocxTmpl) RenderContextWithOptions(ctx context.Context, data any, opts RenderOptions) error {
+	parts, err := d.compileParts(opts)
+	if err != nil {
+		return err
+	}
+	return d.renderParts(ctx, parts, data)
+}
@@ -0,0 +1,137 @@
+package docxtpl
+
+import (
+	"fmt"
+
+	"github.com/abdokhaire/go-docxgen/internal/functions"
+	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+	"golang.org/x/text/language"
+)
+
+// =============================================================================
+// Locale - Localized Numbers, Amounts and Dates
+// =============================================================================
+
+const settingsPath = "word/settings.xml"
+
+// SetLocale sets the locale, a BCP 47 language tag such as "de-DE", of the
+// localNumber, localPercent, localCurrency and localDate template functions.
+// An empty locale restores the default, the language of the document.
+//
+//	if err := doc.SetLocale("de-DE"); err != nil {
+//		return err
+//	}
+//	err := doc.Render(data) // {{localCurrency "EUR" .Total}} → 1.234,56 €
+func (d *DocxTmpl) SetLocale(locale string) error {
+	if locale == "" {
+		d.locale = language.Und
+		return nil
+	}
+	tag, err := parseLocale(locale)
+	if err != nil {
+		return err
+	}
+	d.locale = tag
+	return nil
+}
+
+// Locale returns the locale used for rendering: the one set with SetLocale,
+// or else the language of the document's default text in styles.xml or
+// settings.xml, or else en-US.
+//
+//	fmt.Println(doc.Locale()) // de-DE
+func (d *DocxTmpl) Locale() string {
+	return d.currentLocale().String()
+}
+
+// parseLocale parses a BCP 47 language tag
+func parseLocale(locale string) (language.Tag, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid locale %q: %w", locale, err)
+	}
+	return tag, nil
+}
+
+// currentLocale returns the locale set with SetLocale or the document's
+// language
+func (d *DocxTmpl) currentLocale() language.Tag {
+	if d.locale != language.Und {
+		return d.locale
+	}
+	if tag, err := language.Parse(d.documentLanguage()); err == nil && tag != language.Und {
+		return tag
+	}
+	return language.AmericanEnglish
+}
+
+// documentLanguage returns the language set in the document's styles or
+// settings, or an empty string
+func (d *DocxTmpl) documentLanguage() string {
+	styles, _, err := d.partContent(stylesPath)
+	if err != nil {
+		return ""
+	}
+	var settings string
+	for _, pf := range d.processableFiles {
+		if headerfooter.IsSettings(pf.Name) {
+			settings = pf.Content
+		}
+	}
+	if settings == "" {
+		settings, _, _ = d.partContent(settingsPath)
+	}
+	return xmlutils.DocumentLanguage(styles, settings)
+}
+
+// localeFormatter returns the formatter of the render in progress, or of the
+// current locale
+func (d *DocxTmpl) localeFormatter() *functions.LocaleFormatter {
+	if d.formatter != nil {
+		return d.formatter
+	}
+	return functions.NewLocaleFormatter(d.currentLocale())
+}
+
+// localNumber is the localNumber template function.
+//
+//	{{localNumber 2 .Total}} → 1.234,50 (de-DE), 1,234.50 (en-US)
+func (d *DocxTmpl) localNumber(decimals int, value any) (string, error) {
+	return d.localeFormatter().Number(decimals, value)
+}
+
+// localPercent is the localPercent template function.
+//
+//	{{localPercent 1 .Rate}} → 12,5 % (de-DE), 12.5% (en-US)
+func (d *DocxTmpl) localPercent(decimals int, value any) (string, error) {
+	return d.localeFormatter().Percent(decimals, value)
+}
+
+// localCurrency is the localCurrency template function.
+//
+//	{{localCurrency "EUR" .Total}} → 1.234,50 € (de-DE), €1,234.50 (en-US)
+func (d *DocxTmpl) localCurrency(code string, value any) (string, error) {
+	return d.localeFormatter().Currency(code, value)
+}
+
+// localDate is the localDate template function.
+//
+//	{{localDate "long" .Date}} → 5. März 2024 (de-DE), March 5, 2024 (en-US)
+func (d *DocxTmpl) localDate(layout string, value any) (string, error) {
+	return d.localeFormatter().Date(layout, value)
+}
+
+// formatNumber is the formatNumber function of the Word function library.
+//
+//	{{formatNumber 2 .Total}} → 1.234,50 (de-DE), 1,234.50 (en-US)
+func (d *DocxTmpl) formatNumber(decimals int, value any) (string, error) {
+	return d.localeFormatter().FormatNumber(decimals, value)
+}
+
+// formatCurrency is the formatCurrency function of the Word function library.
+//
+//	{{formatCurrency "€" .Total}} → 1.234,50 € (de-DE), €1,234.50 (en-US)
+func (d *DocxTmpl) formatCurrency(symbol string, value any) (string, error) {
+	return d.localeFormatter().FormatCurrency(symbol, value)
+}
@@ -0,0 +1,493 @@
+package docxtpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/abdokhaire/go-docxgen/internal/contenttypes"
+	"github.com/abdokhaire/go-docxgen/internal/docx"
+	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
+	"github.com/abdokhaire/go-docxgen/internal/hyperlinks"
+)
+
+// =============================================================================
+// Streaming Mail Merge
+// =============================================================================
+
+// MailMergeSink opens the writer a rendered record is saved to. It is only
+// called for records that rendered, with their index in the records, and the
+// writer is closed once the document is written. With a parallelism above one
+// it is called concurrently.
+//
+//	sink := func(i int) (io.WriteCloser, error) {
+//		return os.Create(fmt.Sprintf("letters/%05d.docx", i+1))
+//	}
+type MailMergeSink func(i int) (io.WriteCloser, error)
+
+// MailMergeOptions configures MailMergeStream.
+type MailMergeOptions struct {
+	// Parallelism is the number of records rendered at once,
+	// runtime.GOMAXPROCS(0) when zero or less.
+	Parallelism int
+	// Render holds the options every record is rendered with.
+	Render RenderOptions
+	// Progress, when set, is called after each record, never concurrently.
+	Progress func(MailMergeProgress)
+}
+
+// MailMergeProgress reports a record a mail merge has finished.
+type MailMergeProgress struct {
+	Index  int   // index of the record
+	Err    error // error of the record, nil when it was written
+	Done   int   // records finished so far, including failed ones
+	Failed int   // records that failed so far
+}
+
+// RecordError is the error of a record of a mail merge.
+type RecordError struct {
+	Index int
+	Err   error
+}
+
+func (e RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Index, e.Err)
+}
+
+func (e RecordError) Unwrap() error {
+	return e.Err
+}
+
+// MailMergeError lists the records that failed in a mail merge, in record
+// order. The other records were written.
+type MailMergeError struct {
+	Records []RecordError
+}
+
+func (e *MailMergeError) Error() string {
+	if len(e.Records) == 1 {
+		return "mail merge failed for " + e.Records[0].Error()
+	}
+	return fmt.Sprintf("mail merge failed for %d records, first %v", len(e.Records), e.Records[0])
+}
+
+// Unwrap returns the errors of the records, for errors.Is and errors.As.
+func (e *MailMergeError) Unwrap() []error {
+	errs := make([]error, len(e.Records))
+	for i, record := range e.Records {
+		errs[i] = record
+	}
+	return errs
+}
+
+// RecordsOf returns the records of a slice for MailMergeStream.
+//
+//	err := doc.MailMergeStream(ctx, docxtpl.RecordsOf(customers), sink, docxtpl.MailMergeOptions{})
+func RecordsOf[T any](records []T) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for _, record := range records {
+			if !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+// MailMergeStream renders the template once per record and saves each
+// document to the writer the sink opens for it, as records are read. The
+// template is compiled once and records are rendered by a pool of workers, so
+// only the documents being rendered are held in memory.
+//
+// A record that fails doesn't stop the others: the failures are returned
+// together as a *MailMergeError. When the context is canceled no more records
+// are read, the records being rendered stop and the context's error is
+// returned, joined with the failures.
+//
+//	err := doc.MailMergeStream(ctx, docxtpl.RecordsOf(customers), func(i int) (io.WriteCloser, error) {
+//		return os.Create(fmt.Sprintf("letters/%05d.docx", i+1))
+//	}, docxtpl.MailMergeOptions{
+//		Parallelism: 8,
+//		Progress: func(p docxtpl.MailMergeProgress) {
+//			log.Printf("%d done, %d failed", p.Done, p.Failed)
+//		},
+//	})
+func (d *DocxTmpl) MailMergeStream(ctx context.Context, records iter.Seq[any], sink MailMergeSink, opts MailMergeOptions) error {
+	compiled, err := d.CompileWithOptions(opts.Render)
+	if err != nil {
+		return fmt.Errorf("failed to compile template: %w", err)
+	}
+
+	workers := opts.Parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		mu       sync.Mutex
+		progress MailMergeProgress
+		failed   []RecordError
+	)
+	finish := func(index int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress.Index, progress.Err = index, err
+		progress.Done++
+		if err != nil {
+			progress.Failed++
+			failed = append(failed, RecordError{Index: index, Err: err})
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	type job struct {
+		index  int
+		record any
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				finish(job.index, compiled.writeRecord(ctx, job.index, job.record, sink))
+			}
+		}()
+	}
+
+	index := 0
+	for record := range records {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- job{index: index, record: record}:
+			index++
+			continue
+		case <-ctx.Done():
+		}
+		break
+	}
+	close(jobs)
+	wg.Wait()
+
+	var mergeErr error
+	if len(failed) > 0 {
+		slices.SortFunc(failed, func(a, b RecordError) int { return a.Index - b.Index })
+		mergeErr = &MailMergeError{Records: failed}
+	}
+	if ctx.Err() != nil {
+		return errors.Join(ctx.Err(), mergeErr)
+	}
+	return mergeErr
+}
+
+// writeRecord renders a record and saves it to the writer the sink opens for
+// it
+func (c *CompiledTemplate) writeRecord(ctx context.Context, index int, record any, sink MailMergeSink) error {
+	doc, err := c.render(ctx, record)
+	if err != nil {
+		return err
+	}
+	w, err := sink(index)
+	if err != nil {
+		return err
+	}
+	if err := doc.Save(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// =============================================================================
+// Mail Merge Into a Single Document
+// =============================================================================
+
+const (
+	headerRelationType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+	footerRelationType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+
+	headerContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"
+	footerContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+)
+
+// pageCountFieldRegex matches the NUMPAGES field instructions of a part
+var pageCountFieldRegex = regexp.MustCompile(`((?:<w:instrText[^>]*>|w:instr=")[^<"]*?)\bNUMPAGES\b`)
+
+// SectionBreak is the kind of break starting each record of a mail merge into
+// a single document.
+type SectionBreak string
+
+const (
+	// SectionNextPage starts each record on a new page.
+	SectionNextPage SectionBreak = "nextPage"
+	// SectionOddPage starts each record on an odd page, so every record
+	// starts on the front of a sheet when printing on both sides.
+	SectionOddPage SectionBreak = "oddPage"
+)
+
+// SingleMergeOptions configures MailMergeToSingleWithOptions.
+type SingleMergeOptions struct {
+	// Break is the section break starting each record, SectionNextPage when
+	// empty.
+	Break SectionBreak
+	// ContinuePageNumbers numbers the pages through the whole document instead
+	// of restarting at 1 for each record.
+	ContinuePageNumbers bool
+	// Render holds the options every record is rendered with.
+	Render RenderOptions
+}
+
+// MailMergeToSingleWithOptions renders the template once per record into a
+// single document in which each record is a section of its own. Each record
+// starts on a new page with the template's page setup and its own headers and
+// footers, rendered with the record's data. Page numbers restart at 1 for each
+// record and NUMPAGES fields in headers and footers count the pages of the
+// record, so "Page 1 of 2" is right for every letter.
+//
+//	merged, err := template.MailMergeToSingleWithOptions(records, docxtpl.SingleMergeOptions{
+//		Break: docxtpl.SectionOddPage,
+//	})
+func (d *DocxTmpl) MailMergeToSingleWithOptions(records []map[string]any, opts SingleMergeOptions) (*DocxTmpl, error) {
+	if len(records) == 0 {
+		return d.Clone()
+	}
+	if opts.Break == "" {
+		opts.Break = SectionNextPage
+	}
+
+	compiled, err := d.CompileWithOptions(opts.Render)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile template: %w", err)
+	}
+
+	var merged *DocxTmpl
+	var items []any
+	var last *docx.SectPr
+	for i, record := range records {
+		doc, err := compiled.Render(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render record %d: %w", i, err)
+		}
+
+		var recordItems []any
+		var ids map[string]string
+		if merged == nil {
+			merged = doc
+			recordItems = doc.Document.Body.Items
+			if !opts.ContinuePageNumbers {
+				for j, pf := range merged.processableFiles {
+					if headerfooter.IsHeaderOrFooter(pf.Name) {
+						merged.processableFiles[j].Content = sectionPageCounts(pf.Content)
+					}
+				}
+			}
+		} else {
+			if ids, err = merged.copyHeadersFooters(doc, opts); err != nil {
+				return nil, fmt.Errorf("failed to merge record %d: %w", i, err)
+			}
+
+			// Links added while rendering the record are only written to its relationships on save
+			links := make(map[string]string)
+			for url, id := range doc.hyperlinkReg.GetLinks() {
+				links[id] = url
+			}
+			recordItems = merged.Docx.CopyBodyItems(doc.Docx, links)
+			merged.contentTypes.MergeDefaults(doc.contentTypes)
+			items = endSection(items, last)
+		}
+		items, last = appendSections(items, recordItems, ids, opts)
+	}
+
+	merged.Document.Body.Items = append(items, last)
+	return merged, nil
+}
+
+// copyHeadersFooters adds the headers and footers of a rendered record to the
+// document as new parts, with the media they show, and returns the
+// relationship ids of the new parts by the record's ids
+func (d *DocxTmpl) copyHeadersFooters(record *DocxTmpl, opts SingleMergeOptions) (map[string]string, error) {
+	ids := make(map[string]string)
+	err := record.Docx.RangeRelationships(func(rel *docx.Relationship) error {
+		var kind, contentType string
+		switch rel.Type {
+		case headerRelationType:
+			kind, contentType = "header", headerContentType
+		case footerRelationType:
+			kind, contentType = "footer", footerContentType
+		default:
+			return nil
+		}
+		name := "word/" + rel.Target
+		if strings.HasPrefix(rel.Target, "/") {
+			name = strings.TrimPrefix(rel.Target, "/")
+		}
+		content := record.getProcessableFileContent(name)
+		if content == "" {
+			return nil
+		}
+
+		partName := d.unusedPartName(kind)
+		if !opts.ContinuePageNumbers {
+			content = sectionPageCounts(content)
+		}
+		d.setPartContent(partName, content)
+		d.contentTypes.AddOverride(contenttypes.Override{PartName: "/" + partName, ContentType: contentType})
+
+		relsContent, hasRels, err := record.partContent(hyperlinks.GetRelsPath(name))
+		if err != nil {
+			return err
+		}
+		if hasRels {
+			relsXml, err := d.copyPartMedia(record, relsContent)
+			if err != nil {
+				return err
+			}
+			d.setPartContent(hyperlinks.GetRelsPath(partName), relsXml)
+		}
+
+		ids[rel.ID] = d.Docx.AddRelation(rel.Type, path.Base(partName))
+		return nil
+	})
+	return ids, err
+}
+
+// copyPartMedia adds the images related from a part of another document to
+// this document's media, unless this document has the same image under the
+// same name, and returns the part's relationships pointing to them
+func (d *DocxTmpl) copyPartMedia(other *DocxTmpl, relsContent string) (string, error) {
+	var rels hyperlinks.Relationships
+	if err := xml.Unmarshal([]byte(relsContent), &rels); err != nil {
+		return "", err
+	}
+	for i, rel := range rels.Relationships {
+		name, isMedia := strings.CutPrefix(rel.Target, "media/")
+		if !isMedia || rel.TargetMode != "" {
+			continue
+		}
+		media := other.Docx.Media(name)
+		if media == nil {
+			continue
+		}
+		if own := d.Docx.Media(name); own != nil && bytes.Equal(own.Data, media.Data) {
+			continue
+		}
+		rels.Relationships[i].Target = d.Docx.AddMedia(strings.TrimPrefix(path.Ext(name), "."), media.Data)
+	}
+	return rels.ToXML()
+}
+
+// unusedPartName returns the name of a header or footer part the document
+// doesn't have yet
+func (d *DocxTmpl) unusedPartName(kind string) string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("word/%s%d.xml", kind, n)
+		if _, ok := d.partOverrides[name]; !ok && d.getProcessableFileContent(name) == "" {
+			return name
+		}
+	}
+}
+
+// appendSections appends the body items of a record, whose sections start
+// with the break of the options and refer to the headers and footers with the
+// ids, returning the items and the properties of the record's last section
+func appendSections(items, record []any, ids map[string]string, opts SingleMergeOptions) ([]any, *docx.SectPr) {
+	first := true
+	section := func(sect *docx.SectPr) *docx.SectPr {
+		sect = recordSection(sect, ids)
+		if first {
+			sect.Type = &docx.SectType{Val: string(opts.Break)}
+			if !opts.ContinuePageNumbers {
+				numbering := docx.PgNumType{}
+				if sect.PgNumType != nil {
+					numbering = *sect.PgNumType
+				}
+				start := 1
+				numbering.Start = &start
+				sect.PgNumType = &numbering
+			}
+			first = false
+		}
+		return sect
+	}
+
+	var last *docx.SectPr
+	for _, item := range record {
+		switch o := item.(type) {
+		case *docx.SectPr:
+			last = section(o)
+			continue
+		case *docx.Paragraph:
+			if o.Properties != nil && o.Properties.SectPr != nil {
+				p, props := *o, *o.Properties
+				props.SectPr = section(o.Properties.SectPr)
+				p.Properties = &props
+				item = &p
+			}
+		}
+		items = append(items, item)
+	}
+	if last == nil {
+		last = section(&docx.SectPr{})
+	}
+	return items, last
+}
+
+// recordSection returns a copy of section properties referring to the headers
+// and footers with the new ids
+func recordSection(sect *docx.SectPr, ids map[string]string) *docx.SectPr {
+	section := *sect
+	section.HeaderReferences = make([]*docx.HeaderReference, len(sect.HeaderReferences))
+	for i, ref := range sect.HeaderReferences {
+		section.HeaderReferences[i] = &docx.HeaderReference{Type: ref.Type, ID: ref.ID}
+		if id, ok := ids[ref.ID]; ok {
+			section.HeaderReferences[i].ID = id
+		}
+	}
+	section.FooterReferences = make([]*docx.FooterReference, len(sect.FooterReferences))
+	for i, ref := range sect.FooterReferences {
+		section.FooterReferences[i] = &docx.FooterReference{Type: ref.Type, ID: ref.ID}
+		if id, ok := ids[ref.ID]; ok {
+			section.FooterReferences[i].ID = id
+		}
+	}
+	return &section
+}
+
+// endSection ends a section after the items with its properties, held by the
+// last paragraph or by an empty paragraph after a table
+func endSection(items []any, sect *docx.SectPr) []any {
+	if len(items) > 0 {
+		if p, ok := items[len(items)-1].(*docx.Paragraph); ok && (p.Properties == nil || p.Properties.SectPr == nil) {
+			paragraph := *p
+			var props docx.ParagraphProperties
+			if p.Properties != nil {
+				props = *p.Properties
+			}
+			props.SectPr = sect
+			paragraph.Properties = &props
+			items[len(items)-1] = &paragraph
+			return items
+		}
+	}
+	return append(items, &docx.Paragraph{Properties: &docx.ParagraphProperties{SectPr: sect}})
+}
+
+// sectionPageCounts replaces the NUMPAGES fields of a part with SECTIONPAGES
+// fields counting the pages of the section
+func sectionPageCounts(content string) string {
+	return pageCountFieldRegex.ReplaceAllString(content, "${1}SECTIONPAGES")
+}
@@ -0,0 +1,260 @@
+package docxtpl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+// Markdown Parsing
+// =============================================================================
+
+var (
+	// markdownHeadingRegex matches an ATX heading such as "## Title"
+	markdownHeadingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	// markdownListItemRegex matches a bullet or numbered list item with its indentation
+	markdownListItemRegex = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	// markdownRuleRegex matches a thematic break such as "---"
+	markdownRuleRegex = regexp.MustCompile(`^([-*_])(?:\s*[-*_]){2,}$`)
+	// markdownTableSeparatorRegex matches the line separating a table's header from its body
+	markdownTableSeparatorRegex = regexp.MustCompile(`^\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?$`)
+	// markdownLinkRegex matches an inline link at the start of text
+	markdownLinkRegex = regexp.MustCompile(`^\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+)
+
+// markdownEscapable are the characters that can be escaped with a backslash
+const markdownEscapable = "\\`*_{}[]()#+-.!|~"
+
+// parseMarkdown parses headings, paragraphs, bullet and numbered lists, pipe
+// tables and inline formatting. Other syntax is kept as text.
+func parseMarkdown(source string) []markupBlock {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var blocks []markupBlock
+	var paragraph strings.Builder
+	var numbers []int  // last number at each list level, 0 for bullets
+	listItemText := "" // source of the last list item, extended by continuation lines
+
+	flush := func() {
+		if text := strings.TrimSpace(paragraph.String()); text != "" {
+			blocks = append(blocks, markupBlock{kind: markupParagraph, text: parseMarkdownInline(text)})
+		}
+		paragraph.Reset()
+	}
+	endList := func() {
+		numbers = nil
+		listItemText = ""
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case markdownHeadingRegex.MatchString(trimmed):
+			flush()
+			endList()
+			match := markdownHeadingRegex.FindStringSubmatch(trimmed)
+			blocks = append(blocks, markupBlock{kind: markupHeading, level: len(match[1]), text: parseMarkdownInline(match[2])})
+
+		case markdownRuleRegex.MatchString(trimmed):
+			flush()
+			endList()
+
+		case markdownListItemRegex.MatchString(line):
+			flush()
+			match := markdownListItemRegex.FindStringSubmatch(line)
+			level := min(indentWidth(match[1])/2, 8)
+			numbers = numbers[:min(len(numbers), level+1)]
+			for len(numbers) <= level {
+				numbers = append(numbers, 0)
+			}
+
+			prefix := getBulletPrefix(ListTypeBullet, level)
+			if marker := match[2]; marker[0] >= '0' && marker[0] <= '9' {
+				if numbers[level] == 0 {
+					numbers[level], _ = strconv.Atoi(marker[:len(marker)-1])
+				} else {
+					numbers[level]++
+				}
+				prefix = fmt.Sprintf("%d.", numbers[level])
+			} else {
+				numbers[level] = 0
+			}
+
+			listItemText = match[3]
+			blocks = append(blocks, markupBlock{kind: markupListItem, level: level, prefix: prefix, text: parseMarkdownInline(listItemText)})
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && markdownTableSeparatorRegex.MatchString(strings.TrimSpace(lines[i+1])):
+			flush()
+			endList()
+			rows := [][]*RichText{markdownTableCells(trimmed)}
+			i += 2
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, markdownTableCells(strings.TrimSpace(lines[i])))
+			}
+			i--
+			blocks = append(blocks, markupBlock{kind: markupTable, rows: rows, header: true})
+
+		case listItemText != "" && paragraph.Len() == 0 && line != trimmed:
+			// Indented lines continue the last list item
+			listItemText += " " + trimmed
+			blocks[len(blocks)-1].text = parseMarkdownInline(listItemText)
+
+		default:
+			endList()
+			// Lines ending in two spaces or a backslash are hard line breaks
+			if strings.HasSuffix(line, "  ") || strings.HasSuffix(trimmed, "\\") {
+				paragraph.WriteString(strings.TrimSuffix(trimmed, "\\") + "\n")
+			} else {
+				paragraph.WriteString(trimmed + " ")
+			}
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// indentWidth returns the width of leading whitespace, counting tabs as four spaces
+func indentWidth(indent string) int {
+	return len(strings.ReplaceAll(indent, "\t", "    "))
+}
+
+// markdownTableCells parses the cells of a pipe table row
+func markdownTableCells(line string) []*RichText {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	line = strings.ReplaceAll(line, `\|`, "\x00")
+
+	var cells []*RichText
+	for _, cell := range strings.Split(line, "|") {
+		cells = append(cells, parseMarkdownInline(strings.ReplaceAll(strings.TrimSpace(cell), "\x00", "|")))
+	}
+	return cells
+}
+
+// markdownStyle is the inline formatting in effect while parsing Markdown text
+type markdownStyle struct {
+	bold, italic, strike, code bool
+}
+
+// apply applies the style to the last fragment of the rich text
+func (s markdownStyle) apply(r *RichText) {
+	if s.bold {
+		r.Bold()
+	}
+	if s.italic {
+		r.Italic()
+	}
+	if s.strike {
+		r.Strike()
+	}
+	if s.code {
+		r.Font("Courier New")
+	}
+}
+
+// parseMarkdownInline parses bold, italic, strikethrough, code spans, links,
+// backslash escapes and line breaks into rich text
+func parseMarkdownInline(text string) *RichText {
+	r := NewRichText()
+	var current strings.Builder
+	var style markdownStyle
+
+	emit := func() {
+		if current.Len() == 0 {
+			return
+		}
+		r.AddText(current.String())
+		style.apply(r)
+		current.Reset()
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.IndexByte(markdownEscapable, rest[1]) >= 0:
+			current.WriteByte(rest[1])
+			i += 2
+
+		case rest[0] == '\n':
+			emit()
+			r.Break()
+			i++
+
+		case rest[0] == '`' && strings.IndexByte(rest[1:], '`') >= 0:
+			end := strings.IndexByte(rest[1:], '`') + 1
+			emit()
+			current.WriteString(rest[1:end])
+			style.code = true
+			emit()
+			style.code = false
+			i += end + 1
+
+		case rest[0] == '[' && markdownLinkRegex.MatchString(rest):
+			match := markdownLinkRegex.FindStringSubmatch(rest)
+			emit()
+			for _, fragment := range parseMarkdownInline(match[1]).fragments {
+				r.fragments = append(r.fragments, fragment)
+				style.apply(r)
+				r.Link(match[2])
+			}
+			i += len(match[0])
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if !style.bold && !strings.Contains(rest[2:], rest[:2]) {
+				current.WriteString(rest[:2])
+			} else {
+				emit()
+				style.bold = !style.bold
+			}
+			i += 2
+
+		case strings.HasPrefix(rest, "~~"):
+			if !style.strike && !strings.Contains(rest[2:], "~~") {
+				current.WriteString("~~")
+			} else {
+				emit()
+				style.strike = !style.strike
+			}
+			i += 2
+
+		case rest[0] == '*' || rest[0] == '_':
+			// Underscores inside words such as snake_case are kept as text
+			intraword := rest[0] == '_' && isWordRuneBefore(text, i) && isWordRuneAfter(text, i+1)
+			if intraword || (!style.italic && !strings.Contains(rest[1:], rest[:1])) {
+				current.WriteByte(rest[0])
+			} else {
+				emit()
+				style.italic = !style.italic
+			}
+			i++
+
+		default:
+			current.WriteByte(rest[0])
+			i++
+		}
+	}
+	emit()
+
+	return r
+}
+
+// isWordRuneBefore reports whether the rune before position i is a letter or digit
+func isWordRuneBefore(text string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return i > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// isWordRuneAfter reports whether the rune at position i is a letter or digit
+func isWordRuneAfter(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return i < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,165 @@
+package docxtpl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// Markup - Markdown and HTML Fragments
+// =============================================================================
+
+// markupBlockKind is the kind of a block parsed from Markdown or HTML
+type markupBlockKind int
+
+const (
+	markupParagraph markupBlockKind = iota
+	markupHeading
+	markupListItem
+	markupTable
+)
+
+// markupBlock is a paragraph, heading, list item or table parsed from Markdown or HTML
+type markupBlock struct {
+	kind   markupBlockKind
+	level  int    // heading level (1-6) or list nesting level (0-8)
+	prefix string // list bullet or number
+	text   *RichText
+	rows   [][]*RichText // table cells
+	header bool          // the first table row is a header row
+}
+
+// markdownContent is the markdown template function. It renders Markdown text
+// as Word content at the placeholder position.
+//
+//	{{markdown .Clause}}
+func (d *DocxTmpl) markdownContent(value any) (string, error) {
+	return d.markupXml(parseMarkdown(markupSource(value)))
+}
+
+// htmlContent is the htmlContent template function. It renders simple HTML as
+// Word content at the placeholder position. It isn't named html so the
+// escaping function of text/template keeps working.
+//
+//	{{htmlContent .Note}}
+func (d *DocxTmpl) htmlContent(value any) (string, error) {
+	blocks, err := parseHTML(markupSource(value))
+	if err != nil {
+		return "", err
+	}
+	return d.markupXml(blocks)
+}
+
+// paragraphsContent is the paragraphs template function. It splits text at
+// blank lines into paragraphs with the formatting of the placeholder's
+// paragraph and run, such as list numbering and indentation.
+//
+//	{{paragraphs .Note}}
+func paragraphsContent(value any) (string, error) {
+	return xmlutils.EscapeParagraphs(markupSource(value))
+}
+
+// markupSource returns the original text of a template value.
+// Data strings are XML escaped before rendering so they are unescaped here.
+func markupSource(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return xmlutils.UnescapeXmlString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// markupXml renders parsed blocks. A single paragraph becomes rich text that
+// flows into the paragraph holding the placeholder. Anything else is built with
+// the document builder on a scratch document and returned as block content, which
+// splits the paragraph holding the placeholder.
+func (d *DocxTmpl) markupXml(blocks []markupBlock) (string, error) {
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	if len(blocks) == 1 && blocks[0].kind == markupParagraph {
+		return d.richTextXml(blocks[0].text)
+	}
+
+	content := newBlockContent()
+	for _, block := range blocks {
+		switch block.kind {
+		case markupHeading:
+			content.scratch.AddHeading(content.token(block.text), block.level)
+		case markupListItem:
+			content.scratch.addPrefixedListParagraph(block.prefix, content.token(block.text), block.level)
+		case markupTable:
+			cols := 0
+			for _, row := range block.rows {
+				cols = max(cols, len(row))
+			}
+			table := content.scratch.AddTable(len(block.rows), cols)
+			for r, row := range block.rows {
+				for c := range cols {
+					text := NewRichText()
+					if c < len(row) {
+						text = row[c]
+					}
+					cell := table.SetCell(r, c, content.token(text))
+					if r == 0 && block.header {
+						cell.Bold()
+					}
+				}
+			}
+		default:
+			content.scratch.AddParagraph(content.token(block.text))
+		}
+	}
+
+	return content.xml(d)
+}
+
+// blockContent builds block content on a scratch document with the document
+// builder. Text is added as tokens that are replaced by rich text runs after
+// marshalling, so it keeps its formatting and links.
+type blockContent struct {
+	scratch *DocxTmpl
+	texts   []*RichText
+}
+
+func newBlockContent() *blockContent {
+	return &blockContent{scratch: New()}
+}
+
+// token returns the placeholder text for rich text
+func (b *blockContent) token(text *RichText) string {
+	b.texts = append(b.texts, text)
+	return fmt.Sprintf("{docxtplMarkup%d}", len(b.texts)-1)
+}
+
+// xml marshals the scratch document's body as block content, registering
+// links with d
+func (b *blockContent) xml(d *DocxTmpl) (string, error) {
+	replacements := make([]string, 0, 2*len(b.texts))
+	for i, text := range b.texts {
+		textXml, err := d.richTextXml(text)
+		if err != nil {
+			return "", err
+		}
+		replacements = append(replacements, fmt.Sprintf("{docxtplMarkup%d}", i), textXml)
+	}
+
+	var out strings.Builder
+	out.WriteString(xmlutils.BlockContentStart)
+	for _, item := range b.scratch.Document.Body.Items {
+		itemXml, err := xml.Marshal(item)
+		if err != nil {
+			return "", err
+		}
+		out.Write(itemXml)
+	}
+	out.WriteString(xmlutils.BlockContentEnd)
+
+	return strings.NewReplacer(replacements...).Replace(out.String()), nil
+}
@@ -2,11 +2,15 @@ package docxtpl
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/abdokhaire/go-docxgen/internal/docx"
 )
@@ -189,7 +193,8 @@ func (d *DocxTmpl) SplitAtRegex(pattern string) []*DocxTmpl {
 // =============================================================================
 
 // MailMerge renders the template with multiple data records.
-// Returns a slice of rendered documents, one per record.
+// Returns a slice of rendered documents, one per record. The template is
+// compiled once for all the records.
 //
 //	records := []map[string]any{
 //	    {"Name": "John", "Email": "john@example.com"},
@@ -197,71 +202,182 @@ func (d *DocxTmpl) SplitAtRegex(pattern string) []*DocxTmpl {
 //	}
 //	docs, err := template.MailMerge(records)
 func (d *DocxTmpl) MailMerge(records []map[string]any) ([]*DocxTmpl, error) {
-	var results []*DocxTmpl
+	if len(records) == 0 {
+		return nil, nil
+	}
 
-	for i, record := range records {
-		// Clone the template
-		clone, err := d.Clone()
-		if err != nil {
-			return nil, fmt.Errorf("failed to clone for record %d: %w", i, err)
-		}
+	compiled, err := d.Compile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile template: %w", err)
+	}
 
+	results := make([]*DocxTmpl, len(records))
+	for i, record := range records {
 		// Render with this record's data
-		err = clone.Render(record)
+		results[i], err = compiled.Render(record)
 		if err != nil {
 			return nil, fmt.Errorf("failed to render record %d: %w", i, err)
 		}
-
-		results = append(results, clone)
 	}
 
 	return results, nil
 }
 
 // MailMergeToFiles renders the template and saves each result to a file.
-// filenamePattern should contain %d for the record number (e.g., "letter_%d.docx").
+// filenamePattern should contain %d for the record number (e.g., "letter_%d.docx"),
+// or placeholders for fields of the record (e.g., "letters/{{.CustomerID}}.docx").
+// Text values are cleaned of path separators and other characters not allowed
+// in file names, and records whose file names are the same are rejected before
+// any file is written. Records are rendered and saved as they go, in parallel,
+// and records that fail don't stop the others (see MailMergeStream). The file
+// of a record that fails while being saved is deleted.
 //
 //	err := template.MailMergeToFiles(records, "output/letter_%d.docx")
 func (d *DocxTmpl) MailMergeToFiles(records []map[string]any, filenamePattern string) error {
-	docs, err := d.MailMerge(records)
+	recordFilename, err := filenameTemplate(filenamePattern)
 	if err != nil {
 		return err
 	}
 
-	for i, doc := range docs {
-		filename := fmt.Sprintf(filenamePattern, i+1)
+	// Records are named up front, so two records never write the same file
+	filenames := make([]string, len(records))
+	nameErrs := make([]error, len(records))
+	named := make(map[string]int, len(records))
+	for i, record := range records {
+		filenames[i], nameErrs[i] = recordFilename(i, record)
+		if nameErrs[i] != nil {
+			continue
+		}
+		key := filepath.Clean(filenames[i])
+		if first, ok := named[key]; ok {
+			return fmt.Errorf("records %d and %d are both named %s", first+1, i+1, filenames[i])
+		}
+		named[key] = i
+	}
+
+	var (
+		mu      sync.Mutex
+		created = make(map[int]string)
+	)
+	removeFailed := func(p MailMergeProgress) {
+		if p.Err == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if filename, ok := created[p.Index]; ok {
+			os.Remove(filename)
+		}
+	}
+
+	return d.MailMergeStream(context.Background(), RecordsOf(records), func(i int) (io.WriteCloser, error) {
+		filename := filenames[i]
+		if nameErrs[i] != nil {
+			return nil, nameErrs[i]
+		}
 
 		// Ensure directory exists
 		dir := filepath.Dir(filename)
 		if dir != "" && dir != "." {
 			if err := os.MkdirAll(dir, 0755); err != nil {
-				return fmt.Errorf("failed to create directory for %s: %w", filename, err)
+				return nil, fmt.Errorf("failed to create directory for %s: %w", filename, err)
 			}
 		}
 
-		if err := doc.SaveToFile(filename); err != nil {
-			return fmt.Errorf("failed to save %s: %w", filename, err)
+		f, err := os.Create(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", filename, err)
 		}
+		mu.Lock()
+		created[i] = filename
+		mu.Unlock()
+		return f, nil
+	}, MailMergeOptions{Progress: removeFailed})
+}
+
+// filenameNumberRegex matches the %d of a file name pattern holding
+// placeholders, skipping those within the placeholders
+var filenameNumberRegex = regexp.MustCompile(`\{\{.*?\}\}|%d`)
+
+// filenameNumberKey is the key of the record number in the data of file name
+// patterns holding placeholders
+const filenameNumberKey = "%d"
+
+// filenameTemplate returns the file name of each record for a pattern holding
+// %d for the record number and placeholders for fields of the record. The
+// pattern is parsed once, with %d reading the record number from the data.
+func filenameTemplate(pattern string) (func(i int, record map[string]any) (string, error), error) {
+	if !strings.Contains(pattern, "{{") {
+		return func(i int, _ map[string]any) (string, error) {
+			return fmt.Sprintf(pattern, i+1), nil
+		}, nil
 	}
 
-	return nil
+	text := filenameNumberRegex.ReplaceAllStringFunc(pattern, func(match string) string {
+		if match == "%d" {
+			return fmt.Sprintf("{{index . %q}}", filenameNumberKey)
+		}
+		return match
+	})
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file name pattern: %w", err)
+	}
+	return func(i int, record map[string]any) (string, error) {
+		values := filenameValues(record).(map[string]any)
+		values[filenameNumberKey] = i + 1
+
+		var filename strings.Builder
+		if err := tmpl.Execute(&filename, values); err != nil {
+			return "", fmt.Errorf("failed to name file: %w", err)
+		}
+		return filename.String(), nil
+	}, nil
+}
+
+// filenameUnsafeRegex matches the characters of text values that can't be part of a file name
+var filenameUnsafeRegex = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]`)
+
+// filenameValues returns the record with the characters of its text values
+// that can't be part of a file name replaced by underscores
+func filenameValues(value any) any {
+	switch v := value.(type) {
+	case string:
+		v = filenameUnsafeRegex.ReplaceAllString(v, "_")
+		if strings.Trim(v, ".") == "" {
+			v = strings.ReplaceAll(v, ".", "_")
+		}
+		return v
+	case map[string]any:
+		values := make(map[string]any, len(v))
+		for key, item := range v {
+			values[key] = filenameValues(item)
+		}
+		return values
+	case []map[string]any:
+		values := make([]any, len(v))
+		for i, item := range v {
+			values[i] = filenameValues(item)
+		}
+		return values
+	case []any:
+		values := make([]any, len(v))
+		for i, item := range v {
+			values[i] = filenameValues(item)
+		}
+		return values
+	}
+	return value
 }
 
 // MailMergeToSingle renders the template with multiple records and combines
-// into a single document with page breaks between each.
+// them into a single document, each record starting a new section on a new
+// page with its own headers, footers and page numbering (see
+// MailMergeToSingleWithOptions).
 //
 //	merged, err := template.MailMergeToSingle(records)
 func (d *DocxTmpl) MailMergeToSingle(records []map[string]any) (*DocxTmpl, error) {
-	if len(records) == 0 {
-		return d.Clone()
-	}
-
-	docs, err := d.MailMerge(records)
-	if err != nil {
-		return nil, err
-	}
-
-	return MergeDocuments(docs...)
+	return d.MailMergeToSingleWithOptions(records, SingleMergeOptions{})
 }
 
 // =============================================================================
@@ -292,17 +408,23 @@ func BatchRender(templates []*DocxTmpl, dataList []any) ([]*DocxTmpl, error) {
 	}
 
 	results := make([]*DocxTmpl, len(templates))
+	compiled := make(map[*DocxTmpl]*CompiledTemplate)
 	for i, tmpl := range templates {
-		clone, err := tmpl.Clone()
-		if err != nil {
-			return nil, fmt.Errorf("failed to clone template %d: %w", i, err)
+		// Templates listed several times are compiled once
+		if compiled[tmpl] == nil {
+			c, err := tmpl.Compile()
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile template %d: %w", i, err)
+			}
+			compiled[tmpl] = c
 		}
 
-		if err := clone.Render(dataList[i]); err != nil {
+		result, err := compiled[tmpl].Render(dataList[i])
+		if err != nil {
 			return nil, fmt.Errorf("failed to render template %d: %w", i, err)
 		}
 
-		results[i] = clone
+		results[i] = result
 	}
 
 	return results, nil
@@ -866,10 +988,13 @@ func (d *DocxTmpl) addNestedListItems(list *List, items []ListItem, listType Lis
 
 // addListParagraph creates a paragraph with list formatting
 func (d *DocxTmpl) addListParagraph(text string, listType ListType, level int) *Paragraph {
-	p := d.Docx.AddParagraph()
-
 	// Create the bullet/number prefix based on list type and level
-	prefix := getBulletPrefix(listType, level)
+	return d.addPrefixedListParagraph(getBulletPrefix(listType, level), text, level)
+}
+
+// addPrefixedListParagraph creates a list paragraph with the given bullet or number prefix
+func (d *DocxTmpl) addPrefixedListParagraph(prefix, text string, level int) *Paragraph {
+	p := d.Docx.AddParagraph()
 
 	// Add prefix as a separate run
 	prefixRun := p.AddText(prefix + "\t")
@@ -0,0 +1,104 @@
+package docxtpl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/abdokhaire/go-docxgen/internal/tags"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// Partials
+// =============================================================================
+
+// partial is a reusable template snippet
+type partial struct {
+	text string // plain text in the document's delimiters, for registered partials
+	xml  string // WordprocessingML with the standard delimiters, for loaded partials
+}
+
+// RegisterPartial registers a snippet of plain text that every part of the
+// document (body, headers, footers, footnotes) can use with
+// {{template "name" .}}. The text is written with the document's delimiters
+// and newlines become line breaks. A {{define}} block of the same name in a
+// part wins over the partial.
+//
+//	doc.RegisterPartial("address", "{{.Company}}\n{{.Street}}\n{{.City}}")
+//	err := doc.Render(data) // {{template "address" .Sender}}
+func (d *DocxTmpl) RegisterPartial(name, text string) error {
+	if name == "" {
+		return errors.New("partial name is empty")
+	}
+	if err := tags.CheckPartialSyntax(name, xmlutils.TranslateTextDelimiters(text, d.delims)); err != nil {
+		return fmt.Errorf("partial %q: %w", name, err)
+	}
+	d.setPartial(name, partial{text: text})
+	return nil
+}
+
+// LoadPartials registers the {{define "name"}}...{{end}} blocks in the body of
+// another document as partials, keeping their formatting. Blocks whose tags
+// sit alone in their own paragraphs insert whole paragraphs and tables; blocks
+// within the text of a paragraph insert runs that take on the formatting of
+// the tag using them. Pictures and hyperlinks in the blocks aren't copied.
+//
+//	library, err := docxtpl.ParseFromFilename("snippets.docx")
+//	if err != nil {
+//		return err
+//	}
+//	err = doc.LoadPartials(library)
+func (d *DocxTmpl) LoadPartials(library *DocxTmpl) error {
+	documentXmlString, err := library.templateDocumentXml()
+	if err != nil {
+		return err
+	}
+	preparedXmlString, err := xmlutils.PrepareXmlForTagReplacement(documentXmlString)
+	if err != nil {
+		return err
+	}
+	if err := tags.CheckPartialSyntax("library", preparedXmlString); err != nil {
+		return fmt.Errorf("error parsing partials: %w", err)
+	}
+
+	definitions, err := xmlutils.ExtractDefinitions(preparedXmlString)
+	if err != nil {
+		return err
+	}
+	if len(definitions) == 0 {
+		return errors.New("no {{define}} blocks found in the document")
+	}
+	for name, definition := range definitions {
+		d.setPartial(name, partial{xml: definition})
+	}
+	return nil
+}
+
+// setPartial adds or replaces a partial
+func (d *DocxTmpl) setPartial(name string, p partial) {
+	if d.partials == nil {
+		d.partials = make(map[string]partial)
+	}
+	d.partials[name] = p
+}
+
+// partialTemplates returns the partials as templates for tag replacement
+func (d *DocxTmpl) partialTemplates() (map[string]string, error) {
+	if len(d.partials) == 0 {
+		return nil, nil
+	}
+
+	templates := make(map[string]string, len(d.partials))
+	for name, p := range d.partials {
+		if p.xml != "" {
+			templates[name] = p.xml
+			continue
+		}
+		escaped, err := xmlutils.EscapeTemplateText(xmlutils.TranslateTextDelimiters(p.text, d.delims))
+		if err != nil {
+			return nil, err
+		}
+		templates[name] = escaped
+	}
+	return templates, nil
+}
@@ -0,0 +1,175 @@
+package docxtpl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/headerfooter"
+	"github.com/abdokhaire/go-docxgen/internal/tags"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// Lazy Data Resolution
+// =============================================================================
+
+// Resolver loads the values of the fields a template uses, for data too costly
+// to load in full. Passed as the data of Render, RenderWithOptions,
+// RenderContext or a compiled template, it is asked once per render for each
+// field path the document uses, from the root: {{.Customer.Name}} asks for
+// "Customer", then for "Customer.Name" when "Customer" is nil. A value that
+// isn't nil ends the path, and fields within it are read from it like any
+// other data, so {{.Date.Format "2006"}} asks for "Date" only. Values are
+// converted like the data of Render, so methods of structs are only kept for
+// types such as time.Time and TemplateValuer implementations.
+//
+// Fields used within {{range}} and {{with}} blocks are fields of the values
+// the blocks iterate over or select: {{range .Orders}}{{.Total}}{{end}} asks
+// for "Orders" only, which should hold every field of the orders.
+//
+// A nil value for a whole path is a missing field, handled according to the
+// MissingKey mode. Errors are returned as a *TemplateError pointing at the
+// first tag using the field.
+type Resolver interface {
+	Resolve(path string) (any, error)
+}
+
+// ResolverFunc is a function used as a Resolver.
+//
+//	err := doc.Render(docxtpl.ResolverFunc(func(path string) (any, error) {
+//		switch path {
+//		case "Customer":
+//			return db.LoadCustomer(ctx, id)
+//		case "Orders":
+//			return db.LoadOrders(ctx, id)
+//		}
+//		return nil, nil
+//	}))
+type ResolverFunc func(path string) (any, error)
+
+// Resolve calls the function.
+func (f ResolverFunc) Resolve(path string) (any, error) {
+	return f(path)
+}
+
+// partField is a field used by a part of the document
+type partField struct {
+	tags.FieldReference
+	part string
+}
+
+// resolveFields asks the resolver for the field paths the parts use, from
+// the root of each path until a value isn't nil, returning the values as the
+// data of the render
+func (d *DocxTmpl) resolveFields(ctx context.Context, parts *compiledParts, resolver Resolver) (map[string]any, error) {
+	fields, err := d.partFields(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any)
+	resolved := make(map[string]any)
+	for _, field := range fields {
+		names := strings.Split(field.Path, ".")
+		values := data
+		for i, name := range names {
+			path := strings.Join(names[:i+1], ".")
+			value, ok := resolved[path]
+			if !ok {
+				if ctx.Err() != nil {
+					return nil, context.Cause(ctx)
+				}
+				if value, err = resolver.Resolve(path); err != nil {
+					return nil, d.restoreDelimiters(newResolveError(path, field, err))
+				}
+				resolved[path] = value
+			}
+
+			// Fields within a value, and its methods, are read from the value
+			if value != nil {
+				values[name] = value
+				break
+			}
+			if i == len(names)-1 {
+				if _, ok := values[name]; !ok {
+					values[name] = nil
+				}
+				break
+			}
+			child, ok := values[name].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				values[name] = child
+			}
+			values = child
+		}
+	}
+	return data, nil
+}
+
+// partFields returns the fields used by the parts of the document, in the
+// order of the parts, including those of placeholder pictures and watermarks
+func (d *DocxTmpl) partFields(parts *compiledParts) ([]partField, error) {
+	var fields []partField
+	addFields := func(part string, tmpl *tags.Template) {
+		for _, field := range tmpl.Fields() {
+			fields = append(fields, partField{FieldReference: field, part: partName(part)})
+		}
+	}
+
+	for _, part := range append([]*compiledPart{parts.body}, parts.files...) {
+		tmpl := part.tmpl
+		if tmpl == nil {
+			// Parts with placeholder pictures are parsed on each render
+			_, err := xmlutils.ReplacePictures(part.content, func(picture *xmlutils.Picture) (bool, error) {
+				if names := pictureFields(picture); names != nil {
+					path := strings.Join(names, ".")
+					fields = append(fields, partField{FieldReference: tags.FieldReference{Path: path, Tag: "{{." + path + "}}"}, part: partName(part.name)})
+				}
+				return false, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if tmpl, err = d.parsePart(part.name, part.content, parts.tagOpts); err != nil {
+				return nil, d.restoreDelimiters(newRenderError(err, partName(part.name), nil))
+			}
+		}
+		addFields(part.name, tmpl)
+
+		if headerfooter.IsHeaderOrFooter(part.name) {
+			_, err := headerfooter.ProcessWatermarkTemplates(part.content, func(watermarkText string) (string, error) {
+				tmpl, err := tags.ParseText(watermarkText, d.funcMap, parts.tagOpts)
+				if err != nil {
+					return "", err
+				}
+				addFields(part.name, tmpl)
+				return watermarkText, nil
+			})
+			if err != nil {
+				return nil, d.restoreDelimiters(newRenderError(err, partName(part.name), nil))
+			}
+		}
+	}
+	return fields, nil
+}
+
+// newResolveError returns the error of a resolver for a path as a
+// TemplateError pointing at the first tag using the field
+func newResolveError(path string, field partField, err error) error {
+	te := &TemplateError{
+		Code:        ErrCodeResolveError,
+		Message:     fmt.Sprintf("failed to resolve %s: %v", path, err),
+		Location:    field.part,
+		Placeholder: field.Tag,
+		Cause:       err,
+	}
+	if field.Location != nil {
+		if position := field.Location.String(); position != "" {
+			te.Location = field.part + ", " + position
+		}
+		te.Snippet = field.Location.Snippet
+	}
+	return te
+}
@@ -0,0 +1,229 @@
+package docxtpl
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// RichText - Formatted Inline Template Values
+// =============================================================================
+
+// RichText is a template value made of formatted text fragments. When it replaces
+// a placeholder, each fragment becomes its own run that inherits the formatting of
+// the placeholder's run, with the fragment's own formatting applied on top.
+// Formatting methods apply to the most recently added fragment and can be chained.
+//
+//	amount := docxtpl.NewRichText().
+//		AddText("Overdue: ").
+//		AddText("$120.00").Bold().Color("FF0000")
+//	doc.Render(map[string]any{"Amount": amount})
+type RichText struct {
+	fragments []richTextFragment
+}
+
+// richTextFragment is a piece of rich text with its run properties
+type richTextFragment struct {
+	text       string
+	properties []runPropertyElement
+	link       string
+	lineBreak  bool
+}
+
+// runPropertyElement is a single run property such as <w:b/>
+type runPropertyElement struct {
+	name string
+	xml  string
+}
+
+// NewRichText creates an empty rich text value. Add text with AddText.
+//
+//	rt := docxtpl.NewRichText().AddText("Hello ").AddText("World").Bold()
+func NewRichText() *RichText {
+	return &RichText{}
+}
+
+// TemplateValue keeps rich text values intact when template data is converted.
+func (r *RichText) TemplateValue() any {
+	return r
+}
+
+// AddText adds a text fragment. Following formatting methods apply to it.
+func (r *RichText) AddText(text string) *RichText {
+	r.fragments = append(r.fragments, richTextFragment{text: text})
+	return r
+}
+
+// Break adds a line break.
+func (r *RichText) Break() *RichText {
+	r.fragments = append(r.fragments, richTextFragment{lineBreak: true})
+	return r
+}
+
+// Bold applies bold formatting to the last fragment.
+func (r *RichText) Bold() *RichText {
+	return r.setProperty("b", `<w:b/>`)
+}
+
+// Italic applies italic formatting to the last fragment.
+func (r *RichText) Italic() *RichText {
+	return r.setProperty("i", `<w:i/>`)
+}
+
+// Underline applies underline formatting to the last fragment.
+// Common values: "single", "double", "thick", "dotted", "dash", "wave"
+func (r *RichText) Underline(style ...string) *RichText {
+	val := "single"
+	if len(style) > 0 {
+		val = style[0]
+	}
+	return r.setProperty("u", fmt.Sprintf(`<w:u w:val="%s"/>`, escapeAttr(val)))
+}
+
+// Strike applies strikethrough formatting to the last fragment.
+func (r *RichText) Strike() *RichText {
+	return r.setProperty("strike", `<w:strike/>`)
+}
+
+// Color sets the text color of the last fragment.
+// Use hex color code without # (e.g., "FF0000" for red).
+func (r *RichText) Color(hexColor string) *RichText {
+	return r.setProperty("color", fmt.Sprintf(`<w:color w:val="%s"/>`, escapeAttr(hexColor)))
+}
+
+// Size sets the font size of the last fragment.
+// Size is in half-points (e.g., 24 = 12pt).
+func (r *RichText) Size(halfPoints int) *RichText {
+	r.setProperty("sz", fmt.Sprintf(`<w:sz w:val="%d"/>`, halfPoints))
+	return r.setProperty("szCs", fmt.Sprintf(`<w:szCs w:val="%d"/>`, halfPoints))
+}
+
+// SizePoints sets the font size of the last fragment in points.
+func (r *RichText) SizePoints(points int) *RichText {
+	return r.Size(points * 2)
+}
+
+// Font sets the font family of the last fragment.
+func (r *RichText) Font(fontName string) *RichText {
+	name := escapeAttr(fontName)
+	return r.setProperty("rFonts", fmt.Sprintf(`<w:rFonts w:ascii="%s" w:eastAsia="%s" w:hAnsi="%s" w:cs="%s"/>`, name, name, name, name))
+}
+
+// Highlight applies a highlight color to the last fragment.
+// Valid colors: yellow, green, cyan, magenta, blue, red, darkBlue, darkCyan,
+// darkGreen, darkMagenta, darkRed, darkYellow, darkGray, lightGray, black
+func (r *RichText) Highlight(color string) *RichText {
+	return r.setProperty("highlight", fmt.Sprintf(`<w:highlight w:val="%s"/>`, escapeAttr(color)))
+}
+
+// Link turns the last fragment into a hyperlink to the URL.
+//
+//	rt.AddText("our website").Link("https://example.com")
+func (r *RichText) Link(url string) *RichText {
+	if fragment := r.last(); fragment != nil {
+		fragment.link = url
+	}
+	return r
+}
+
+// Text returns the plain text of the rich text value.
+func (r *RichText) Text() string {
+	var text strings.Builder
+	for _, fragment := range r.fragments {
+		if fragment.lineBreak {
+			text.WriteString("\n")
+			continue
+		}
+		text.WriteString(fragment.text)
+	}
+	return text.String()
+}
+
+// last returns the most recently added text fragment
+func (r *RichText) last() *richTextFragment {
+	for i := len(r.fragments) - 1; i >= 0; i-- {
+		if !r.fragments[i].lineBreak {
+			return &r.fragments[i]
+		}
+	}
+	return nil
+}
+
+// setProperty sets a run property on the last fragment, replacing any property with the same name
+func (r *RichText) setProperty(name, xml string) *RichText {
+	fragment := r.last()
+	if fragment == nil {
+		return r
+	}
+	for i, property := range fragment.properties {
+		if property.name == name {
+			fragment.properties[i].xml = xml
+			return r
+		}
+	}
+	fragment.properties = append(fragment.properties, runPropertyElement{name: name, xml: xml})
+	return r
+}
+
+// hyperlinkProperties are the default run properties of hyperlink fragments
+var hyperlinkProperties = []runPropertyElement{
+	{name: "rStyle", xml: `<w:rStyle w:val="Hyperlink"/>`},
+	{name: "color", xml: `<w:color w:val="0563C1"/>`},
+	{name: "u", xml: `<w:u w:val="single"/>`},
+}
+
+// richTextXml renders a rich text value as runs between rich text markers,
+// registering the relationships of its hyperlinks.
+func (d *DocxTmpl) richTextXml(r *RichText) (string, error) {
+	var out strings.Builder
+	out.WriteString(xmlutils.RichTextStart)
+
+	for _, fragment := range r.fragments {
+		if fragment.lineBreak {
+			out.WriteString(`<w:r><w:rPr></w:rPr><w:br/></w:r>`)
+			continue
+		}
+
+		text, err := xmlutils.EscapeXmlString(fragment.text)
+		if err != nil {
+			return "", err
+		}
+
+		properties := fragment.properties
+		if fragment.link != "" {
+			properties = append(append([]runPropertyElement{}, hyperlinkProperties...), properties...)
+		}
+		var propertiesXml strings.Builder
+		for i, property := range properties {
+			// Later properties override earlier ones with the same name
+			overridden := false
+			for _, later := range properties[i+1:] {
+				if later.name == property.name {
+					overridden = true
+					break
+				}
+			}
+			if !overridden {
+				propertiesXml.WriteString(property.xml)
+			}
+		}
+
+		run := `<w:r><w:rPr>` + propertiesXml.String() + `</w:rPr><w:t xml:space="preserve">` + text + `</w:t></w:r>`
+		if fragment.link != "" {
+			rId := d.hyperlinkReg.RegisterLink(fragment.link)
+			run = `<w:hyperlink r:id="` + rId + `" w:history="1">` + run + `</w:hyperlink>`
+		}
+		out.WriteString(run)
+	}
+
+	out.WriteString(xmlutils.RichTextEnd)
+	return out.String(), nil
+}
+
+// escapeAttr escapes a value for use in an XML attribute
+func escapeAttr(value string) string {
+	return html.EscapeString(value)
+}
@@ -0,0 +1,160 @@
+package docxtpl
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/abdokhaire/go-docxgen/internal/contenttypes"
+	"github.com/abdokhaire/go-docxgen/internal/docx"
+	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
+)
+
+// =============================================================================
+// Sub-documents
+// =============================================================================
+
+const (
+	stylesPath    = "word/styles.xml"
+	numberingPath = "word/numbering.xml"
+
+	numberingRelationType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	numberingContentType  = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+)
+
+// imageReferenceRegex matches the relationship id of an embedded image
+var imageReferenceRegex = regexp.MustCompile(`r:embed="([^"]*)"`)
+
+// TemplateValue keeps a document intact when template data is converted, so it
+// can be embedded as a sub-document.
+//
+//	appendix, _ := docxtpl.ParseFromFilename("appendix.docx")
+//	doc.Render(map[string]any{"Appendix": appendix}) // {{.Appendix}}
+func (d *DocxTmpl) TemplateValue() any {
+	return d
+}
+
+// subDocumentXml copies the body of another document for insertion at a
+// placeholder. Its media and hyperlinks are added to this document and the
+// styles and numbering definitions it uses are merged into this document's.
+// The content replaces the paragraph holding the placeholder. The sub-document's
+// page setup, headers and footers aren't copied.
+func (d *DocxTmpl) subDocumentXml(sub *DocxTmpl) (string, error) {
+	if sub == d {
+		return "", errors.New("a document cannot be embedded in itself")
+	}
+
+	// Links added while rendering the sub-document are only written to its relationships on save
+	links := make(map[string]string)
+	for url, id := range sub.hyperlinkReg.GetLinks() {
+		links[id] = url
+	}
+
+	var content strings.Builder
+	for _, item := range d.Docx.CopyBodyItems(sub.Docx, links) {
+		if _, ok := item.(*docx.SectPr); ok {
+			continue
+		}
+		itemXml, err := xml.Marshal(item)
+		if err != nil {
+			return "", err
+		}
+		content.Write(itemXml)
+	}
+
+	body, err := d.mergeDefinitions(sub, content.String())
+	if err != nil {
+		return "", err
+	}
+	d.contentTypes.MergeDefaults(sub.contentTypes)
+
+	// Documents built in memory may not have content types for their images yet
+	media := &contenttypes.ContentTypes{}
+	for _, match := range imageReferenceRegex.FindAllStringSubmatch(body, -1) {
+		target, err := d.Docx.ReferTarget(match[1])
+		if err != nil {
+			continue
+		}
+		if contentType, ok := contenttypes.ImageContentType(strings.TrimPrefix(path.Ext(target), ".")); ok {
+			media.Defaults = append(media.Defaults, contentType)
+		}
+	}
+	d.contentTypes.MergeDefaults(media)
+
+	return xmlutils.BlockContentStart + body + xmlutils.BlockContentEnd, nil
+}
+
+// mergeDefinitions adds the styles and numbering definitions used by content
+// from the sub-document to this document, returning the content with its
+// numbering remapped to the merged definitions
+func (d *DocxTmpl) mergeDefinitions(sub *DocxTmpl, content string) (string, error) {
+	subStyles, hasSubStyles, err := sub.partContent(stylesPath)
+	if err != nil {
+		return "", err
+	}
+	styles, hasStyles, err := d.partContent(stylesPath)
+	if err != nil {
+		return "", err
+	}
+	var missingStyles []string
+	if hasSubStyles && hasStyles {
+		missingStyles = xmlutils.MissingStyles(styles, subStyles, xmlutils.StyleIDs(content))
+	}
+
+	subNumbering, hasSubNumbering, err := sub.partContent(numberingPath)
+	if err != nil {
+		return "", err
+	}
+	numIDs := xmlutils.NumberingIDs(content + strings.Join(missingStyles, ""))
+	if hasSubNumbering && len(numIDs) > 0 {
+		numbering, hasNumbering, err := d.partContent(numberingPath)
+		if err != nil {
+			return "", err
+		}
+		if !hasNumbering {
+			numbering = xmlutils.EmptyNumbering
+			d.Docx.AddRelation(numberingRelationType, "numbering.xml")
+			d.contentTypes.AddOverride(contenttypes.Override{PartName: "/" + numberingPath, ContentType: numberingContentType})
+		}
+
+		numbering, ids := xmlutils.MergeNumbering(numbering, subNumbering, numIDs)
+		d.setPartContent(numberingPath, numbering)
+		content = xmlutils.RemapNumberingIDs(content, ids)
+		for i := range missingStyles {
+			missingStyles[i] = xmlutils.RemapNumberingIDs(missingStyles[i], ids)
+		}
+	}
+
+	if len(missingStyles) > 0 {
+		d.setPartContent(stylesPath, xmlutils.AddStyles(styles, subStyles, missingStyles))
+	}
+
+	return content, nil
+}
+
+// partContent returns the current content of an unparsed part and whether the
+// document has the part
+func (d *DocxTmpl) partContent(name string) (string, bool, error) {
+	if content, ok := d.partOverrides[name]; ok {
+		return content, true, nil
+	}
+	content, err := d.Docx.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(content), true, nil
+}
+
+// setPartContent replaces the content of an unparsed part when saving
+func (d *DocxTmpl) setPartContent(name, content string) {
+	if d.partOverrides == nil {
+		d.partOverrides = make(map[string]string)
+	}
+	d.partOverrides[name] = content
+}
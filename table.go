@@ -3,6 +3,7 @@ package docxtpl
 import (
 	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"sort"
 	"strings"
 
@@ -105,6 +106,16 @@ func (t *Table) Center() *Table {
 	return t.Justify("center")
 }
 
+// Style applies a table style defined in the document, such as "TableGrid".
+// The table's own borders are removed so the style's borders and conditional
+// formatting (header row, first column) apply.
+func (t *Table) Style(styleID string) *Table {
+	t.table.Style(styleID)
+	t.table.TableProperties.TableBorders = nil
+	t.table.TableProperties.Look = &docx.WTableLook{Val: "04A0", FirstRow: 1, FirstCol: 1, NoVBand: 1}
+	return t
+}
+
 // GetRaw returns the underlying go-docx table for advanced usage.
 func (t *Table) GetRaw() *docx.Table {
 	return t.table
@@ -156,6 +167,13 @@ func (r *TableRow) Justify(alignment string) *TableRow {
 	return r
 }
 
+// RepeatHeader repeats the row at the top of each page the table spans.
+// Word only repeats rows at the start of the table.
+func (r *TableRow) RepeatHeader() *TableRow {
+	r.row.RepeatHeader()
+	return r
+}
+
 // GetRaw returns the underlying go-docx table row for advanced usage.
 func (r *TableRow) GetRaw() *docx.WTableRow {
 	return r.row
@@ -784,6 +802,136 @@ func (d *DocxTmpl) AddTableWithHeaders(headers []string, data [][]string) *Table
 	return table
 }
 
+// =============================================================================
+// Table Values
+// =============================================================================
+
+// defaultTableWidth is the width of tables created by AddTable, in twips
+const defaultTableWidth = 9360
+
+// TableSpec describes a table generated from data at a placeholder. Pass it as
+// a template value and the paragraph holding the placeholder is replaced by the
+// table.
+//
+//	items := docxtpl.TableSpec{
+//	    Headers:      []string{"Item", "Qty", "Price"},
+//	    Rows:         [][]any{{"Pen", 2, "1.50"}, {"Paper", 1, "4.00"}},
+//	    ColumnWidths: []int{4320, 1440, 1440},
+//	    Style:        "TableGrid",
+//	    RepeatHeader: true,
+//	}
+//	doc.Render(map[string]any{"LineItems": items}) // {{.LineItems}}
+type TableSpec struct {
+	Headers      []string // header row, bold and centered; none when empty
+	Rows         [][]any  // cell values: strings, *RichText or any value formatted with fmt
+	ColumnWidths []int    // column widths in twips; columns without a width share the rest of the page width
+	Style        string   // table style id defined in the document, such as "TableGrid"
+	RepeatHeader bool     // repeat the header row at the top of each page
+}
+
+// TemplateValue keeps the spec intact when template data is converted.
+func (s *TableSpec) TemplateValue() any {
+	return s
+}
+
+// columns returns the number of columns, the widest of the header and rows
+func (s *TableSpec) columns() int {
+	cols := len(s.Headers)
+	for _, row := range s.Rows {
+		cols = max(cols, len(row))
+	}
+	return cols
+}
+
+// columnWidths returns the width of each column in twips
+func (s *TableSpec) columnWidths(cols int) []int {
+	widths := make([]int, cols)
+	used, missing := 0, 0
+	for i := range widths {
+		if i < len(s.ColumnWidths) && s.ColumnWidths[i] > 0 {
+			widths[i] = s.ColumnWidths[i]
+			used += widths[i]
+		} else {
+			missing++
+		}
+	}
+	if missing > 0 {
+		share := 1440 // one inch when the given widths fill the page
+		if used < defaultTableWidth {
+			share = (defaultTableWidth - used) / missing
+		}
+		for i := range widths {
+			if widths[i] == 0 {
+				widths[i] = share
+			}
+		}
+	}
+	return widths
+}
+
+// tableSpecXml builds the table described by the spec as block content
+func (d *DocxTmpl) tableSpecXml(spec *TableSpec) (string, error) {
+	cols := spec.columns()
+	if cols == 0 {
+		return "", nil
+	}
+
+	content := newBlockContent()
+	header := 0
+	if len(spec.Headers) > 0 {
+		header = 1
+	}
+
+	var table *Table
+	if len(spec.ColumnWidths) > 0 {
+		table = content.scratch.AddTableWithWidths(len(spec.Rows)+header, spec.columnWidths(cols))
+	} else {
+		table = content.scratch.AddTable(len(spec.Rows)+header, cols)
+	}
+	if spec.Style != "" {
+		table.Style(spec.Style)
+	}
+
+	if header > 0 {
+		for c := range cols {
+			text := ""
+			if c < len(spec.Headers) {
+				text = spec.Headers[c]
+			}
+			table.SetCell(0, c, content.token(NewRichText().AddText(text))).Bold().Center()
+		}
+		if spec.RepeatHeader {
+			table.Row(0).RepeatHeader()
+		}
+	}
+
+	for r, row := range spec.Rows {
+		for c := range cols {
+			var value any
+			if c < len(row) {
+				value = row[c]
+			}
+			table.SetCell(r+header, c, content.token(tableCellText(value)))
+		}
+	}
+
+	return content.xml(d)
+}
+
+// tableCellText converts a table spec cell value to rich text
+func tableCellText(value any) *RichText {
+	switch v := value.(type) {
+	case nil:
+		return NewRichText()
+	case *RichText:
+		return v
+	case string:
+		return NewRichText().AddText(v)
+	default:
+		return NewRichText().AddText(fmt.Sprint(v))
+	}
+}
+
 // =============================================================================
 // Table Helper Functions
 // =============================================================================
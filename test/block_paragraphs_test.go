@@ -0,0 +1,83 @@
+package docxtpl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockParagraphs(t *testing.T) {
+	t.Run("Should repeat paragraphs and tables between range tags in their own paragraphs", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Report")
+		doc.AddParagraph("{{range .Sections}}")
+		doc.AddHeading("{{.Title}}", 2)
+		doc.AddParagraph("{{.Body}}")
+		doc.AddTableWithHeaders([]string{"Owner"}, [][]string{{"{{.Owner}}"}})
+		doc.AddParagraph("{{end}}")
+		doc.AddParagraph("End")
+
+		err := doc.Render(map[string]any{
+			"Sections": []map[string]any{
+				{"Title": "Intro", "Body": "Hello", "Owner": "Ann"},
+				{"Title": "Outro", "Body": "Bye", "Owner": "Bob"},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, doc.CountTables())
+		assert.Equal(t, []string{
+			"Report",
+			"Intro", "Hello", "|  :----: |", "| Owner |", "| Ann |",
+			"Outro", "Bye", "|  :----: |", "| Owner |", "| Bob |",
+			"End",
+		}, nonEmptyLines(doc.GetText()))
+	})
+
+	t.Run("Should remove paragraphs between false if tags without leaving blanks", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Before")
+		doc.AddParagraph("{{if .Show}}")
+		doc.AddParagraph("Hidden")
+		doc.AddPageBreak()
+		doc.AddParagraph("{{else}}")
+		doc.AddParagraph("Shown")
+		doc.AddParagraph("{{end}}")
+		doc.AddParagraph("After")
+
+		err := doc.Render(map[string]any{"Show": false})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Before\nShown\nAfter", doc.GetText())
+		assert.Equal(t, 3, doc.CountParagraphs())
+	})
+
+	t.Run("Should keep inline block tags within a paragraph", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Items: {{range .Items}}{{.}} {{end}}")
+
+		err := doc.Render(map[string]any{"Items": []string{"a", "b"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Items: a b ", doc.GetText())
+	})
+}
+
+// nonEmptyLines splits text into lines, dropping empty ones
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
+
+// collapseSpaces replaces runs of spaces with a single space
+func collapseSpaces(text string) string {
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool { return r == ' ' }), " ")
+}
@@ -0,0 +1,46 @@
+package docxtpl_test
+
+import (
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnLoops(t *testing.T) {
+	newDoc := func() *docxtpl.DocxTmpl {
+		doc := docxtpl.New()
+		table := doc.AddTableWithWidths(3, []int{2000, 3000})
+		table.SetCell(0, 0, "Region")
+		table.SetCell(0, 1, "Quarters")
+		table.SetCell(1, 1, "{{hrange .Quarters}}{{.}}{{hend}}")
+		table.SetCell(2, 0, "{{.Name}}")
+		table.SetCell(2, 1, "{{hrange $i, $q := .Quarters}}{{$i}}{{hend}}")
+		return doc
+	}
+
+	t.Run("Should add a column per item", func(t *testing.T) {
+		doc := newDoc()
+		err := doc.Render(map[string]any{"Name": "EU", "Quarters": []string{"Q1", "Q2", "Q3"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"| :----: | :----: |",
+			"| Region | Quarters |",
+			"| | Q1 | Q2 | Q3 |",
+			"| EU | 0 | 1 | 2 |",
+		}, nonEmptyLines(collapseSpaces(doc.GetText())))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:tblGrid><w:gridCol w:w="2000"></w:gridCol><w:gridCol w:w="1000"></w:gridCol><w:gridCol w:w="1000"></w:gridCol><w:gridCol w:w="1000"></w:gridCol></w:tblGrid>`)
+		assert.Contains(t, xml, `<w:gridSpan w:val="3">`)
+	})
+
+	t.Run("Should work in strict mode", func(t *testing.T) {
+		doc := newDoc()
+		err := doc.RenderWithOptions(map[string]any{"Name": "EU", "Quarters": []string{"Q1", "Q2"}}, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		require.NoError(t, err)
+		assert.Contains(t, doc.GetText(), "| EU | 0 | 1 |")
+	})
+}
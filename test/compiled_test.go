@@ -0,0 +1,136 @@
+package docxtpl_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executed parses the document written by a compiled template
+func executed(t *testing.T, compiled *docxtpl.CompiledTemplate, data any) *docxtpl.DocxTmpl {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, compiled.Execute(&buf, data))
+	doc, err := docxtpl.ParseFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	return doc
+}
+
+func TestCompiledTemplate(t *testing.T) {
+	t.Run("Should render like Render", func(t *testing.T) {
+		template := func() *docxtpl.DocxTmpl {
+			doc := docxtpl.New()
+			doc.AddParagraph("Dear {{.Name}},")
+			doc.AddParagraph("{{range .Items}}{{.}}; {{end}}")
+			doc.AddParagraph(`{{localNumber 2 .Total}} {{shout .Name}}`)
+			require.NoError(t, doc.RegisterFunction("shout", strings.ToUpper))
+			require.NoError(t, doc.SetLocale("de-DE"))
+			return doc
+		}
+		data := map[string]any{"Name": "Ada", "Items": []string{"a", "b"}, "Total": 1234.5}
+
+		rendered := template()
+		require.NoError(t, rendered.Render(data))
+
+		compiled, err := template().Compile()
+		require.NoError(t, err)
+		assert.Equal(t, rendered.GetText(), executed(t, compiled, data).GetText())
+		assert.Contains(t, rendered.GetText(), "Dear Ada,")
+		assert.Contains(t, rendered.GetText(), "1.234,50 ADA")
+	})
+
+	t.Run("Should render records concurrently", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Dear {{.Name}}, see {{link .URL .Name}}")
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+
+		const records = 20
+		outputs := make([][]byte, records)
+		var wg sync.WaitGroup
+		for i := range records {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var buf bytes.Buffer
+				data := map[string]any{"Name": fmt.Sprintf("Customer %d", i), "URL": fmt.Sprintf("https://example.com/%d", i)}
+				if assert.NoError(t, compiled.Execute(&buf, data)) {
+					outputs[i] = buf.Bytes()
+				}
+			}()
+		}
+		wg.Wait()
+
+		for i, output := range outputs {
+			assert.Contains(t, zippedPart(t, output, "word/document.xml"), fmt.Sprintf("Dear Customer %d, see", i))
+			assert.Contains(t, zippedPart(t, output, "word/_rels/document.xml.rels"), fmt.Sprintf(`Target="https://example.com/%d"`, i))
+		}
+	})
+
+	t.Run("Should not change once compiled", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Hello {{.Name}}")
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+
+		doc.AddParagraph("Added later")
+		require.NoError(t, doc.Render(map[string]any{"Name": "Ada"}))
+
+		text := executed(t, compiled, map[string]any{"Name": "Grace"}).GetText()
+		assert.Contains(t, text, "Hello Grace")
+		assert.NotContains(t, text, "Added later")
+
+		// Rendering doesn't change the compiled template either
+		assert.Contains(t, executed(t, compiled, map[string]any{"Name": "Alan"}).GetText(), "Hello Alan")
+	})
+
+	t.Run("Should replace placeholder pictures on each render", func(t *testing.T) {
+		doc := pictureTemplate(t, "{{.Logo}}", pictureXml(false, "Picture 1", "{{.Logo}}", "rId91"))
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+
+		for _, name := range []string{"test_image.png", "test_image.jpg"} {
+			data, err := os.ReadFile("testdata/templates/" + name)
+			require.NoError(t, err)
+			logo, err := docxtpl.CreateInlineImageFromBytes(data, name[strings.LastIndex(name, "."):])
+			require.NoError(t, err)
+
+			rendered, err := compiled.Render(map[string]any{"Logo": logo})
+			require.NoError(t, err)
+			target, _, _ := pictureImage(t, savedPart(t, rendered, "word/document.xml"), savedPart(t, rendered, "word/_rels/document.xml.rels"))
+			assert.Equal(t, string(data), savedPart(t, rendered, "word/"+target))
+			target, _, _ = pictureImage(t, savedPart(t, rendered, "word/header1.xml"), savedPart(t, rendered, "word/_rels/header1.xml.rels"))
+			assert.Equal(t, string(data), savedPart(t, rendered, "word/"+target))
+		}
+	})
+
+	t.Run("Should report errors like RenderWithOptions", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Hello {{.Name}} {{.Missing}}")
+		compiled, err := doc.CompileWithOptions(docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = compiled.Execute(&buf, map[string]any{"Name": "Ada"})
+		var te *docxtpl.TemplateError
+		require.ErrorAs(t, err, &te)
+		require.Len(t, te.Unresolved, 1)
+		assert.Equal(t, "{{.Missing}}", te.Unresolved[0].Placeholder)
+		assert.Zero(t, buf.Len())
+
+		// Syntax errors are reported when compiling
+		doc = docxtpl.New()
+		doc.AddParagraph("Hello {{if .Name}}")
+		_, err = doc.Compile()
+		require.ErrorAs(t, err, &te)
+		assert.Equal(t, docxtpl.ErrCodeSyntaxError, te.Code)
+	})
+}
@@ -0,0 +1,47 @@
+package docxtpl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderStructTags(t *testing.T) {
+	type Customer struct {
+		Name     string    `json:"customer_name"`
+		Internal string    `docx:"-"`
+		Since    time.Time `docx:"since"`
+	}
+
+	t.Run("Should key fields by docx tags", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{.Name}} since {{.since.Format "2006"}}`)
+
+		err := doc.Render(Customer{Name: "Acme", Since: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme since 2019", doc.GetText())
+	})
+
+	t.Run("Should fall back to json tags when enabled", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.UseJSONTags(true)
+		doc.AddParagraph("{{.customer_name}}")
+
+		err := doc.Render(Customer{Name: "Acme"})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", doc.GetText())
+	})
+
+	t.Run("Should convert and escape structs within map data", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.UseJSONTags(true)
+		doc.AddParagraph(`{{.C.customer_name}} since {{.C.since.Format "2006"}}`)
+
+		err := doc.Render(map[string]any{"C": Customer{Name: "A & <B>", Since: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}})
+		require.NoError(t, err)
+		assert.Equal(t, "A & <B> since 2019", doc.GetText())
+	})
+}
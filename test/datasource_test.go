@@ -0,0 +1,233 @@
+package docxtpl_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ordersWorkbook returns an Excel workbook whose sheet holds the rows, with
+// text in inline strings and dates as serial numbers formatted as dates,
+// counted from 1904 when date1904 is set
+func ordersWorkbook(t *testing.T, date1904 bool, rows ...[]string) []byte {
+	t.Helper()
+
+	var properties string
+	if date1904 {
+		properties = `<workbookPr date1904="1"/>`
+	}
+
+	var sheet strings.Builder
+	for _, row := range rows {
+		sheet.WriteString("<row>")
+		for _, cell := range row {
+			switch {
+			case strings.HasPrefix(cell, "date:"):
+				sheet.WriteString(`<c s="1"><v>` + strings.TrimPrefix(cell, "date:") + `</v></c>`)
+			case strings.HasPrefix(cell, "n:"):
+				sheet.WriteString(`<c><v>` + strings.TrimPrefix(cell, "n:") + `</v></c>`)
+			default:
+				sheet.WriteString(`<c t="inlineStr"><is><t>` + cell + `</t></is></c>`)
+			}
+		}
+		sheet.WriteString("</row>")
+	}
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			properties + `<sheets><sheet name="Orders" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><cellXfs><xf numFmtId="0"/><xf numFmtId="14"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			sheet.String() + `</sheetData></worksheet>`,
+	}
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestRecordSources(t *testing.T) {
+	march5 := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Should read CSV with renamed and typed columns", func(t *testing.T) {
+		// Excel starts CSV files with a byte order mark
+		data := "\ufeffCustomer name,Total,Due,Paid\nAda,1.5,2024-03-05,yes\nGrace,12,,no\n"
+		source := docxtpl.CSVSource(strings.NewReader(data), docxtpl.SourceOptions{
+			Columns: map[string]string{"Customer name": "Name"},
+			Types:   map[string]docxtpl.FieldType{"Total": docxtpl.FieldNumber, "Due": docxtpl.FieldDate, "Paid": docxtpl.FieldBool},
+		})
+		records, err := source.ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"Name": "Ada", "Total": 1.5, "Due": march5, "Paid": true},
+			{"Name": "Grace", "Total": int64(12), "Due": nil, "Paid": false},
+		}, records)
+	})
+
+	t.Run("Should report values that don't convert", func(t *testing.T) {
+		source := docxtpl.CSVSource(strings.NewReader("Total\n12\nlots\n"), docxtpl.SourceOptions{
+			Types: map[string]docxtpl.FieldType{"Total": docxtpl.FieldNumber},
+		})
+		records, err := source.ReadAll()
+		assert.Len(t, records, 1)
+		assert.EqualError(t, err, `row 2: field Total: "lots" is not a number`)
+	})
+
+	t.Run("Should group consecutive rows into line items", func(t *testing.T) {
+		data := "Invoice;Customer;Item;Price\n1;Ada;Pen;2.5\n1;Ada;Ink;4\n2;Grace;;\n3;Alan;Pad;3\n"
+		source := docxtpl.CSVSource(strings.NewReader(data), docxtpl.SourceOptions{
+			Comma:   ';',
+			Columns: map[string]string{"Item": "Items.Name", "Price": "Items.Price"},
+			Types:   map[string]docxtpl.FieldType{"Items.Price": docxtpl.FieldNumber},
+			GroupBy: []string{"Invoice"},
+			Lists:   []string{"Items"},
+		})
+		records, err := source.ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"Invoice": "1", "Customer": "Ada", "Items": []map[string]any{{"Name": "Pen", "Price": 2.5}, {"Name": "Ink", "Price": int64(4)}}},
+			{"Invoice": "2", "Customer": "Grace", "Items": []map[string]any{}},
+			{"Invoice": "3", "Customer": "Alan", "Items": []map[string]any{{"Name": "Pad", "Price": int64(3)}}},
+		}, records)
+	})
+
+	t.Run("Should keep nested fields that aren't lists from the first row", func(t *testing.T) {
+		data := "Invoice,Customer,City,Item\n1,Ada,London,Pen\n1,Ada,Paris,Ink\n"
+		source := docxtpl.CSVSource(strings.NewReader(data), docxtpl.SourceOptions{
+			Columns: map[string]string{"Customer": "Customer.Name", "City": "Customer.City", "Item": "Items.Name"},
+			GroupBy: []string{"Invoice"},
+			Lists:   []string{"Items"},
+		})
+		records, err := source.ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{
+			"Invoice":  "1",
+			"Customer": map[string]any{"Name": "Ada", "City": "London"},
+			"Items":    []map[string]any{{"Name": "Pen"}, {"Name": "Ink"}},
+		}}, records)
+	})
+
+	t.Run("Should read JSON Lines", func(t *testing.T) {
+		data := `{"name": "Ada", "total": 12, "rate": 0.5, "due": "2024-03-05", "tags": [1, 2]}` + "\n\n" + `{"name": "Grace"}` + "\n"
+		source := docxtpl.JSONLSource(strings.NewReader(data), docxtpl.SourceOptions{
+			Columns: map[string]string{"name": "Name"},
+			Types:   map[string]docxtpl.FieldType{"due": docxtpl.FieldDate},
+		})
+		var records []any
+		for record := range source.Records() {
+			records = append(records, record)
+		}
+		require.NoError(t, source.Err())
+		assert.Equal(t, []any{
+			map[string]any{"Name": "Ada", "total": int64(12), "rate": 0.5, "due": march5, "tags": []any{int64(1), int64(2)}},
+			map[string]any{"Name": "Grace"},
+		}, records)
+
+		source = docxtpl.JSONLSource(strings.NewReader("{\"name\": \"Ada\"}\nnot json\n"), docxtpl.SourceOptions{})
+		_, err := source.ReadAll()
+		assert.ErrorContains(t, err, "line 2:")
+	})
+
+	t.Run("Should read an Excel sheet", func(t *testing.T) {
+		workbook := ordersWorkbook(t, false,
+			[]string{"Name", "Due", "Total", "Paid"},
+			[]string{"Ada", "date:45356", "n:12.5", "TRUE"},
+			[]string{},
+			[]string{"Grace", "n:45356", "n:7", "no"},
+		)
+		source, err := docxtpl.XLSXSource(bytes.NewReader(workbook), int64(len(workbook)), "", docxtpl.SourceOptions{
+			Types: map[string]docxtpl.FieldType{"Due": docxtpl.FieldDate, "Paid": docxtpl.FieldBool},
+		})
+		require.NoError(t, err)
+		records, err := source.ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"Name": "Ada", "Due": march5, "Total": 12.5, "Paid": true},
+			{"Name": "Grace", "Due": march5, "Total": int64(7), "Paid": false},
+		}, records)
+
+		_, err = docxtpl.XLSXSource(bytes.NewReader(workbook), int64(len(workbook)), "Missing", docxtpl.SourceOptions{})
+		assert.ErrorContains(t, err, `no sheet "Missing"`)
+	})
+
+	t.Run("Should convert serial dates of workbooks counting from 1904", func(t *testing.T) {
+		workbook := ordersWorkbook(t, true,
+			[]string{"Name", "Due", "Sent"},
+			[]string{"Ada", "date:43894", "n:43894"},
+		)
+		source, err := docxtpl.XLSXSource(bytes.NewReader(workbook), int64(len(workbook)), "", docxtpl.SourceOptions{
+			Types: map[string]docxtpl.FieldType{"Sent": docxtpl.FieldDate},
+		})
+		require.NoError(t, err)
+		records, err := source.ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"Name": "Ada", "Due": march5, "Sent": march5}}, records)
+	})
+
+	t.Run("Should mail merge the records of a source", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`Dear {{.Name}}: {{range .Items}}{{.Name}} {{end}}`)
+		source := docxtpl.CSVSource(strings.NewReader("Name,Item\nAda,Pen\nAda,Ink\nGrace,Pad\n"), docxtpl.SourceOptions{
+			Columns: map[string]string{"Item": "Items.Name"},
+			GroupBy: []string{"Name"},
+			Lists:   []string{"Items"},
+		})
+
+		sink := newMemorySink()
+		require.NoError(t, doc.MailMergeStream(context.Background(), source.Records(), sink.open, docxtpl.MailMergeOptions{}))
+		require.NoError(t, source.Err())
+		require.Len(t, sink.docs, 2)
+		assert.Contains(t, zippedPart(t, sink.docs[0].Bytes(), "word/document.xml"), "Dear Ada: Pen Ink ")
+		assert.Contains(t, zippedPart(t, sink.docs[1].Bytes(), "word/document.xml"), "Dear Grace: Pad ")
+	})
+}
+
+func TestMailMergeToFilesPattern(t *testing.T) {
+	doc := docxtpl.New()
+	doc.AddParagraph("Dear {{.Name}},")
+	records := []map[string]any{
+		{"ID": "A/1", "Name": "Ada"},
+		{"ID": "..", "Name": "Grace"},
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, doc.MailMergeToFiles(records, filepath.Join(dir, "{{.ID}}-%d.docx")))
+	for i, name := range []string{"A_1-1.docx", "__-2.docx"} {
+		written, err := docxtpl.ParseFromFilename(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Contains(t, written.GetText(), fmt.Sprintf("Dear %s,", records[i]["Name"]))
+	}
+
+	assert.ErrorContains(t, doc.MailMergeToFiles(records, filepath.Join(dir, "{{.ID")), "invalid file name pattern")
+	err := doc.MailMergeToFiles(records, filepath.Join(dir, "{{.Missing}}.docx"))
+	assert.ErrorContains(t, err, "failed to name file")
+	err = doc.MailMergeToFiles(records, filepath.Join(dir, "{{if .Name}}letter{{end}}.docx"))
+	assert.ErrorContains(t, err, "records 1 and 2 are both named")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// %d within placeholders is left to them
+	require.NoError(t, doc.MailMergeToFiles(records, filepath.Join(dir, `{{printf "%03d" (len .Name)}}-%d.docx`)))
+	for _, name := range []string{"003-1.docx", "005-2.docx"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+}
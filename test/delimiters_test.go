@@ -0,0 +1,99 @@
+package docxtpl_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelimiters(t *testing.T) {
+	t.Run("Should render tags with custom delimiters and keep literal braces", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.SetDelimiters("[[", "]]")
+		greeting := doc.AddParagraph("Dear [")
+		greeting.AddText("[.Na").Bold()
+		greeting.AddText("me]]!")
+		doc.AddParagraph("Use {{.Name}} in Go templates and {x} in others")
+		doc.AddParagraph("[[range .Items]]")
+		doc.AddParagraph("- [[.]]")
+		doc.AddParagraph("[[end]]")
+
+		require.NoError(t, doc.Render(map[string]any{"Name": "Tom", "Items": []string{"a", "b"}}))
+		assert.Equal(t, "Dear Tom!\nUse {{.Name}} in Go templates and {x} in others\n- a\n- b", doc.GetText())
+	})
+
+	t.Run("Should render escaped delimiters", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.SetDelimiters("<<", ">>")
+		doc.AddParagraph(`<<if gt .Total 5>>Total: <<printf "%d" .Total>><<end>>`)
+
+		require.NoError(t, doc.Render(map[string]any{"Total": 7}))
+		assert.Equal(t, "Total: 7", doc.GetText())
+	})
+
+	t.Run("Should not treat data values as tags", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.SetDelimiters("[[", "]]")
+		doc.AddParagraph("[[.Code]]")
+
+		require.NoError(t, doc.Render(map[string]any{"Code": "{{.X}} [[.Y]]"}))
+		assert.Equal(t, "{{.X}} [[.Y]]", doc.GetText())
+	})
+
+	t.Run("Should report placeholders with the custom delimiters", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.SetDelimiters("[[", "]]")
+		doc.AddParagraph("[[.Name]] {{.NotATag}}")
+		doc.AddParagraph("[[.Missing]]")
+
+		placeholders, err := doc.GetPlaceholders()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"[[.Name]]", "[[.Missing]]"}, placeholders)
+		assert.Empty(t, doc.ValidatePlaceholderSyntax())
+		assert.True(t, doc.Validate().Valid)
+
+		errs := doc.ValidateData(map[string]any{"Name": "Tom"})
+		require.Len(t, errs, 1)
+		assert.Equal(t, "[[.Missing]]", errs[0].Placeholder)
+
+		err = doc.RenderWithOptions(map[string]any{"Name": "Tom"}, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		require.Len(t, te.Unresolved, 1)
+		assert.Equal(t, "[[.Missing]]", te.Unresolved[0].Placeholder)
+
+		require.NoError(t, doc.RenderWithOptions(map[string]any{"Name": "Tom"}, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyKeep}))
+		assert.Equal(t, "Tom {{.NotATag}}\n[[.Missing]]", doc.GetText())
+	})
+
+	t.Run("Should report syntax errors with the custom delimiters", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.SetDelimiters("[[", "]]")
+		doc.AddParagraph("[[.Name")
+
+		errs := doc.ValidatePlaceholderSyntax()
+		require.NotEmpty(t, errs)
+		assert.Contains(t, errs[0].Message, "1 [[ vs 0 ]]")
+		assert.False(t, doc.Validate().Valid)
+	})
+
+	t.Run("Should replace pictures whose alt text uses the custom delimiters", func(t *testing.T) {
+		logo, err := os.ReadFile("testdata/templates/test_image.png")
+		require.NoError(t, err)
+		img, err := docxtpl.CreateInlineImageFromBytes(logo, ".png")
+		require.NoError(t, err)
+
+		doc := pictureTemplate(t, "[[.Logo]]", `<w:r><w:t>[[.Name]]</w:t></w:r>`)
+		doc.SetDelimiters("[[", "]]")
+		require.NoError(t, doc.Render(map[string]any{"Logo": img, "Name": "Acme"}))
+
+		assert.Equal(t, "Acme", doc.GetText())
+		header := savedPart(t, doc, "word/header1.xml")
+		target, _, _ := pictureImage(t, header, savedPart(t, doc, "word/_rels/header1.xml.rels"))
+		assert.Equal(t, string(logo), savedPart(t, doc, "word/"+target))
+		assert.NotContains(t, header, "[[")
+	})
+}
@@ -158,6 +158,7 @@ func TestConvenienceErrorCreators(t *testing.T) {
 	err = docxtpl.ErrInvalidFunc("myFunc")
 	assert.Equal(docxtpl.ErrCodeInvalidFunc, err.Code)
 	assert.Contains(err.Message, "myFunc")
+	assert.Contains(docxtpl.ErrInvalidFunc("formatDate").Suggestions[0], "docxtpl.DocxFunctions()")
 
 	// ErrImageLoad
 	err = docxtpl.ErrImageLoad("/path/to/image.png", errors.New("not found"))
@@ -2,9 +2,11 @@ package docxtpl_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/abdokhaire/go-docxgen"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegisterFunctions(t *testing.T) {
@@ -51,3 +53,25 @@ func TestRegisterFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestDocxFunctions(t *testing.T) {
+	doc := docxtpl.New()
+	doc.RegisterFuncMap(docxtpl.DocxFunctions())
+	doc.AddParagraph(`{{upper .Name}}{{nbsp}}{{checkbox .Paid}} {{formatCurrency "$" .Total}} on {{formatDate "2 Jan 2006" .Date}}`)
+	doc.AddParagraph(`{{join "\n" .Lines}}{{pageBreak}}{{breaks .Notes}}`)
+	doc.AddParagraph(`{{default "n/a" .Phone}}`)
+
+	require.NoError(t, doc.Render(map[string]any{
+		"Name":  "Müller & Söhne",
+		"Paid":  true,
+		"Total": 1234.5,
+		"Date":  time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+		"Lines": []string{"1 Main St", "Springfield"},
+		"Notes": "a\tb",
+	}))
+
+	assert.Equal(t, "MÜLLER & SÖHNE ☒ $1,234.50 on 5 Mar 2024\n1 Main St\nSpringfield\na\tb\nn/a", doc.GetText())
+	xml := savedPart(t, doc, "word/document.xml")
+	assert.Contains(t, xml, `<w:br w:type="page"></w:br>`)
+	assert.Contains(t, xml, `<w:tab></w:tab>`)
+}
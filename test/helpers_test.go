@@ -0,0 +1,34 @@
+package docxtpl_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/require"
+)
+
+// savedPart returns the content of a part of the saved document
+func savedPart(t *testing.T, doc *docxtpl.DocxTmpl, name string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.Save(&buf))
+	return zippedPart(t, buf.Bytes(), name)
+}
+
+// zippedPart returns the content of a part of a saved document
+func zippedPart(t *testing.T, document []byte, name string) string {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(document), int64(len(document)))
+	require.NoError(t, err)
+	file, err := reader.Open(name)
+	require.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	return string(content)
+}
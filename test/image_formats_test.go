@@ -0,0 +1,156 @@
+package docxtpl_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"regexp"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// testImage returns a 216 by 72 pixel image, 3 by 1 inches at 72 DPI
+func testImage() image.Image {
+	img := image.NewPaletted(image.Rect(0, 0, 216, 72), color.Palette{color.White, color.Black})
+	for x := 0; x < 216; x += 2 {
+		img.SetColorIndex(x, x%72, 1)
+	}
+	return img
+}
+
+// renderedImage renders the image into a new document and returns the
+// document's XML and the saved data of the image it shows
+func renderedImage(t *testing.T, img *docxtpl.InlineImage) (doc *docxtpl.DocxTmpl, xml string, target string) {
+	t.Helper()
+
+	doc = docxtpl.New()
+	doc.AddParagraph("{{.Image}}")
+	require.NoError(t, doc.Render(map[string]any{"Image": img}))
+
+	xml = savedPart(t, doc, "word/document.xml")
+	target, cx, cy := pictureImage(t, xml, savedPart(t, doc, "word/_rels/document.xml.rels"))
+	assert.Equal(t, []string{"2743200", "914400"}, []string{cx, cy}, "size")
+	return doc, xml, target
+}
+
+func TestImageFormats(t *testing.T) {
+	encode := func(t *testing.T, encode func(*bytes.Buffer, image.Image) error) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, encode(&buf, testImage()))
+		return buf.Bytes()
+	}
+
+	t.Run("Should add GIF, BMP and TIFF images with their content types", func(t *testing.T) {
+		for _, format := range []struct {
+			ext, contentType string
+			data             []byte
+		}{
+			{".gif", "image/gif", encode(t, func(buf *bytes.Buffer, img image.Image) error { return gif.Encode(buf, img, nil) })},
+			{".bmp", "image/bmp", encode(t, func(buf *bytes.Buffer, img image.Image) error { return bmp.Encode(buf, img) })},
+			{".tiff", "image/tiff", encode(t, func(buf *bytes.Buffer, img image.Image) error { return tiff.Encode(buf, img, nil) })},
+			{".tif", "image/tiff", encode(t, func(buf *bytes.Buffer, img image.Image) error {
+				return tiff.Encode(buf, img, &tiff.Options{Compression: tiff.Deflate})
+			})},
+		} {
+			img, err := docxtpl.CreateInlineImageFromBytes(format.data, format.ext)
+			require.NoError(t, err, format.ext)
+
+			doc, _, target := renderedImage(t, img)
+			assert.Equal(t, string(format.data), savedPart(t, doc, "word/"+target), format.ext)
+
+			extension := regexp.MustCompile(`\.(\w+)$`).FindStringSubmatch(target)[1]
+			assert.Contains(t, savedPart(t, doc, "[Content_Types].xml"),
+				`<Default Extension="`+extension+`" ContentType="`+format.contentType+`"`, format.ext)
+
+			require.NoError(t, img.Resize(108, 36), format.ext)
+			_, _, err = image.Decode(bytes.NewReader(encodedImage(t, img)))
+			assert.NoError(t, err, format.ext)
+		}
+	})
+
+	t.Run("Should convert WebP images to PNG", func(t *testing.T) {
+		// A 1 by 1 pixel lossless WebP image
+		data, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+		require.NoError(t, err)
+
+		img, err := docxtpl.CreateInlineImageFromBytes(data, ".webp")
+		require.NoError(t, err)
+		assert.Equal(t, ".png", img.Ext)
+
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Image}}")
+		require.NoError(t, doc.Render(map[string]any{"Image": img}))
+
+		target, _, _ := pictureImage(t, savedPart(t, doc, "word/document.xml"), savedPart(t, doc, "word/_rels/document.xml.rels"))
+		converted, err := png.Decode(bytes.NewReader([]byte(savedPart(t, doc, "word/"+target))))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 1, 1), converted.Bounds())
+	})
+
+	t.Run("Should add SVG images with a PNG fallback", func(t *testing.T) {
+		svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="3in" height="1in" viewBox="0 0 30 10">` +
+			`<rect x="0" y="0" width="30" height="10" fill="#ff0000"/></svg>`)
+
+		img, err := docxtpl.CreateInlineImageFromBytes(svg, ".svg")
+		require.NoError(t, err)
+		assert.Error(t, img.Resize(10, 10))
+
+		doc, xml, target := renderedImage(t, img.AltText("Logo"))
+		fallback, err := png.Decode(bytes.NewReader([]byte(savedPart(t, doc, "word/"+target))))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 288, 96), fallback.Bounds())
+		r, g, b, _ := fallback.At(144, 48).RGBA()
+		assert.Equal(t, []uint32{0xffff, 0, 0}, []uint32{r, g, b})
+
+		svgBlip := regexp.MustCompile(`<a:ext uri="\{96DAC541-7B7A-43D3-8B79-37D633B846F1\}"><asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="(\w+)"`).FindStringSubmatch(xml)
+		require.NotNil(t, svgBlip)
+		rels := savedPart(t, doc, "word/_rels/document.xml.rels")
+		svgTarget := regexp.MustCompile(`Id="` + svgBlip[1] + `"[^>]*Target="([^"]+)"`).FindStringSubmatch(rels)
+		require.NotNil(t, svgTarget)
+		assert.Equal(t, string(svg), savedPart(t, doc, "word/"+svgTarget[1]))
+
+		contentTypes := savedPart(t, doc, "[Content_Types].xml")
+		assert.Contains(t, contentTypes, `<Default Extension="svg" ContentType="image/svg+xml"`)
+		assert.Contains(t, contentTypes, `<Default Extension="png" ContentType="image/png"`)
+	})
+
+	t.Run("Should replace template pictures with SVG images", func(t *testing.T) {
+		svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100"><circle cx="50" cy="50" r="40"/></svg>`)
+		img, err := docxtpl.CreateInlineImageFromBytes(svg, ".svg")
+		require.NoError(t, err)
+
+		doc := pictureTemplate(t, "Header logo", pictureXml(false, "Picture 1", "{{.Logo}}", "rId91"))
+		require.NoError(t, doc.Render(map[string]any{"Logo": img}))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		rels := savedPart(t, doc, "word/_rels/document.xml.rels")
+		target, _, _ := pictureImage(t, xml, rels)
+		assert.Regexp(t, `\.png$`, target)
+		assert.Contains(t, xml, "asvg:svgBlip")
+	})
+
+	t.Run("Should reject data that isn't in an image format", func(t *testing.T) {
+		_, err := docxtpl.CreateInlineImageFromBytes([]byte("plain text, not an image"), ".svg")
+		assert.Error(t, err)
+	})
+}
+
+// encodedImage returns the data of the image as saved in a document
+func encodedImage(t *testing.T, img *docxtpl.InlineImage) []byte {
+	t.Helper()
+
+	doc := docxtpl.New()
+	doc.AddParagraph("{{.Image}}")
+	require.NoError(t, doc.Render(map[string]any{"Image": img}))
+	target, _, _ := pictureImage(t, savedPart(t, doc, "word/document.xml"), savedPart(t, doc, "word/_rels/document.xml.rels"))
+	return []byte(savedPart(t, doc, "word/"+target))
+}
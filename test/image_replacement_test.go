@@ -0,0 +1,192 @@
+package docxtpl_test
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pictureXml returns a run holding a placeholder picture laid out like Word
+// saves it, with a frame of 2000000 by 1000000 EMUs
+func pictureXml(anchor bool, name, descr, rId string) string {
+	graphic := `<wp:docPr id="1" name="` + name + `" descr="` + descr + `"/>` +
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" noChangeAspect="1"/></wp:cNvGraphicFramePr>` +
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">` +
+		`<pic:nvPicPr><pic:cNvPr id="0" name="placeholder.png" descr="` + descr + `"/><pic:cNvPicPr/></pic:nvPicPr>` +
+		`<pic:blipFill><a:blip r:embed="` + rId + `"/><a:srcRect l="10000"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>` +
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="2000000" cy="1000000"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>` +
+		`</pic:pic></a:graphicData></a:graphic>`
+
+	if anchor {
+		return `<w:r><w:drawing><wp:anchor distT="0" distB="0" distL="114300" distR="114300" simplePos="0" relativeHeight="1" behindDoc="0" locked="0" layoutInCell="1" allowOverlap="1">` +
+			`<wp:simplePos x="0" y="0"/><wp:positionH relativeFrom="column"><wp:posOffset>457200</wp:posOffset></wp:positionH>` +
+			`<wp:positionV relativeFrom="paragraph"><wp:posOffset>0</wp:posOffset></wp:positionV>` +
+			`<wp:extent cx="2000000" cy="1000000"/><wp:effectExtent l="0" t="0" r="0" b="0"/><wp:wrapSquare wrapText="bothSides"/>` +
+			graphic + `</wp:anchor></w:drawing></w:r>`
+	}
+	return `<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">` +
+		`<wp:extent cx="2000000" cy="1000000"/><wp:effectExtent l="0" t="0" r="0" b="0"/>` +
+		graphic + `</wp:inline></w:drawing></w:r>`
+}
+
+// pictureTemplate returns a document whose body holds the runs and whose
+// header holds a picture with the alt text headerDescr, all showing the same
+// placeholder image
+func pictureTemplate(t *testing.T, headerDescr string, runs ...string) *docxtpl.DocxTmpl {
+	t.Helper()
+
+	placeholder, err := os.ReadFile("testdata/templates/test_image.jpg")
+	require.NoError(t, err)
+
+	return withParts(t, docxtpl.New(), map[string]func(string) string{
+		"word/document.xml": func(document string) string {
+			start := strings.Index(document, "<w:body>") + len("<w:body>")
+			end := strings.Index(document, "</w:body>")
+			return document[:start] + `<w:p>` + strings.Join(runs, "") + `</w:p>` +
+				`<w:sectPr><w:headerReference w:type="default" r:id="rId90"/></w:sectPr>` + document[end:]
+		},
+		"word/_rels/document.xml.rels": func(rels string) string {
+			return strings.Replace(rels, "</Relationships>",
+				`<Relationship Id="rId90" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>`+
+					`<Relationship Id="rId91" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/placeholder.jpg"/>`+
+					`</Relationships>`, 1)
+		},
+		"word/header1.xml": func(string) string {
+			return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+				`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing">` +
+				`<w:p>` + pictureXml(false, "Header logo", headerDescr, "rId1") + `</w:p></w:hdr>`
+		},
+		"word/_rels/header1.xml.rels": func(string) string {
+			return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+				`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/placeholder.jpg"/>` +
+				`</Relationships>`
+		},
+		"word/media/placeholder.jpg": func(string) string { return string(placeholder) },
+		"[Content_Types].xml": func(types string) string {
+			return strings.Replace(types, "</Types>",
+				`<Default Extension="jpg" ContentType="image/jpeg"/>`+
+					`<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/></Types>`, 1)
+		},
+	})
+}
+
+// pictureImage returns the target and size of the image shown by the first
+// picture in the XML, whose relationships are in rels
+func pictureImage(t *testing.T, xml, rels string) (target string, cx string, cy string) {
+	t.Helper()
+
+	embed := regexp.MustCompile(`r:embed="(\w+)"`).FindStringSubmatch(xml)
+	require.NotNil(t, embed)
+	match := regexp.MustCompile(`Id="` + embed[1] + `"[^>]*Target="([^"]+)"`).FindStringSubmatch(rels)
+	require.NotNil(t, match, "relationship %s", embed[1])
+
+	extent := regexp.MustCompile(`<wp:extent cx="(\d+)" cy="(\d+)"`).FindStringSubmatch(xml)
+	require.NotNil(t, extent)
+	assert.Contains(t, xml, fmt.Sprintf(`<a:ext cx="%s" cy="%s"`, extent[1], extent[2]))
+	return match[1], extent[1], extent[2]
+}
+
+func TestImageReplacement(t *testing.T) {
+	logoData, err := os.ReadFile("testdata/templates/test_image.png")
+	require.NoError(t, err)
+	newLogo := func() *docxtpl.InlineImage {
+		logo, err := docxtpl.CreateInlineImageFromBytes(logoData, ".png")
+		require.NoError(t, err)
+		return logo
+	}
+
+	t.Run("Should replace a picture whose alt text is a placeholder", func(t *testing.T) {
+		doc := pictureTemplate(t, "Header logo", pictureXml(false, "Picture 1", "{{.Logo}}", "rId91"))
+
+		err := doc.Render(map[string]any{"Logo": newLogo().AltText("Company logo")})
+		require.NoError(t, err)
+
+		xml := savedPart(t, doc, "word/document.xml")
+		target, cx, cy := pictureImage(t, xml, savedPart(t, doc, "word/_rels/document.xml.rels"))
+		assert.NotEqual(t, "media/placeholder.jpg", target)
+		assert.Equal(t, string(logoData), savedPart(t, doc, "word/"+target))
+
+		// The square logo fits inside the frame
+		assert.Equal(t, []string{"1000000", "1000000"}, []string{cx, cy})
+		assert.Contains(t, xml, `descr="Company logo"`)
+		assert.NotContains(t, xml, "{{")
+		assert.NotContains(t, xml, "srcRect")
+		assert.Contains(t, savedPart(t, doc, "[Content_Types].xml"), `Extension="png"`)
+	})
+
+	t.Run("Should replace a floating picture by name and keep its layout", func(t *testing.T) {
+		doc := pictureTemplate(t, "Header logo", pictureXml(true, "{{ .Company.Logo }}", "", "rId91"))
+
+		err := doc.Render(struct {
+			Company struct{ Logo *docxtpl.InlineImage }
+		}{
+			Company: struct{ Logo *docxtpl.InlineImage }{Logo: newLogo().KeepFrameSize()},
+		})
+		require.NoError(t, err)
+
+		xml := savedPart(t, doc, "word/document.xml")
+		target, cx, cy := pictureImage(t, xml, savedPart(t, doc, "word/_rels/document.xml.rels"))
+		assert.Equal(t, string(logoData), savedPart(t, doc, "word/"+target))
+		assert.Equal(t, []string{"2000000", "1000000"}, []string{cx, cy})
+		assert.Contains(t, xml, "<wp:anchor")
+		assert.Contains(t, xml, "<wp:wrapSquare")
+		assert.Contains(t, xml, "<wp:posOffset>457200</wp:posOffset>")
+		assert.NotContains(t, xml, "{{")
+	})
+
+	t.Run("Should replace pictures in headers", func(t *testing.T) {
+		doc := pictureTemplate(t, "{{.Logo}}", `<w:r><w:t>{{.Name}}</w:t></w:r>`)
+
+		err := doc.Render(map[string]any{"Logo": newLogo().Width(docxtpl.Cm(1)), "Name": "Acme"})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", doc.GetText())
+
+		header := savedPart(t, doc, "word/header1.xml")
+		rels := savedPart(t, doc, "word/_rels/header1.xml.rels")
+		target, cx, cy := pictureImage(t, header, rels)
+		assert.Equal(t, string(logoData), savedPart(t, doc, "word/"+target))
+		assert.Contains(t, rels, `Target="media/placeholder.jpg"`)
+
+		// An image with its own size keeps it
+		assert.Equal(t, []string{"360000", "360000"}, []string{cx, cy})
+		assert.NotContains(t, header, "{{")
+		assert.NotContains(t, header, "descr=")
+	})
+
+	t.Run("Should leave pictures without an image value to the template", func(t *testing.T) {
+		doc := pictureTemplate(t, "Header logo", pictureXml(false, "Picture 1", "{{.Logo}}", "rId91"))
+
+		err := doc.RenderWithOptions(map[string]any{}, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		require.NotEmpty(t, te.Unresolved)
+		assert.Equal(t, "{{.Logo}}", te.Unresolved[0].Placeholder)
+	})
+
+	t.Run("Should replace pictures by alt text or name before rendering", func(t *testing.T) {
+		doc := pictureTemplate(t, "Company logo",
+			pictureXml(false, "Company logo", "", "rId91"),
+			pictureXml(true, "Picture 2", "Team photo", "rId91"))
+
+		require.NoError(t, doc.ReplaceImage("Company logo", newLogo()))
+		assert.Error(t, doc.ReplaceImage("Missing", newLogo()))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		rels := savedPart(t, doc, "word/_rels/document.xml.rels")
+		target, _, _ := pictureImage(t, xml, rels)
+		assert.NotEqual(t, "media/placeholder.jpg", target)
+		assert.Contains(t, xml, `r:embed="rId91"`)
+		assert.Contains(t, xml, `name="Company logo"`)
+
+		headerTarget, _, _ := pictureImage(t, savedPart(t, doc, "word/header1.xml"), savedPart(t, doc, "word/_rels/header1.xml.rels"))
+		assert.Equal(t, string(logoData), savedPart(t, doc, "word/"+headerTarget))
+	})
+}
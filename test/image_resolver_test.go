@@ -0,0 +1,101 @@
+package docxtpl_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageResolver(t *testing.T) {
+	render := func(t *testing.T, resolver docxtpl.ImageResolver, value string) (*docxtpl.DocxTmpl, error) {
+		t.Helper()
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Logo}}")
+		if resolver != nil {
+			doc.SetImageResolver(resolver)
+		}
+		return doc, doc.Render(map[string]any{"Logo": value})
+	}
+
+	t.Run("Should render image paths as text without a resolver", func(t *testing.T) {
+		doc, err := render(t, nil, "testdata/templates/test_image.png")
+		require.NoError(t, err)
+		assert.Equal(t, "testdata/templates/test_image.png", doc.GetText())
+		assert.NotContains(t, savedPart(t, doc, "word/document.xml"), "<w:drawing>")
+	})
+
+	t.Run("Should resolve file names within the file system", func(t *testing.T) {
+		resolver := docxtpl.FSImageResolver(os.DirFS("testdata/templates"))
+
+		doc, err := render(t, resolver, "test_image.png")
+		require.NoError(t, err)
+		assert.Contains(t, savedPart(t, doc, "word/document.xml"), "<w:drawing>")
+
+		for _, value := range []string{"../templates/test_image.png", "/etc/logo.png", "missing.png", "test_basic.docx", "Just text"} {
+			doc, err := render(t, resolver, value)
+			require.NoError(t, err, value)
+			assert.Equal(t, value, doc.GetText())
+		}
+	})
+
+	t.Run("Should report files that aren't images", func(t *testing.T) {
+		resolver := docxtpl.FSImageResolver(fstest.MapFS{"logo.png": {Data: []byte("not an image")}})
+
+		_, err := render(t, resolver, "logo.png")
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		assert.Equal(t, docxtpl.ErrCodeImageError, te.Code)
+	})
+
+	t.Run("Should use a callback", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/templates/test_image.jpg")
+		require.NoError(t, err)
+		resolver := docxtpl.ImageResolverFunc(func(value string) (*docxtpl.InlineImage, error) {
+			switch {
+			case value == "asset:logo":
+				return docxtpl.CreateInlineImageFromBytes(data, ".jpg")
+			case strings.HasPrefix(value, "asset:"):
+				return nil, errors.New("unknown asset")
+			}
+			return nil, nil
+		})
+
+		doc, err := render(t, resolver, "asset:logo")
+		require.NoError(t, err)
+		assert.Contains(t, savedPart(t, doc, "word/document.xml"), "<w:drawing>")
+
+		doc, err = render(t, resolver, "plain")
+		require.NoError(t, err)
+		assert.Equal(t, "plain", doc.GetText())
+
+		_, err = render(t, resolver, "asset:missing")
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		assert.Equal(t, docxtpl.ErrCodeImageError, te.Code)
+	})
+
+	t.Run("Should resolve paths on disk when enabled", func(t *testing.T) {
+		doc, err := render(t, docxtpl.FilePathImageResolver(), "testdata/templates/test_image.jpg")
+		require.NoError(t, err)
+		assert.Contains(t, savedPart(t, doc, "word/document.xml"), "<w:drawing>")
+	})
+
+	t.Run("Should resolve template pictures with string values", func(t *testing.T) {
+		doc := pictureTemplate(t, "Header logo", pictureXml(false, "Picture 1", "{{.Logo}}", "rId91"))
+		doc.SetImageResolver(docxtpl.FSImageResolver(os.DirFS("testdata/templates")))
+
+		require.NoError(t, doc.Render(map[string]any{"Logo": "test_image.png"}))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		target, _, _ := pictureImage(t, xml, savedPart(t, doc, "word/_rels/document.xml.rels"))
+		logo, err := os.ReadFile("testdata/templates/test_image.png")
+		require.NoError(t, err)
+		assert.Equal(t, string(logo), savedPart(t, doc, "word/"+target))
+	})
+}
@@ -65,7 +65,7 @@ func TestCreateInlineImageFromBytes_InvalidExtension(t *testing.T) {
 	// Valid PNG data but wrong extension
 	pngData := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0x00, 0x00, 0x00, 0x00}
 
-	_, err := docxtpl.CreateInlineImageFromBytes(pngData, ".gif")
+	_, err := docxtpl.CreateInlineImageFromBytes(pngData, ".ico")
 	assert.NotNil(err)
 	assert.Contains(err.Error(), "unsupported image format")
 }
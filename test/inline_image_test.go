@@ -1,6 +1,11 @@
 package docxtpl_test
 
 import (
+	"image"
+	_ "image/jpeg"
+	"os"
+	"regexp"
+	"strconv"
 	"testing"
 
 	"github.com/abdokhaire/go-docxgen"
@@ -94,3 +99,83 @@ func TestGetResolution(t *testing.T) {
 	assert.Greater(wDpi, 0)
 	assert.Greater(hDpi, 0)
 }
+
+// renderedImageExtent renders the image alone in a new document and returns its displayed size in EMUs
+func renderedImageExtent(t *testing.T, img *docxtpl.InlineImage) (int64, int64, string) {
+	t.Helper()
+
+	doc := docxtpl.New()
+	doc.AddParagraph("{{.Photo}}")
+	require.NoError(t, doc.Render(map[string]any{"Photo": img}))
+
+	xml := savedPart(t, doc, "word/document.xml")
+	match := regexp.MustCompile(`<wp:extent cx="(\d+)" cy="(\d+)"`).FindStringSubmatch(xml)
+	require.NotNil(t, match)
+	cx, _ := strconv.ParseInt(match[1], 10, 64)
+	cy, _ := strconv.ParseInt(match[2], 10, 64)
+	return cx, cy, xml
+}
+
+func TestInlineImageSizing(t *testing.T) {
+	const path = "testdata/templates/test_image.jpg"
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	config, _, err := image.DecodeConfig(file)
+	file.Close()
+	require.NoError(t, err)
+	ratio := float64(config.Height) / float64(config.Width)
+
+	newImage := func() *docxtpl.InlineImage {
+		img, err := docxtpl.CreateInlineImage(path)
+		require.NoError(t, err)
+		return img
+	}
+
+	t.Run("Should convert units to EMUs", func(t *testing.T) {
+		assert.Equal(t, docxtpl.Length(360000), docxtpl.Cm(1))
+		assert.Equal(t, docxtpl.Length(914400), docxtpl.Inches(1))
+		assert.Equal(t, docxtpl.Length(12700), docxtpl.Points(1))
+	})
+
+	t.Run("Should keep the aspect ratio with only a width", func(t *testing.T) {
+		cx, cy, xml := renderedImageExtent(t, newImage().Width(docxtpl.Cm(5)))
+		assert.Equal(t, int64(1800000), cx)
+		assert.InDelta(t, 1800000*ratio, float64(cy), 1)
+		assert.Contains(t, xml, `<a:ext cx="1800000" cy="`+strconv.FormatInt(cy, 10)+`">`)
+	})
+
+	t.Run("Should keep the aspect ratio with only a height", func(t *testing.T) {
+		cx, cy, _ := renderedImageExtent(t, newImage().Height(docxtpl.Inches(2)))
+		assert.Equal(t, int64(1828800), cy)
+		assert.InDelta(t, 1828800/ratio, float64(cx), 1)
+	})
+
+	t.Run("Should use an explicit size", func(t *testing.T) {
+		cx, cy, _ := renderedImageExtent(t, newImage().Size(docxtpl.Points(144), docxtpl.Points(72)))
+		assert.Equal(t, int64(1828800), cx)
+		assert.Equal(t, int64(914400), cy)
+	})
+
+	t.Run("Should scale down to fit the bounding box", func(t *testing.T) {
+		cx, cy, _ := renderedImageExtent(t, newImage().Width(docxtpl.Cm(40)).MaxSize(docxtpl.Cm(10), docxtpl.Cm(100)))
+		assert.Equal(t, int64(3600000), cx)
+		assert.InDelta(t, 3600000*ratio, float64(cy), 1)
+
+		cx, cy, _ = renderedImageExtent(t, newImage().Height(docxtpl.Cm(40)).MaxSize(0, docxtpl.Cm(4)))
+		assert.Equal(t, int64(1440000), cy)
+		assert.InDelta(t, 1440000/ratio, float64(cx), 1)
+	})
+
+	t.Run("Should fit the page content width", func(t *testing.T) {
+		cx, _, _ := renderedImageExtent(t, newImage().Width(docxtpl.Inches(20)).FitToPage())
+		assert.Equal(t, int64(docxtpl.Inches(6.5)), cx)
+
+		cx, _, _ = renderedImageExtent(t, newImage().Width(docxtpl.Inches(2)).FitToPage())
+		assert.Equal(t, int64(docxtpl.Inches(2)), cx)
+	})
+
+	t.Run("Should write alt text and title", func(t *testing.T) {
+		_, _, xml := renderedImageExtent(t, newImage().AltText("Site photo & notes").Title("North wall"))
+		assert.Regexp(t, `<wp:docPr id="\d+" name="[^"]*" descr="Site photo &amp; notes" title="North wall">`, xml)
+	})
+}
@@ -0,0 +1,127 @@
+package docxtpl_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderLimits(t *testing.T) {
+	items := make([]string, 50)
+	for i := range items {
+		items[i] = "item"
+	}
+
+	t.Run("Should render within the limits", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{range .Items}}{{upper .}} {{end}}`)
+		require.NoError(t, doc.RegisterFunction("upper", strings.ToUpper))
+		doc.SetLimits(docxtpl.Limits{
+			Timeout:        time.Minute,
+			MaxOutputBytes: 1 << 20,
+			MaxIterations:  50,
+			AllowedFuncs:   []string{"upper"},
+		})
+		require.NoError(t, doc.RenderContext(context.Background(), map[string]any{"Items": items}))
+		assert.Contains(t, doc.GetText(), "ITEM ITEM")
+	})
+
+	t.Run("Should report loops exceeding the limit", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{range .Items}}{{.}}{{end}}`)
+		doc.SetLimits(docxtpl.Limits{MaxIterations: 10})
+		err := doc.Render(map[string]any{"Items": items})
+
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok, "got %v", err)
+		assert.Equal(t, docxtpl.ErrCodeLimitExceeded, te.Code)
+		assert.Equal(t, "template loops exceeded the limit of 10 iterations", te.Message)
+		assert.Equal(t, "{{range .Items}}", te.Placeholder)
+		var limitErr *docxtpl.LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, docxtpl.LimitIterations, limitErr.Kind)
+		assert.Contains(t, doc.GetText(), "{{range .Items}}")
+	})
+
+	t.Run("Should keep the limits on documents rendered from a compiled template", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{.Name}}`)
+		doc.SetLimits(docxtpl.Limits{MaxIterations: 10})
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+		rendered, err := compiled.Render(map[string]any{"Name": "Ada"})
+		require.NoError(t, err)
+
+		rendered.AddParagraph(`{{range .Items}}{{.}}{{end}}`)
+		err = rendered.Render(map[string]any{"Items": items})
+		var limitErr *docxtpl.LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, docxtpl.LimitIterations, limitErr.Kind)
+	})
+
+	t.Run("Should report output exceeding the limit", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{.Text}}`)
+		doc.SetLimits(docxtpl.Limits{MaxOutputBytes: 64 << 10})
+		err := doc.Render(map[string]any{"Text": strings.Repeat("x", 100<<10)})
+		var limitErr *docxtpl.LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, docxtpl.LimitOutput, limitErr.Kind)
+	})
+
+	t.Run("Should refuse functions that aren't allowed", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{upper .Name}} {{title .Name}}`)
+		doc.RegisterFuncMap(map[string]any{"upper": strings.ToUpper, "title": strings.ToTitle})
+		doc.SetLimits(docxtpl.Limits{AllowedFuncs: []string{"upper"}})
+		compiled, err := doc.Compile()
+		assert.Nil(t, compiled)
+
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok, "got %v", err)
+		assert.Equal(t, docxtpl.ErrCodeLimitExceeded, te.Code)
+		assert.Equal(t, "{{title .Name}}", te.Placeholder)
+		var limitErr *docxtpl.LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, &docxtpl.LimitError{Kind: docxtpl.LimitFunc, Func: "title"}, limitErr)
+	})
+
+	t.Run("Should stop after the timeout", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{range .Count}}{{end}}`)
+		doc.SetLimits(docxtpl.Limits{Timeout: 20 * time.Millisecond})
+		err := doc.RenderContext(context.Background(), map[string]any{"Count": 1000000000})
+		var limitErr *docxtpl.LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, docxtpl.LimitTimeout, limitErr.Kind)
+	})
+
+	t.Run("Should render with options and a context", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{.Name}} {{localNumber 1 .Total}}`)
+		err := doc.RenderContextWithOptions(context.Background(), map[string]any{"Total": 2.5},
+			docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok, "got %v", err)
+		require.Len(t, te.Unresolved, 1)
+
+		require.NoError(t, doc.RenderContextWithOptions(context.Background(), map[string]any{"Name": "Ada", "Total": 2.5},
+			docxtpl.RenderOptions{Locale: "de-DE"}))
+		assert.Equal(t, "Ada 2,5", doc.GetText())
+	})
+
+	t.Run("Should stop once the context is canceled", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{range .Items}}{{.}}{{end}}`)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := doc.RenderContext(ctx, map[string]any{"Items": items})
+		assert.True(t, errors.Is(err, context.Canceled), "got %v", err)
+	})
+}
@@ -0,0 +1,112 @@
+package docxtpl_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale(t *testing.T) {
+	data := map[string]any{
+		"Total": 1234.56,
+		"Rate":  0.125,
+		"Date":  time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+	}
+	localized := func() *docxtpl.DocxTmpl {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{localNumber 2 .Total}} | {{localPercent 1 .Rate}} | {{localCurrency "EUR" .Total}} | {{localDate "long" .Date}}`)
+		return doc
+	}
+
+	t.Run("Should format with the locale set on the document", func(t *testing.T) {
+		tests := []struct {
+			locale string
+			want   string
+		}{
+			{"en-US", "1,234.56 | 12.5% | €1,234.56 | March 5, 2024"},
+			{"de-DE", "1.234,56 | 12,5\u00a0% | 1.234,56\u00a0€ | 5. März 2024"},
+			{"fr-FR", "1\u00a0234,56 | 12,5\u00a0% | 1\u00a0234,56\u00a0€ | 5 mars 2024"},
+		}
+		for _, tt := range tests {
+			doc := localized()
+			require.NoError(t, doc.SetLocale(tt.locale))
+			assert.Equal(t, tt.locale, doc.Locale())
+			require.NoError(t, doc.Render(data))
+			assert.Equal(t, tt.want, doc.GetText(), tt.locale)
+		}
+	})
+
+	t.Run("Should format in the currency of the locale's region", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{localCurrency "" .Total}}`)
+		require.NoError(t, doc.SetLocale("ar-AE"))
+		require.NoError(t, doc.Render(data))
+		assert.Equal(t, "1,234.56\u00a0د.إ.\u200f", doc.GetText())
+	})
+
+	t.Run("Should default to the language of the document", func(t *testing.T) {
+		assert.Equal(t, "en-US", docxtpl.New().Locale())
+
+		doc := withParts(t, localized(), map[string]func(string) string{
+			"word/styles.xml": func(styles string) string {
+				return strings.Replace(styles, `<w:lang w:val="en-US"`, `<w:lang w:val="de-DE"`, 1)
+			},
+		})
+		assert.Equal(t, "de-DE", doc.Locale())
+		require.NoError(t, doc.Render(data))
+		assert.Equal(t, "1.234,56 | 12,5\u00a0% | 1.234,56\u00a0€ | 5. März 2024", doc.GetText())
+
+		// An empty locale restores the document's language
+		require.NoError(t, doc.SetLocale("fr-FR"))
+		require.NoError(t, doc.SetLocale(""))
+		assert.Equal(t, "de-DE", doc.Locale())
+	})
+
+	t.Run("Should replace the locale for one render", func(t *testing.T) {
+		doc := localized()
+		require.NoError(t, doc.SetLocale("de-DE"))
+		require.NoError(t, doc.RenderWithOptions(data, docxtpl.RenderOptions{Locale: "en-GB"}))
+		assert.Equal(t, "1,234.56 | 12.5% | €1,234.56 | 5 March 2024", doc.GetText())
+		assert.Equal(t, "de-DE", doc.Locale())
+	})
+
+	t.Run("Should format the numbers of the Word function library for the locale", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.RegisterFuncMap(docxtpl.DocxFunctions())
+		doc.AddParagraph(`{{formatNumber 2 .Total}} | {{formatCurrency "€" .Total}}`)
+		require.NoError(t, doc.SetLocale("de-DE"))
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+
+		rendered, err := compiled.Render(data)
+		require.NoError(t, err)
+		assert.Equal(t, "1.234,56 | 1.234,56 €", rendered.GetText())
+
+		require.NoError(t, doc.RenderWithOptions(data, docxtpl.RenderOptions{Locale: "en-US"}))
+		assert.Equal(t, "1,234.56 | €1,234.56", doc.GetText())
+	})
+
+	t.Run("Should keep functions registered over the Word function library", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.RegisterFuncMap(docxtpl.DocxFunctions())
+		require.NoError(t, doc.RegisterFunction("formatNumber", func(int, any) string { return "custom" }))
+		doc.AddParagraph(`{{formatNumber 2 .Total}} | {{formatCurrency "€" .Total}}`)
+		require.NoError(t, doc.SetLocale("de-DE"))
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+
+		rendered, err := compiled.Render(data)
+		require.NoError(t, err)
+		assert.Equal(t, "custom | 1.234,56\u00a0€", rendered.GetText())
+	})
+
+	t.Run("Should reject invalid locales", func(t *testing.T) {
+		doc := localized()
+		assert.ErrorContains(t, doc.SetLocale("not a locale"), `invalid locale "not a locale"`)
+		assert.Error(t, doc.RenderWithOptions(data, docxtpl.RenderOptions{Locale: "??"}))
+	})
+}
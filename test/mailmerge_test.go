@@ -0,0 +1,297 @@
+package docxtpl_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink collects the documents written by a mail merge
+type memorySink struct {
+	mu   sync.Mutex
+	docs map[int]*bytes.Buffer
+}
+
+// buffer is a document being written to the sink
+type buffer struct {
+	bytes.Buffer
+	sink  *memorySink
+	index int
+}
+
+func (b *buffer) Close() error {
+	b.sink.mu.Lock()
+	defer b.sink.mu.Unlock()
+	b.sink.docs[b.index] = &b.Buffer
+	return nil
+}
+
+func newMemorySink() *memorySink {
+	return &memorySink{docs: make(map[int]*bytes.Buffer)}
+}
+
+func (s *memorySink) open(i int) (io.WriteCloser, error) {
+	return &buffer{sink: s, index: i}, nil
+}
+
+func TestMailMergeStream(t *testing.T) {
+	letter := func() *docxtpl.DocxTmpl {
+		doc := docxtpl.New()
+		doc.AddParagraph("Dear {{.Name}},")
+		return doc
+	}
+	customers := func(count int) []map[string]any {
+		records := make([]map[string]any, count)
+		for i := range records {
+			records[i] = map[string]any{"Name": fmt.Sprintf("Customer %d", i)}
+		}
+		return records
+	}
+
+	t.Run("Should write every record in parallel", func(t *testing.T) {
+		sink := newMemorySink()
+		var progress []docxtpl.MailMergeProgress
+		err := letter().MailMergeStream(context.Background(), docxtpl.RecordsOf(customers(25)), sink.open, docxtpl.MailMergeOptions{
+			Parallelism: 4,
+			Progress:    func(p docxtpl.MailMergeProgress) { progress = append(progress, p) },
+		})
+		require.NoError(t, err)
+
+		require.Len(t, sink.docs, 25)
+		for i, output := range sink.docs {
+			assert.Contains(t, zippedPart(t, output.Bytes(), "word/document.xml"), fmt.Sprintf("Dear Customer %d,", i))
+		}
+		require.Len(t, progress, 25)
+		assert.Equal(t, 25, progress[24].Done)
+		assert.Zero(t, progress[24].Failed)
+	})
+
+	t.Run("Should collect the errors of records without stopping", func(t *testing.T) {
+		records := customers(6)
+		delete(records[1], "Name")
+		delete(records[4], "Name")
+
+		sink := newMemorySink()
+		var last docxtpl.MailMergeProgress
+		err := letter().MailMergeStream(context.Background(), docxtpl.RecordsOf(records), sink.open, docxtpl.MailMergeOptions{
+			Render:   docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError},
+			Progress: func(p docxtpl.MailMergeProgress) { last = p },
+		})
+
+		var mergeErr *docxtpl.MailMergeError
+		require.ErrorAs(t, err, &mergeErr)
+		require.Len(t, mergeErr.Records, 2)
+		assert.Equal(t, 1, mergeErr.Records[0].Index)
+		assert.Equal(t, 4, mergeErr.Records[1].Index)
+		var te *docxtpl.TemplateError
+		assert.ErrorAs(t, err, &te)
+
+		assert.Len(t, sink.docs, 4)
+		assert.NotContains(t, sink.docs, 1)
+		assert.Equal(t, 6, last.Done)
+		assert.Equal(t, 2, last.Failed)
+	})
+
+	t.Run("Should stop reading records when canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sink := newMemorySink()
+		err := letter().MailMergeStream(ctx, docxtpl.RecordsOf(customers(100)), sink.open, docxtpl.MailMergeOptions{
+			Parallelism: 1,
+			Progress: func(p docxtpl.MailMergeProgress) {
+				if p.Done == 3 {
+					cancel()
+				}
+			},
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, len(sink.docs), 100)
+		assert.GreaterOrEqual(t, len(sink.docs), 3)
+	})
+
+	t.Run("Should stop the records being rendered when canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The record cancels the merge once it's being rendered
+		doc := docxtpl.New()
+		doc.AddParagraph("{{cancel}}{{range .Count}}{{end}}")
+		require.NoError(t, doc.RegisterFunction("cancel", func() string {
+			cancel()
+			return ""
+		}))
+		sink := newMemorySink()
+		err := doc.MailMergeStream(ctx, docxtpl.RecordsOf([]map[string]any{{"Count": 1000000000}}), sink.open, docxtpl.MailMergeOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+		var mergeErr *docxtpl.MailMergeError
+		require.ErrorAs(t, err, &mergeErr)
+		assert.Len(t, mergeErr.Records, 1)
+		assert.Empty(t, sink.docs)
+	})
+
+	t.Run("Should report sink errors", func(t *testing.T) {
+		failing := func(i int) (io.WriteCloser, error) { return nil, errors.New("disk full") }
+		err := letter().MailMergeStream(context.Background(), docxtpl.RecordsOf(customers(2)), failing, docxtpl.MailMergeOptions{})
+		var mergeErr *docxtpl.MailMergeError
+		require.ErrorAs(t, err, &mergeErr)
+		assert.Len(t, mergeErr.Records, 2)
+		assert.ErrorContains(t, err, "disk full")
+	})
+
+	t.Run("Should write files as records are rendered", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, letter().MailMergeToFiles(customers(3), filepath.Join(dir, "letters", "letter_%d.docx")))
+		for i := range 3 {
+			doc, err := docxtpl.ParseFromFilename(filepath.Join(dir, "letters", fmt.Sprintf("letter_%d.docx", i+1)))
+			require.NoError(t, err)
+			assert.Contains(t, doc.GetText(), fmt.Sprintf("Dear Customer %d,", i))
+		}
+		entries, err := os.ReadDir(filepath.Join(dir, "letters"))
+		require.NoError(t, err)
+		assert.Len(t, entries, 3)
+	})
+}
+
+// letterTemplate returns a document whose body greets the record and whose
+// header and footer show its name and page numbers
+func letterTemplate(t *testing.T) *docxtpl.DocxTmpl {
+	t.Helper()
+
+	namespaces := `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	return withParts(t, docxtpl.New(), map[string]func(string) string{
+		"word/document.xml": func(document string) string {
+			start := strings.Index(document, "<w:body>") + len("<w:body>")
+			end := strings.Index(document, "</w:body>")
+			return document[:start] + `<w:p><w:r><w:t>Dear {{.Name}},</w:t></w:r></w:p>` +
+				`<w:sectPr><w:headerReference w:type="default" r:id="rId90"/><w:footerReference w:type="default" r:id="rId91"/>` +
+				`<w:pgSz w:w="11906" w:h="16838"/></w:sectPr>` + document[end:]
+		},
+		"word/_rels/document.xml.rels": func(rels string) string {
+			return strings.Replace(rels, "</Relationships>",
+				`<Relationship Id="rId90" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>`+
+					`<Relationship Id="rId91" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>`+
+					`</Relationships>`, 1)
+		},
+		"word/header1.xml": func(string) string {
+			return `<w:hdr ` + namespaces + `><w:p><w:r><w:t>Letter to {{.Name}}</w:t></w:r></w:p></w:hdr>`
+		},
+		"word/footer1.xml": func(string) string {
+			return `<w:ftr ` + namespaces + `><w:p><w:r><w:t xml:space="preserve">Page </w:t></w:r>` +
+				`<w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple><w:r><w:t xml:space="preserve"> of </w:t></w:r>` +
+				`<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> NUMPAGES \* MERGEFORMAT </w:instrText></w:r>` +
+				`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>1</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p></w:ftr>`
+		},
+		"[Content_Types].xml": func(types string) string {
+			return strings.Replace(types, "</Types>",
+				`<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>`+
+					`<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/></Types>`, 1)
+		},
+	})
+}
+
+// sectionParts returns the names of the header or footer parts the sections
+// of a saved document refer to, in section order
+func sectionParts(t *testing.T, doc *docxtpl.DocxTmpl, kind string) []string {
+	t.Helper()
+
+	rels := savedPart(t, doc, "word/_rels/document.xml.rels")
+	var parts []string
+	for _, ref := range regexp.MustCompile(`<w:`+kind+`Reference w:type="default" r:id="(\w+)"`).FindAllStringSubmatch(savedPart(t, doc, "word/document.xml"), -1) {
+		target := regexp.MustCompile(`Id="` + ref[1] + `"[^>]*Target="([^"]+)"`).FindStringSubmatch(rels)
+		require.NotNil(t, target, "relationship %s", ref[1])
+		parts = append(parts, "word/"+target[1])
+	}
+	return parts
+}
+
+func TestMailMergeToSingleSections(t *testing.T) {
+	records := []map[string]any{{"Name": "Ada"}, {"Name": "Grace"}, {"Name": "Alan"}}
+
+	t.Run("Should start each record in a section of its own", func(t *testing.T) {
+		merged, err := letterTemplate(t).MailMergeToSingle(records)
+		require.NoError(t, err)
+
+		document := savedPart(t, merged, "word/document.xml")
+		assert.Equal(t, 3, strings.Count(document, "<w:sectPr>"))
+		assert.Equal(t, 3, strings.Count(document, `<w:type w:val="nextPage">`))
+		assert.Equal(t, 3, strings.Count(document, `<w:pgNumType w:start="1">`))
+		assert.Equal(t, 3, strings.Count(document, `<w:pgSz w:w="11906" w:h="16838">`))
+		assert.Regexp(t, `Dear Ada,</w:t></w:r></w:p>.*Dear Grace,</w:t></w:r></w:p>.*Dear Alan,`, document)
+		assert.Regexp(t, `<w:pPr><w:sectPr>.*</w:sectPr></w:pPr><w:r><w:t>Dear Ada,`, document, "sections end with their last paragraph")
+
+		headers := sectionParts(t, merged, "header")
+		require.Len(t, headers, 3)
+		for i, header := range headers {
+			assert.Contains(t, savedPart(t, merged, header), "Letter to "+records[i]["Name"].(string))
+		}
+		footers := sectionParts(t, merged, "footer")
+		require.Len(t, footers, 3)
+		for _, name := range footers {
+			footer := savedPart(t, merged, name)
+			assert.Contains(t, footer, `<w:instrText xml:space="preserve"> SECTIONPAGES \* MERGEFORMAT </w:instrText>`)
+			assert.Contains(t, footer, `w:instr=" PAGE "`)
+		}
+		types := savedPart(t, merged, "[Content_Types].xml")
+		assert.Contains(t, types, `<Override PartName="/word/header3.xml"`)
+		assert.Contains(t, types, `<Override PartName="/word/footer3.xml"`)
+	})
+
+	t.Run("Should start records on odd pages and number pages through", func(t *testing.T) {
+		merged, err := letterTemplate(t).MailMergeToSingleWithOptions(records, docxtpl.SingleMergeOptions{
+			Break:               docxtpl.SectionOddPage,
+			ContinuePageNumbers: true,
+		})
+		require.NoError(t, err)
+
+		document := savedPart(t, merged, "word/document.xml")
+		assert.Equal(t, 3, strings.Count(document, `<w:type w:val="oddPage">`))
+		assert.NotContains(t, document, "pgNumType")
+		for _, footer := range sectionParts(t, merged, "footer") {
+			assert.Contains(t, savedPart(t, merged, footer), " NUMPAGES ")
+		}
+	})
+
+	t.Run("Should keep the images of each record's header", func(t *testing.T) {
+		logos := make([]*docxtpl.InlineImage, 2)
+		for i, name := range []string{"test_image.png", "test_image.jpg"} {
+			data, err := os.ReadFile("testdata/templates/" + name)
+			require.NoError(t, err)
+			logos[i], err = docxtpl.CreateInlineImageFromBytes(data, filepath.Ext(name))
+			require.NoError(t, err)
+		}
+
+		doc := pictureTemplate(t, "{{.Logo}}", `<w:r><w:t>{{.Name}}</w:t></w:r>`)
+		merged, err := doc.MailMergeToSingle([]map[string]any{{"Name": "Ada", "Logo": logos[0]}, {"Name": "Grace", "Logo": logos[1]}})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, merged.Save(&buf))
+		headers := sectionParts(t, merged, "header")
+		require.Len(t, headers, 2)
+		var targets []string
+		for _, header := range headers {
+			rels := savedPart(t, merged, strings.Replace(header, "word/", "word/_rels/", 1)+".rels")
+			target, _, _ := pictureImage(t, savedPart(t, merged, header), rels)
+			targets = append(targets, target)
+		}
+		assert.NotEqual(t, targets[0], targets[1])
+		assert.Equal(t, ".png", filepath.Ext(targets[0]))
+		assert.Equal(t, ".jpg", filepath.Ext(targets[1]))
+		for _, target := range targets {
+			assert.NotEmpty(t, zippedPart(t, buf.Bytes(), "word/"+target))
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package docxtpl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	t.Run("Should render inline Markdown within the paragraph", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Clause: {{markdown .Clause}} (see terms)")
+
+		err := doc.Render(map[string]any{"Clause": "Payment is due **within 30 days** of _invoice_ & `net_total` [online](https://example.com/pay)"})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(doc.GetText(), "Clause: Payment is due within 30 days of invoice & net_total "))
+		assert.True(t, strings.HasSuffix(doc.GetText(), " (see terms)"))
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b></w:rPr><w:t xml:space="preserve">within 30 days</w:t>`)
+		assert.Contains(t, xml, `<w:rPr><w:i></w:i></w:rPr><w:t xml:space="preserve">invoice</w:t>`)
+		assert.Contains(t, xml, `<w:rFonts w:ascii="Courier New" w:eastAsia="Courier New" w:hAnsi="Courier New"></w:rFonts></w:rPr><w:t xml:space="preserve">net_total</w:t>`)
+		assert.Regexp(t, `<w:hyperlink r:id="rIdLink\d+"><w:r><w:rPr>.*?<w:rStyle w:val="Hyperlink"></w:rStyle>.*?</w:rPr><w:t xml:space="preserve">online</w:t></w:r></w:hyperlink>`, xml)
+	})
+
+	t.Run("Should render headings, lists and tables as paragraphs replacing the placeholder paragraph", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Before")
+		doc.AddParagraph("{{markdown .Clause}}")
+		doc.AddParagraph("After")
+
+		clause := "## Terms\n\nThe *buyer* agrees:\n\n- to pay\n- to collect\n  - on time\n\n1. First\n2. Second\n\n| Item | Price |\n|------|------:|\n| Pen  | 1.50  |"
+		err := doc.Render(map[string]any{"Clause": clause})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"Before",
+			"Terms",
+			"The buyer agrees:",
+			"•\tto pay",
+			"•\tto collect",
+			"○\ton time",
+			"1.\tFirst",
+			"2.\tSecond",
+			"|  :----: | :----: |",
+			"| Item | Price |",
+			"| Pen | 1.50 |",
+			"After",
+		}, nonEmptyLines(doc.GetText()))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:pStyle w:val="Heading2"></w:pStyle>`)
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b></w:rPr><w:t xml:space="preserve">Item</w:t>`)
+		assert.NotContains(t, xml, "docxtpl")
+	})
+
+	t.Run("Should split the paragraph around block content", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Notes: {{markdown .Notes}} End.")
+
+		err := doc.Render(map[string]any{"Notes": "* one\n* two"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Notes:", "•\tone", "•\ttwo", "End."}, nonEmptyLines(doc.GetText()))
+	})
+
+	t.Run("Should render nothing for empty values", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("[{{markdown .Missing}}]")
+
+		err := doc.Render(map[string]any{})
+		require.NoError(t, err)
+		assert.Equal(t, "[]", doc.GetText())
+	})
+}
+
+func TestHTML(t *testing.T) {
+	t.Run("Should render inline HTML within the paragraph", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Note: {{htmlContent .Note}}")
+
+		err := doc.Render(map[string]any{"Note": "<p>Read <b>carefully</b>,   <em>then</em> <a href=\"https://example.com\">sign</a> &amp; return</p>"})
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasPrefix(doc.GetText(), "Note: Read carefully, then "))
+		assert.True(t, strings.HasSuffix(doc.GetText(), " & return"))
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b></w:rPr><w:t xml:space="preserve">carefully</w:t>`)
+		assert.Contains(t, xml, `<w:rPr><w:i></w:i></w:rPr><w:t xml:space="preserve">then</w:t>`)
+		assert.Regexp(t, `<w:hyperlink r:id="rIdLink\d+">.*?<w:t xml:space="preserve">sign</w:t></w:r></w:hyperlink>`, xml)
+	})
+
+	t.Run("Should render headings, lists and tables as paragraphs", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{htmlContent .Note}}")
+
+		note := `<h1>Summary</h1>
+<p>Line one<br>Line two</p>
+<ol>
+  <li>First</li>
+  <li>Second
+    <ul><li>Nested</li></ul>
+  </li>
+</ol>
+<table>
+  <tr><th>Name</th><th>Qty</th></tr>
+  <tr><td>Pen</td><td>2</td></tr>
+</table>`
+		err := doc.Render(map[string]any{"Note": note})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"Summary",
+			"Line one",
+			"Line two",
+			"1.\tFirst",
+			"2.\tSecond",
+			"○\tNested",
+			"|  :----: | :----: |",
+			"| Name | Qty |",
+			"| Pen | 2 |",
+		}, nonEmptyLines(doc.GetText()))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:pStyle w:val="Heading1"></w:pStyle>`)
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b></w:rPr><w:t xml:space="preserve">Qty</w:t>`)
+	})
+
+	t.Run("Should render each paragraph as its own paragraph", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{htmlContent .Note}}")
+
+		err := doc.Render(map[string]any{"Note": "<p>One</p><p>Two</p>"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"One", "Two"}, nonEmptyLines(doc.GetText()))
+	})
+
+	t.Run("Should keep the html escaping function of text/template", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Note | html}}")
+
+		err := doc.Render(map[string]any{"Note": "<b>Bold</b>"})
+		require.NoError(t, err)
+		assert.Contains(t, doc.GetText(), "Bold")
+		assert.NotContains(t, savedPart(t, doc, "word/document.xml"), "<w:b>")
+	})
+}
+
+func TestParagraphs(t *testing.T) {
+	t.Run("Should split text at blank lines into paragraphs with the placeholder's formatting", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Note: {{paragraphs .Note}} (end)").Style("Quote")
+		doc.AddParagraph("After")
+
+		err := doc.Render(map[string]any{"Note": "First line\r\nsame paragraph\r\n\r\n  \r\nSecond\tparagraph & more\n\nThird"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Note: First line\nsame paragraph\nSecond\tparagraph & more\nThird (end)\nAfter", doc.GetText())
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Equal(t, 3, strings.Count(xml, `<w:pStyle w:val="Quote">`))
+		assert.Contains(t, xml, `<w:t xml:space="preserve">Second</w:t><w:tab></w:tab><w:t>paragraph &amp; more</w:t>`)
+		assert.NotContains(t, xml, "docxtpl")
+		assert.NotContains(t, xml, "\r")
+	})
+
+	t.Run("Should keep soft line breaks and map tabs for plain values", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Note}}")
+
+		err := doc.Render(map[string]any{"Note": "a\r\nb\n\nc\td"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "a\nb\n\nc\td", doc.GetText())
+		assert.Equal(t, 1, strings.Count(savedPart(t, doc, "word/document.xml"), "<w:p>"))
+	})
+}
@@ -0,0 +1,97 @@
+package docxtpl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// headerTemplate returns a document whose header holds a paragraph with the text
+func headerTemplate(t *testing.T, doc *docxtpl.DocxTmpl, text string) *docxtpl.DocxTmpl {
+	t.Helper()
+
+	return withParts(t, doc, map[string]func(string) string{
+		"word/document.xml": func(document string) string {
+			return strings.Replace(document, "</w:body>", `<w:sectPr><w:headerReference w:type="default" r:id="rId90"/></w:sectPr></w:body>`, 1)
+		},
+		"word/_rels/document.xml.rels": func(rels string) string {
+			return strings.Replace(rels, "</Relationships>",
+				`<Relationship Id="rId90" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/></Relationships>`, 1)
+		},
+		"word/header1.xml": func(string) string {
+			return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+				`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:hdr>`
+		},
+		"[Content_Types].xml": func(types string) string {
+			return strings.Replace(types, "</Types>",
+				`<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/></Types>`, 1)
+		},
+	})
+}
+
+func TestPartials(t *testing.T) {
+	data := map[string]any{"Company": "Acme & Co", "City": "Springfield", "Name": "Tom", "Title": "CEO"}
+
+	t.Run("Should render registered partials in the body and headers", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`Sent by {{template "address" .}}`)
+		doc = headerTemplate(t, doc, `{{template "address" .}}`)
+		require.NoError(t, doc.RegisterPartial("address", "{{.Company}}\n{{.City}}"))
+
+		require.NoError(t, doc.Render(data))
+		assert.Equal(t, "Sent by Acme & Co\nSpringfield", doc.GetText())
+		assert.Contains(t, savedPart(t, doc, "word/header1.xml"), "Acme &amp; Co</w:t><w:br/><w:t>Springfield")
+	})
+
+	t.Run("Should use the document's delimiters in partials", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.SetDelimiters("[[", "]]")
+		doc.AddParagraph(`[[template "label" .]]`)
+		require.NoError(t, doc.RegisterPartial("label", "{{ [[.Name]] }}"))
+
+		require.NoError(t, doc.Render(data))
+		assert.Equal(t, "{{ Tom }}", doc.GetText())
+	})
+
+	t.Run("Should reject partials with syntax errors", func(t *testing.T) {
+		doc := docxtpl.New()
+		assert.Error(t, doc.RegisterPartial("broken", "{{if .Name}}"))
+		assert.Error(t, doc.RegisterPartial("", "text"))
+	})
+
+	t.Run("Should load formatted definitions from another document", func(t *testing.T) {
+		library := docxtpl.New()
+		library.AddParagraph(`{{define "signature"}}`)
+		library.AddParagraph("Kind regards,")
+		library.AddParagraph("{{.Name}}").Bold()
+		library.AddParagraph(`{{if .Title}}`)
+		library.AddParagraph("{{.Title}}").Italic()
+		library.AddParagraph(`{{end}}`)
+		library.AddParagraph(`{{end}}`)
+		greeting := library.AddParagraph(`{{define "greeting"}}Dear `)
+		greeting.AddText("{{.Name}}").Bold()
+		greeting.AddText("{{end}}")
+
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{template "greeting" .}},`)
+		doc.AddParagraph("Thank you for your order.")
+		doc.AddParagraph(`{{template "signature" .}}`)
+		require.NoError(t, doc.LoadPartials(library))
+
+		require.NoError(t, doc.Render(data))
+		assert.Equal(t, "Dear Tom,\nThank you for your order.\nKind regards,\nTom\nCEO", doc.GetText())
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b></w:rPr><w:t>Tom</w:t>`)
+		assert.Contains(t, xml, `<w:rPr><w:i></w:i></w:rPr><w:t>CEO</w:t>`)
+	})
+
+	t.Run("Should reject documents without definitions", func(t *testing.T) {
+		library := docxtpl.New()
+		library.AddParagraph("{{.Name}}")
+		assert.Error(t, docxtpl.New().LoadPartials(library))
+	})
+}
@@ -0,0 +1,89 @@
+package docxtpl_test
+
+import (
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderWithOptions(t *testing.T) {
+	newDoc := func() *docxtpl.DocxTmpl {
+		doc := docxtpl.New()
+		doc.AddParagraph("Dear {{.Name}},")
+		doc.AddParagraph("Your total is {{.Totl}}.")
+		return doc
+	}
+	data := map[string]any{"Name": "Tom", "Total": "42"}
+
+	t.Run("Should render missing values as empty text by default", func(t *testing.T) {
+		doc := newDoc()
+		err := doc.RenderWithOptions(data, docxtpl.RenderOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "Dear Tom,\nYour total is .", doc.GetText())
+	})
+
+	t.Run("Should fail listing unresolved placeholders in error mode", func(t *testing.T) {
+		doc := newDoc()
+		err := doc.RenderWithOptions(data, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		require.Error(t, err)
+
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		assert.Equal(t, docxtpl.ErrCodeUndefinedField, te.Code)
+		assert.Equal(t, []docxtpl.PlaceholderLocation{
+			{Placeholder: "{{.Totl}}", Part: "body", Paragraph: 1, Snippet: "Your total is {{.Totl}}."},
+		}, te.Unresolved)
+
+		// The document should be left untouched
+		assert.Equal(t, "Dear {{.Name}},\nYour total is {{.Totl}}.", doc.GetText())
+	})
+
+	t.Run("Should keep unresolved placeholders in keep mode", func(t *testing.T) {
+		doc := newDoc()
+		err := doc.RenderWithOptions(data, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyKeep})
+		require.NoError(t, err)
+		assert.Equal(t, "Dear Tom,\nYour total is {{.Totl}}.", doc.GetText())
+	})
+}
+
+func TestRenderErrorLocations(t *testing.T) {
+	t.Run("Should report unknown functions with their location", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Invoice {{.Number}}")
+		doc.AddParagraph("Due on {{formatDate .Due}}")
+
+		err := doc.Render(map[string]any{"Number": "1"})
+		require.Error(t, err)
+
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		assert.Equal(t, docxtpl.ErrCodeInvalidFunc, te.Code)
+		assert.Equal(t, "body, paragraph 1", te.Location)
+		assert.Equal(t, "{{formatDate .Due}}", te.Placeholder)
+		assert.Equal(t, "Due on {{formatDate .Due}}", te.Snippet)
+	})
+
+	t.Run("Should suggest similar fields for unresolved placeholders", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Total: {{.Totl}}")
+
+		err := doc.RenderWithOptions(map[string]any{"Total": "42"}, docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		assert.Contains(t, te.Suggestions, "Did you mean {{.Total}} instead of {{.Totl}}?")
+		assert.Equal(t, "Total: {{.Totl}}", te.Unresolved[0].Snippet)
+	})
+
+	t.Run("Should suggest similar fields for nil chains", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("City: {{.Adress.City}}")
+
+		err := doc.Render(map[string]any{"Address": map[string]any{"City": "London"}})
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok)
+		assert.Equal(t, docxtpl.ErrCodeUndefinedField, te.Code)
+		assert.Equal(t, "body, paragraph 0", te.Location)
+	})
+}
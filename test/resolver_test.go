@@ -0,0 +1,158 @@
+package docxtpl_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingResolver resolves fields from the values, counting the calls for
+// each path
+type countingResolver struct {
+	mu     sync.Mutex
+	values map[string]any
+	calls  map[string]int
+	err    map[string]error
+}
+
+func (r *countingResolver) Resolve(path string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.calls == nil {
+		r.calls = make(map[string]int)
+	}
+	r.calls[path]++
+	return r.values[path], r.err[path]
+}
+
+// customer is a resolved value with a method, kept as is by TemplateValue
+type customer struct {
+	First, Last string
+}
+
+func (c customer) FullName() string {
+	return c.First + " " + c.Last
+}
+
+func (c customer) TemplateValue() any {
+	return c
+}
+
+func TestRenderResolver(t *testing.T) {
+	t.Run("Should only resolve the fields the document uses", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Dear {{.Customer.Name}} of {{.Customer.City}},")
+		doc.AddParagraph("{{range .Orders}}{{.Total}} {{$.Currency}} {{end}}")
+		doc.AddParagraph("{{if .Paid}}Thanks, {{.Customer.Name}}{{end}}")
+
+		resolver := &countingResolver{values: map[string]any{
+			"Customer.Name": "Ada",
+			"Customer.City": "London",
+			"Orders":        []map[string]any{{"Total": 12}, {"Total": 30}},
+			"Currency":      "EUR",
+			"Paid":          true,
+			"Secret":        "unused",
+		}}
+		require.NoError(t, doc.Render(resolver))
+		text := doc.GetText()
+		assert.Contains(t, text, "Dear Ada of London,")
+		assert.Contains(t, text, "12 EUR 30 EUR")
+		assert.Contains(t, text, "Thanks, Ada")
+		assert.Equal(t, map[string]int{"Customer": 1, "Customer.Name": 1, "Customer.City": 1, "Orders": 1, "Currency": 1, "Paid": 1}, resolver.calls)
+	})
+
+	t.Run("Should call the methods of resolved values", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{.Customer.FullName}} since {{.Invoice.Date.Format "2006"}}`)
+		resolver := &countingResolver{values: map[string]any{
+			"Customer":     customer{First: "Ada", Last: "Lovelace"},
+			"Invoice.Date": time.Date(1843, time.July, 1, 0, 0, 0, 0, time.UTC),
+		}}
+		require.NoError(t, doc.Render(resolver))
+		assert.Equal(t, "Ada Lovelace since 1843", doc.GetText())
+		assert.Equal(t, map[string]int{"Customer": 1, "Invoice": 1, "Invoice.Date": 1}, resolver.calls)
+	})
+
+	t.Run("Should convert and escape resolved values like data", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph(`{{.Customer.name}} <{{.Customer.Email}}>`)
+		type account struct {
+			Name  string `docx:"name"`
+			Email string
+		}
+		resolver := &countingResolver{values: map[string]any{
+			"Customer": account{Name: "A & B", Email: "<ab@example.com>"},
+		}}
+		require.NoError(t, doc.Render(resolver))
+		assert.Equal(t, "A & B <<ab@example.com>>", doc.GetText())
+	})
+
+	t.Run("Should resolve a field once when fields within it are used too", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Customer.Name}}")
+		doc.AddParagraph("{{with .Customer}}{{.City}}{{end}}")
+		compiled, err := doc.Compile()
+		require.NoError(t, err)
+
+		var paths []string
+		rendered, err := compiled.Render(docxtpl.ResolverFunc(func(path string) (any, error) {
+			paths = append(paths, path)
+			return map[string]any{"Name": "Ada", "City": "London"}, nil
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Customer"}, paths)
+		assert.Contains(t, rendered.GetText(), "London")
+	})
+
+	t.Run("Should resolve the placeholders of pictures", func(t *testing.T) {
+		doc := pictureTemplate(t, "{{.Logo}}", pictureXml(false, "Picture 1", "{{.Logo}}", "rId91"))
+		data, err := os.ReadFile("testdata/templates/test_image.png")
+		require.NoError(t, err)
+		logo, err := docxtpl.CreateInlineImageFromBytes(data, ".png")
+		require.NoError(t, err)
+
+		resolver := &countingResolver{values: map[string]any{"Logo": logo}}
+		require.NoError(t, doc.Render(resolver))
+		assert.Equal(t, map[string]int{"Logo": 1}, resolver.calls)
+		target, _, _ := pictureImage(t, savedPart(t, doc, "word/header1.xml"), savedPart(t, doc, "word/_rels/header1.xml.rels"))
+		assert.Equal(t, string(data), savedPart(t, doc, "word/"+target))
+	})
+
+	t.Run("Should report resolver errors at the tag using the field", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Dear {{.Name}},")
+		doc.AddParagraph("Orders: {{range .Orders}}{{.Total}}{{end}}")
+		errDatabase := errors.New("connection refused")
+		resolver := &countingResolver{
+			values: map[string]any{"Name": "Ada"},
+			err:    map[string]error{"Orders": errDatabase},
+		}
+
+		err := doc.Render(resolver)
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok, "got %v", err)
+		assert.Equal(t, docxtpl.ErrCodeResolveError, te.Code)
+		assert.Equal(t, "failed to resolve Orders: connection refused", te.Message)
+		assert.Equal(t, "body, paragraph 1", te.Location)
+		assert.Equal(t, "{{range .Orders}}", te.Placeholder)
+		assert.ErrorIs(t, err, errDatabase)
+		assert.Contains(t, doc.GetText(), "Dear {{.Name}},")
+	})
+
+	t.Run("Should treat nil values as missing fields", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Dear {{.Name}},")
+		err := doc.RenderWithOptions(docxtpl.ResolverFunc(func(string) (any, error) { return nil, nil }),
+			docxtpl.RenderOptions{MissingKey: docxtpl.MissingKeyError})
+		te, ok := docxtpl.IsTemplateError(err)
+		require.True(t, ok, "got %v", err)
+		require.Len(t, te.Unresolved, 1)
+		assert.Equal(t, "{{.Name}}", te.Unresolved[0].Placeholder)
+	})
+}
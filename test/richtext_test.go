@@ -0,0 +1,63 @@
+package docxtpl_test
+
+import (
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRichText(t *testing.T) {
+	t.Run("Should render formatted runs inheriting the placeholder formatting", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("").AddText("Balance: {{.Amount}} due").Italic()
+
+		amount := docxtpl.NewRichText().
+			AddText("overdue ").
+			AddText("$120.00 <net>").Bold().Color("FF0000")
+		err := doc.Render(map[string]any{"Amount": amount})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Balance: overdue $120.00 <net> due", doc.GetText())
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b><w:i></w:i><w:color w:val="FF0000"></w:color></w:rPr><w:t xml:space="preserve">$120.00 &lt;net&gt;</w:t>`)
+		assert.Contains(t, xml, `<w:rPr><w:i></w:i></w:rPr><w:t xml:space="preserve"> due</w:t>`)
+	})
+
+	t.Run("Should render links and breaks", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Contact}}")
+
+		contact := docxtpl.NewRichText().
+			AddText("Call us").
+			Break().
+			AddText("or visit our website").Link("https://example.com")
+		err := doc.Render(map[string]any{"Contact": contact})
+		require.NoError(t, err)
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:br></w:br>`)
+		assert.Regexp(t, `<w:hyperlink r:id="rIdLink\d+"><w:r><w:rPr>.*<w:rStyle w:val="Hyperlink"></w:rStyle>.*</w:rPr><w:t xml:space="preserve">or visit our website</w:t></w:r></w:hyperlink>`, xml)
+	})
+
+	t.Run("Should render rich text in nested data and structs", func(t *testing.T) {
+		type line struct {
+			Note *docxtpl.RichText
+		}
+		doc := docxtpl.New()
+		doc.AddParagraph("{{range .Lines}}{{.Note}};{{end}}")
+
+		err := doc.Render(struct{ Lines []line }{Lines: []line{
+			{Note: docxtpl.NewRichText().AddText("a").Italic()},
+			{Note: docxtpl.NewRichText().AddText("b").Underline()},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "a;b;", doc.GetText())
+	})
+
+	t.Run("Should return the plain text", func(t *testing.T) {
+		rt := docxtpl.NewRichText().AddText("Hello").Bold().Break().AddText("World")
+		assert.Equal(t, "Hello\nWorld", rt.Text())
+	})
+}
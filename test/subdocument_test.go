@@ -0,0 +1,186 @@
+package docxtpl_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withParts saves the document with parts added or replaced
+func withParts(t *testing.T, doc *docxtpl.DocxTmpl, parts map[string]func(string) string) *docxtpl.DocxTmpl {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.Save(&buf))
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+	written := make(map[string]bool)
+	for _, f := range reader.File {
+		file, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		file.Close()
+
+		if update, ok := parts[f.Name]; ok {
+			content = []byte(update(string(content)))
+			written[f.Name] = true
+		}
+		w, err := writer.Create(f.Name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	for name, update := range parts {
+		if !written[name] {
+			w, err := writer.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(update("")))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, writer.Close())
+
+	result, err := docxtpl.ParseFromBytes(out.Bytes())
+	require.NoError(t, err)
+	return result
+}
+
+func TestSubDocuments(t *testing.T) {
+	t.Run("Should splice the body of a document at the placeholder", func(t *testing.T) {
+		appendix := docxtpl.New()
+		appendix.AddHeading("Appendix", 1)
+		appendix.AddParagraph("Signed by {{.Name}}")
+		appendix.AddTableFromSlice([][]string{{"Item", "Qty"}, {"Pen", "2"}})
+		require.NoError(t, appendix.Render(map[string]any{"Name": "Ana"}))
+
+		doc := docxtpl.New()
+		doc.AddParagraph("Before")
+		doc.AddParagraph("{{.Appendix}}")
+		doc.AddParagraph("After")
+
+		err := doc.Render(map[string]any{"Appendix": appendix})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"Before",
+			"Appendix",
+			"Signed by Ana",
+			"|  :----: | :----: |",
+			"| Item | Qty |",
+			"| Pen | 2 |",
+			"After",
+		}, nonEmptyLines(doc.GetText()))
+		assert.NotContains(t, savedPart(t, doc, "word/document.xml"), "docxtpl")
+	})
+
+	t.Run("Should work with documents in struct data", func(t *testing.T) {
+		appendix := docxtpl.New()
+		appendix.AddParagraph("Terms apply")
+
+		doc := docxtpl.New()
+		doc.AddParagraph("Intro {{.Appendix}} outro")
+
+		err := doc.Render(struct{ Appendix *docxtpl.DocxTmpl }{appendix})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Intro", "Terms apply", "outro"}, nonEmptyLines(doc.GetText()))
+	})
+
+	t.Run("Should copy media and hyperlinks with new relationship ids", func(t *testing.T) {
+		image, err := os.ReadFile("testdata/templates/test_image.png")
+		require.NoError(t, err)
+
+		appendix := docxtpl.New()
+		para := appendix.AddParagraph("See ")
+		para.AddLink("the site", "https://example.com/site")
+		_, err = para.AddInlineImage(image)
+		require.NoError(t, err)
+		appendix.AddParagraph(`{{link "https://example.com/rendered" "rendered"}}`)
+		require.NoError(t, appendix.Render(map[string]any{}))
+
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Appendix}}")
+		require.NoError(t, doc.Render(map[string]any{"Appendix": appendix}))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		rels := savedPart(t, doc, "word/_rels/document.xml.rels")
+		for _, url := range []string{"https://example.com/site", "https://example.com/rendered"} {
+			match := regexp.MustCompile(`Id="(\w+)"[^>]*Target="` + regexp.QuoteMeta(url) + `"`).FindStringSubmatch(rels)
+			if assert.NotNil(t, match, url) {
+				assert.Contains(t, xml, `<w:hyperlink r:id="`+match[1]+`">`)
+			}
+		}
+
+		embed := regexp.MustCompile(`r:embed="(\w+)"`).FindStringSubmatch(xml)
+		require.NotNil(t, embed)
+		assert.Regexp(t, `Id="`+embed[1]+`"[^>]*Target="media/[^"]+\.png"`, rels)
+		assert.Contains(t, savedPart(t, doc, "[Content_Types].xml"), `Extension="png"`)
+	})
+
+	t.Run("Should merge the styles and numbering used by the document", func(t *testing.T) {
+		base := docxtpl.New()
+		base.AddParagraph("Listed").Style("Callout").GetRaw().NumPr("1", "0")
+		appendix := withParts(t, base, map[string]func(string) string{
+			"word/styles.xml": func(styles string) string {
+				return strings.Replace(styles, "</w:styles>",
+					`<w:style w:type="paragraph" w:styleId="Callout"><w:name w:val="Callout"/><w:basedOn w:val="CalloutBase"/></w:style>`+
+						`<w:style w:type="paragraph" w:styleId="CalloutBase"><w:name w:val="Callout Base"/></w:style>`+
+						`<w:style w:type="paragraph" w:styleId="Unused"><w:name w:val="Unused"/></w:style></w:styles>`, 1)
+			},
+			"word/numbering.xml": func(string) string {
+				return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+					`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+					`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl></w:abstractNum>` +
+					`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num></w:numbering>`
+			},
+		})
+
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.First}}")
+		doc.AddParagraph("{{.Second}}")
+		require.NoError(t, doc.Render(map[string]any{"First": appendix, "Second": appendix}))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:numId w:val="1">`)
+		assert.Contains(t, xml, `<w:numId w:val="2">`)
+
+		numbering := savedPart(t, doc, "word/numbering.xml")
+		assert.Contains(t, numbering, `<w:abstractNum w:abstractNumId="1">`)
+		assert.Contains(t, numbering, `<w:abstractNum w:abstractNumId="2">`)
+		assert.Contains(t, numbering, `<w:num w:numId="2"><w:abstractNumId w:val="2"/></w:num>`)
+
+		styles := savedPart(t, doc, "word/styles.xml")
+		assert.Equal(t, 1, strings.Count(styles, `w:styleId="Callout"`))
+		assert.Contains(t, styles, `w:styleId="CalloutBase"`)
+		assert.NotContains(t, styles, `w:styleId="Unused"`)
+
+		assert.Contains(t, savedPart(t, doc, "word/_rels/document.xml.rels"), `Target="numbering.xml"`)
+		assert.Contains(t, savedPart(t, doc, "[Content_Types].xml"), `PartName="/word/numbering.xml"`)
+
+		// The saved document opens with its definitions intact
+		var buf bytes.Buffer
+		require.NoError(t, doc.Save(&buf))
+		reopened, err := docxtpl.ParseFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Listed", "Listed"}, nonEmptyLines(reopened.GetText()))
+	})
+
+	t.Run("Should not embed a document in itself", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.Self}}")
+
+		err := doc.Render(map[string]any{"Self": doc})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,87 @@
+package docxtpl_test
+
+import (
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableBlocks(t *testing.T) {
+	t.Run("Should remove rows in false if blocks", func(t *testing.T) {
+		doc := docxtpl.New()
+		table := doc.AddTable(4, 2)
+		table.SetCell(0, 0, "Subtotal")
+		table.SetCell(0, 1, "{{.Subtotal}}")
+		table.SetCell(1, 0, "{{if .ShowDiscount}}")
+		table.SetCell(1, 1, "")
+		table.SetCell(2, 0, "Discount")
+		table.SetCell(2, 1, "{{.Discount}}")
+		table.SetCell(3, 0, "")
+		table.SetCell(3, 1, "{{end}}")
+
+		err := doc.Render(map[string]any{"Subtotal": "100", "Discount": "10", "ShowDiscount": false})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"| :----: | :----: |", "| Subtotal | 100 |"}, nonEmptyLines(collapseSpaces(doc.GetText())))
+	})
+
+	t.Run("Should keep inline if blocks in rows of a range", func(t *testing.T) {
+		doc := docxtpl.New()
+		table := doc.AddTable(3, 2)
+		table.SetCell(0, 0, "{{range .Items}}")
+		table.SetCell(0, 1, "")
+		table.SetCell(1, 0, "{{.Name}}")
+		table.SetCell(1, 1, "{{if .Discount}}-{{.Discount}}{{end}}")
+		table.SetCell(2, 0, "{{end}}")
+		table.SetCell(2, 1, "")
+
+		err := doc.Render(map[string]any{"Items": []map[string]any{
+			{"Name": "A", "Discount": "5"},
+			{"Name": "B"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"| :----: | :----: |", "| A | -5 |", "| B | |"}, nonEmptyLines(collapseSpaces(doc.GetText())))
+	})
+
+	t.Run("Should remove the whole table when its if block is false", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Before")
+		table := doc.AddTable(3, 1)
+		table.SetCell(0, 0, "{{if .ShowTable}}")
+		table.SetCell(1, 0, "Content")
+		table.SetCell(2, 0, "{{end}}")
+		doc.AddParagraph("After")
+
+		err := doc.Render(map[string]any{"ShowTable": false})
+		require.NoError(t, err)
+		assert.Equal(t, 0, doc.CountTables())
+		assert.Equal(t, "Before\nAfter", doc.GetText())
+	})
+
+	t.Run("Should remove a single cell table when its if block is false", func(t *testing.T) {
+		doc := docxtpl.New()
+		table := doc.AddTable(1, 1)
+		cell := table.Cell(0, 0)
+		cell.SetText("{{if .ShowNote}}")
+		cell.AddParagraph("Note")
+		cell.AddParagraph("{{end}}")
+
+		err := doc.Render(map[string]any{"ShowNote": false})
+		require.NoError(t, err)
+		assert.Equal(t, 0, doc.CountTables())
+
+		doc = docxtpl.New()
+		table = doc.AddTable(1, 1)
+		cell = table.Cell(0, 0)
+		cell.SetText("{{if .ShowNote}}")
+		cell.AddParagraph("Note")
+		cell.AddParagraph("{{end}}")
+
+		err = doc.Render(map[string]any{"ShowNote": true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, doc.CountTables())
+		assert.Contains(t, doc.GetText(), "Note")
+		assert.NotContains(t, doc.GetText(), "{{")
+	})
+}
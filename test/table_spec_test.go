@@ -0,0 +1,88 @@
+package docxtpl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abdokhaire/go-docxgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableSpec(t *testing.T) {
+	t.Run("Should replace the placeholder paragraph with a table", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Invoice")
+		doc.AddParagraph("{{.LineItems}}")
+		doc.AddParagraph("Thank you")
+
+		err := doc.Render(map[string]any{
+			"LineItems": docxtpl.TableSpec{
+				Headers: []string{"Item", "Qty", "Price"},
+				Rows: [][]any{
+					{"Pen & ink", 2, 1.5},
+					{docxtpl.NewRichText().AddText("Paper").Bold(), nil},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"Invoice",
+			"|  :----: | :----: | :----: |",
+			"| Item | Qty | Price |",
+			"| Pen & ink | 2 | 1.5 |",
+			"| Paper |        |        |",
+			"Thank you",
+		}, nonEmptyLines(doc.GetText()))
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:rPr><w:b></w:b></w:rPr><w:t xml:space="preserve">Item</w:t>`)
+		assert.Contains(t, xml, `<w:t xml:space="preserve">Pen &amp; ink</w:t>`)
+		assert.NotContains(t, xml, "tblHeader")
+		assert.NotContains(t, xml, "docxtpl")
+	})
+
+	t.Run("Should apply column widths, style and header repeat", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("{{.LineItems}}")
+
+		err := doc.Render(struct{ LineItems *docxtpl.TableSpec }{&docxtpl.TableSpec{
+			Headers:      []string{"Item", "Qty", "Price"},
+			Rows:         [][]any{{"Pen", 2, "1.50"}},
+			ColumnWidths: []int{5760},
+			Style:        "TableGrid",
+			RepeatHeader: true,
+		}})
+		require.NoError(t, err)
+
+		xml := savedPart(t, doc, "word/document.xml")
+		assert.Contains(t, xml, `<w:tblStyle w:val="TableGrid"></w:tblStyle>`)
+		assert.NotContains(t, xml, "<w:tblBorders>")
+		assert.Contains(t, xml, `<w:gridCol w:w="5760"></w:gridCol><w:gridCol w:w="1800"></w:gridCol><w:gridCol w:w="1800"></w:gridCol>`)
+		assert.Equal(t, 1, strings.Count(xml, "<w:tblHeader></w:tblHeader>"))
+
+		// The header repeat survives parsing the saved document
+		reopened, err := doc.Clone()
+		require.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(savedPart(t, reopened, "word/document.xml"), "<w:tblHeader></w:tblHeader>"))
+	})
+
+	t.Run("Should keep text around an inline placeholder", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("Items: {{.LineItems}} Total: 3")
+
+		err := doc.Render(map[string]any{"LineItems": &docxtpl.TableSpec{Rows: [][]any{{"Pen"}, {"Paper"}}}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Items:", "|  :----: |", "| Pen |", "| Paper |", "Total: 3"}, nonEmptyLines(doc.GetText()))
+	})
+
+	t.Run("Should render nothing for an empty spec", func(t *testing.T) {
+		doc := docxtpl.New()
+		doc.AddParagraph("[{{.LineItems}}]")
+
+		err := doc.Render(map[string]any{"LineItems": docxtpl.TableSpec{}})
+		require.NoError(t, err)
+		assert.Equal(t, "[]", doc.GetText())
+	})
+}
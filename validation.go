@@ -1,13 +1,16 @@
 package docxtpl
 
 import (
+	"errors"
 	"fmt"
-	"reflect"
+	"sort"
 	"regexp"
 	"strings"
 	"text/template"
 
+	"github.com/abdokhaire/go-docxgen/internal/functions"
 	"github.com/abdokhaire/go-docxgen/internal/tags"
+	"github.com/abdokhaire/go-docxgen/internal/templatedata"
 	"github.com/abdokhaire/go-docxgen/internal/xmlutils"
 )
 
@@ -37,6 +40,8 @@ const (
 	ErrCodeDataConversion ErrorCode = "DATA_CONVERSION"
 	ErrCodeImageError     ErrorCode = "IMAGE_ERROR"
 	ErrCodeMarshalError   ErrorCode = "MARSHAL_ERROR"
+	ErrCodeLimitExceeded  ErrorCode = "LIMIT_EXCEEDED"
+	ErrCodeResolveError   ErrorCode = "RESOLVE_ERROR"
 
 	// Save errors
 	ErrCodeWriteError ErrorCode = "WRITE_ERROR"
@@ -64,11 +69,33 @@ type TemplateError struct {
 	// LineNumber is the approximate line in the template, if available.
 	LineNumber int
 
+	// Snippet is the visible text surrounding the error in the document, if available.
+	Snippet string
+
 	// Cause is the underlying error that caused this error.
 	Cause error
 
 	// Suggestions provides possible fixes for the error.
 	Suggestions []string
+
+	// Unresolved lists the placeholders that had no value when rendering in strict mode.
+	Unresolved []PlaceholderLocation
+}
+
+// PlaceholderLocation identifies a placeholder within a document part.
+type PlaceholderLocation struct {
+	Placeholder string // Placeholder text, e.g. "{{.Name}}"
+	Part        string // Document part, e.g. "body", "header2", "footnotes"
+	Paragraph   int    // Zero-based paragraph index within the part, -1 if not in a paragraph
+	Snippet     string // Visible text of the surrounding paragraph
+}
+
+// String returns a short description such as "{{.Name}} (body, paragraph 3)".
+func (p PlaceholderLocation) String() string {
+	if p.Paragraph < 0 {
+		return fmt.Sprintf("%s (%s)", p.Placeholder, p.Part)
+	}
+	return fmt.Sprintf("%s (%s, paragraph %d)", p.Placeholder, p.Part, p.Paragraph)
 }
 
 // Error implements the error interface.
@@ -90,6 +117,10 @@ func (e *TemplateError) Error() string {
 		parts = append(parts, fmt.Sprintf("(line %d)", e.LineNumber))
 	}
 
+	if e.Snippet != "" {
+		parts = append(parts, fmt.Sprintf("(near %q)", e.Snippet))
+	}
+
 	return strings.Join(parts, " ")
 }
 
@@ -119,10 +150,21 @@ func (e *TemplateError) String() string {
 		sb.WriteString(fmt.Sprintf("Line:     %d\n", e.LineNumber))
 	}
 
+	if e.Snippet != "" {
+		sb.WriteString(fmt.Sprintf("Near:     %s\n", e.Snippet))
+	}
+
 	if e.Cause != nil {
 		sb.WriteString(fmt.Sprintf("Cause:    %s\n", e.Cause.Error()))
 	}
 
+	if len(e.Unresolved) > 0 {
+		sb.WriteString("\nUnresolved:\n")
+		for _, p := range e.Unresolved {
+			sb.WriteString(fmt.Sprintf("  - %s\n", p))
+		}
+	}
+
 	if len(e.Suggestions) > 0 {
 		sb.WriteString("\nSuggestions:\n")
 		for i, s := range e.Suggestions {
@@ -290,19 +332,41 @@ func ErrUndefinedField(field string, location string) *TemplateError {
 	}
 }
 
-// ErrInvalidFunc creates an invalid function error.
-func ErrInvalidFunc(funcName string) *TemplateError {
+// ErrUnresolvedPlaceholders creates an error listing placeholders that had no value.
+func ErrUnresolvedPlaceholders(unresolved []PlaceholderLocation) *TemplateError {
+	descriptions := make([]string, len(unresolved))
+	for i, p := range unresolved {
+		descriptions[i] = p.String()
+	}
 	return &TemplateError{
-		Code:    ErrCodeInvalidFunc,
-		Message: fmt.Sprintf("function %q is not defined", funcName),
+		Code:       ErrCodeUndefinedField,
+		Message:    fmt.Sprintf("%d unresolved placeholder(s): %s", len(unresolved), strings.Join(descriptions, ", ")),
+		Unresolved: unresolved,
 		Suggestions: []string{
-			fmt.Sprintf("Register the function using doc.RegisterFunction(%q, fn)", funcName),
-			"Use doc.RegisterFuncMap(sprig.FuncMap()) to add Sprig functions",
-			"Check for typos in the function name",
+			"Add the missing fields to your data",
+			"Check for typos in the placeholder names",
+			"Render with MissingKeyZero to allow empty values",
 		},
 	}
 }
 
+// ErrInvalidFunc creates an invalid function error.
+func ErrInvalidFunc(funcName string) *TemplateError {
+	suggestions := []string{
+		fmt.Sprintf("Register the function using doc.RegisterFunction(%q, fn)", funcName),
+		"Use doc.RegisterFuncMap(sprig.FuncMap()) to add Sprig functions",
+		"Check for typos in the function name",
+	}
+	if _, ok := functions.DocxFuncMap()[funcName]; ok {
+		suggestions = append([]string{"Use doc.RegisterFuncMap(docxtpl.DocxFunctions()) to add the Word function library"}, suggestions...)
+	}
+	return &TemplateError{
+		Code:        ErrCodeInvalidFunc,
+		Message:     fmt.Sprintf("function %q is not defined", funcName),
+		Suggestions: suggestions,
+	}
+}
+
 // ErrImageLoad creates an image loading error.
 func ErrImageLoad(path string, cause error) *TemplateError {
 	return &TemplateError{
@@ -331,6 +395,133 @@ func ErrFileParse(filename string, cause error) *TemplateError {
 	}
 }
 
+// fieldNameRegex extracts the field from text/template messages about unknown fields
+var fieldNameRegex = regexp.MustCompile(`(?:can't evaluate field|no entry for key|nil pointer evaluating [^.]*\.)\s*"?(\w+)"?`)
+
+// undefinedFuncRegex extracts the function name from text/template parse errors
+var undefinedFuncRegex = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+// newRenderError converts an error from tag replacement into a TemplateError
+// pointing at the part, paragraph and tag that caused it.
+func newRenderError(err error, part string, data map[string]any) error {
+	var located *tags.LocatedError
+	if !errors.As(err, &located) {
+		return err
+	}
+
+	te := &TemplateError{
+		Code:        ErrCodeExecutionError,
+		Message:     located.Message,
+		Location:    part,
+		Placeholder: located.Tag,
+		Cause:       err,
+	}
+	if located.Location != nil {
+		if position := located.Location.String(); position != "" {
+			te.Location = part + ", " + position
+		}
+		te.Snippet = located.Location.Snippet
+	}
+
+	var limitErr *tags.LimitError
+	if errors.As(err, &limitErr) {
+		te.Code = ErrCodeLimitExceeded
+		return te
+	}
+
+	if located.Kind == tags.ParseError {
+		te.Code = ErrCodeSyntaxError
+		if match := undefinedFuncRegex.FindStringSubmatch(located.Message); match != nil {
+			te.Code = ErrCodeInvalidFunc
+			te.Suggestions = ErrInvalidFunc(match[1]).Suggestions
+		} else {
+			te.Suggestions = ErrSyntax(located.Message).Suggestions
+		}
+		return te
+	}
+
+	if match := fieldNameRegex.FindStringSubmatch(located.Message); match != nil {
+		te.Code = ErrCodeUndefinedField
+		field := extractFieldName(located.Tag)
+		if field == "" {
+			field = match[1]
+		}
+		te.Suggestions = suggestFields(field, data)
+	}
+	return te
+}
+
+// fieldSuggestions returns "did you mean" suggestions for unresolved placeholders.
+func fieldSuggestions(unresolved []PlaceholderLocation, data map[string]any) []string {
+	var suggestions []string
+	seen := make(map[string]bool)
+	for _, p := range unresolved {
+		for _, suggestion := range suggestFields(extractFieldName(p.Placeholder), data) {
+			if !seen[suggestion] {
+				seen[suggestion] = true
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+	return suggestions
+}
+
+// suggestFields suggests data fields with names similar to the last segment of a field path.
+func suggestFields(fieldPath string, data map[string]any) []string {
+	if fieldPath == "" {
+		return nil
+	}
+	segments := strings.Split(fieldPath, ".")
+	name := segments[len(segments)-1]
+
+	bestScore := 0.5 // Minimum similarity threshold
+	best := ""
+	for _, candidate := range collectFieldNames(data) {
+		if candidate == name {
+			continue
+		}
+		if score := similarity(name, candidate); score > bestScore {
+			bestScore = score
+			best = candidate
+		}
+	}
+	if best == "" {
+		return nil
+	}
+
+	segments[len(segments)-1] = best
+	return []string{fmt.Sprintf("Did you mean {{.%s}} instead of {{.%s}}?", strings.Join(segments, "."), fieldPath)}
+}
+
+// collectFieldNames returns the unique keys found anywhere in the data, including
+// inside nested maps and the items of slices used by range loops.
+func collectFieldNames(data map[string]any) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	var collect func(m map[string]any)
+	collect = func(m map[string]any) {
+		for key, value := range m {
+			if !seen[key] {
+				seen[key] = true
+				names = append(names, key)
+			}
+			switch v := value.(type) {
+			case map[string]any:
+				collect(v)
+			case []map[string]any:
+				for _, item := range v {
+					collect(item)
+				}
+			}
+		}
+	}
+	collect(data)
+
+	sort.Strings(names)
+	return names
+}
+
 // IsTemplateError checks if an error is a TemplateError and returns it.
 func IsTemplateError(err error) (*TemplateError, bool) {
 	if te, ok := err.(*TemplateError); ok {
@@ -389,11 +580,8 @@ func (r *ValidationResult) Error() string {
 func (d *DocxTmpl) Validate() *ValidationResult {
 	result := &ValidationResult{Valid: true}
 
-	// Merge tags in document body
-	tags.MergeTags(d.Document.Body.Items)
-
-	// Get document XML
-	documentXml, err := d.getDocumentXml()
+	// Get document XML with its tags merged
+	documentXml, err := d.templateDocumentXml()
 	if err != nil {
 		result.Valid = false
 		result.Errors = append(result.Errors, ValidationError{
@@ -410,11 +598,12 @@ func (d *DocxTmpl) Validate() *ValidationResult {
 	// Validate headers, footers, etc.
 	for _, pf := range d.processableFiles {
 		location := getLocationName(pf.Name)
-		mergedContent := xmlutils.MergeFragmentedTagsInXml(pf.Content)
+		mergedContent := xmlutils.MergeFragmentedTagsInXml(d.templateContent(pf.Name, pf.Content))
 		pfErrors := d.validateXmlContent(mergedContent, location)
 		result.Errors = append(result.Errors, pfErrors...)
 	}
 
+	result.Errors = d.restoreValidationDelimiters(result.Errors)
 	result.Valid = len(result.Errors) == 0
 	return result
 }
@@ -502,7 +691,7 @@ func (d *DocxTmpl) checkTemplateSyntax(content string, location string) []Valida
 		return errors
 	}
 
-	_, err = template.New("validate").Funcs(d.funcMap).Parse(preparedContent)
+	_, err = template.New("validate").Funcs(d.funcMap).Funcs(xmlutils.TemplateFuncs()).Parse(preparedContent)
 	if err != nil {
 		errMsg := err.Error()
 
@@ -586,10 +775,10 @@ func (d *DocxTmpl) ValidateData(data any) []ValidationError {
 	var errors []ValidationError
 
 	// Get all placeholders from template
-	placeholders, _ := d.GetPlaceholders()
+	placeholders, _ := d.placeholders()
 
 	// Convert data to map for checking
-	dataMap := toMap(data)
+	dataMap := toMap(data, templatedata.Options{UseJSONTags: d.useJSONTags})
 
 	// Check each placeholder
 	for _, ph := range placeholders {
@@ -609,7 +798,7 @@ func (d *DocxTmpl) ValidateData(data any) []ValidationError {
 			errors = append(errors, ValidationError{
 				Field:       fieldName,
 				Message:     "field not found in data",
-				Placeholder: ph,
+				Placeholder: d.delims.Restore(ph),
 			})
 		}
 	}
@@ -627,7 +816,7 @@ func (d *DocxTmpl) GetRequiredFields() []FieldInfo {
 	fieldMap := make(map[string]*FieldInfo)
 
 	// Get placeholders from document body
-	placeholders, _ := d.GetPlaceholders()
+	placeholders, _ := d.placeholders()
 
 	for _, ph := range placeholders {
 		fieldName := extractFieldName(ph)
@@ -760,7 +949,7 @@ func extractFieldName(placeholder string) string {
 func isControlFlow(field string) bool {
 	// Check if it's a control flow statement
 	lower := strings.ToLower(field)
-	controlKeywords := []string{"if", "else", "end", "range", "with", "define", "template", "block"}
+	controlKeywords := []string{"if", "else", "end", "range", "with", "define", "template", "block", "hrange", "hend"}
 	for _, kw := range controlKeywords {
 		if strings.HasPrefix(lower, kw) {
 			return true
@@ -794,7 +983,7 @@ func fieldExists(data map[string]interface{}, fieldPath string) bool {
 	return true
 }
 
-func toMap(data any) map[string]interface{} {
+func toMap(data any, opts templatedata.Options) map[string]interface{} {
 	if data == nil {
 		return nil
 	}
@@ -803,34 +992,13 @@ func toMap(data any) map[string]interface{} {
 	if m, ok := data.(map[string]interface{}); ok {
 		return m
 	}
-	if m, ok := data.(map[string]string); ok {
-		result := make(map[string]interface{})
-		for k, v := range m {
-			result[k] = v
-		}
-		return result
-	}
 
-	// Convert struct to map using reflection
-	v := reflect.ValueOf(data)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-
-	if v.Kind() != reflect.Struct {
+	// Convert structs and other maps the same way Render does, honoring struct tags
+	result, err := templatedata.DataToMapWithOptions(data, opts)
+	if err != nil {
 		return nil
 	}
 
-	result := make(map[string]interface{})
-	t := v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		field := t.Field(i)
-		if field.PkgPath != "" {
-			continue // Skip unexported fields
-		}
-		result[field.Name] = v.Field(i).Interface()
-	}
-
 	return result
 }
 
@@ -885,7 +1053,7 @@ func generateSampleValue(fieldName, fieldType string) interface{} {
 func (d *DocxTmpl) ValidatePlaceholderSyntax() []ValidationError {
 	var errors []ValidationError
 
-	text := d.GetText()
+	text := xmlutils.TranslateTextDelimiters(d.GetText(), d.delims)
 
 	// Find all {{ }} patterns
 	re := regexp.MustCompile(`\{\{[^}]*\}\}`)
@@ -925,5 +1093,5 @@ func (d *DocxTmpl) ValidatePlaceholderSyntax() []ValidationError {
 		})
 	}
 
-	return errors
+	return d.restoreValidationDelimiters(errors)
 }